@@ -1,21 +1,27 @@
 package config
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/mitchellh/cli"
 	"github.com/spf13/pflag"
 
 	"github.com/blairham/aws-sso-config/command/config/edit"
+	"github.com/blairham/aws-sso-config/command/config/export"
 	configflags "github.com/blairham/aws-sso-config/command/config/flags"
 	"github.com/blairham/aws-sso-config/command/config/get"
+	"github.com/blairham/aws-sso-config/command/config/importconfig"
 	"github.com/blairham/aws-sso-config/command/config/list"
 	"github.com/blairham/aws-sso-config/command/config/set"
+	"github.com/blairham/aws-sso-config/command/config/shared"
 	"github.com/blairham/aws-sso-config/command/config/unset"
+	"github.com/blairham/aws-sso-config/command/config/validate"
 )
 
 type cmd struct {
 	UI    cli.Ui
+	ctx   context.Context
 	flags *pflag.FlagSet
 
 	// Flag variables
@@ -23,13 +29,21 @@ type cmd struct {
 }
 
 func New(ui cli.Ui) *cmd {
-	c := &cmd{UI: ui}
+	return NewWithContext(context.Background(), ui)
+}
+
+// NewWithContext creates the command with a context for subcommands that make
+// network calls; ctx is cancelled when the process is interrupted
+func NewWithContext(ctx context.Context, ui cli.Ui) *cmd {
+	c := &cmd{UI: ui, ctx: ctx}
 	c.init()
 	return c
 }
 
 func (c *cmd) init() {
 	c.flags = pflag.NewFlagSet("config", pflag.ContinueOnError)
+	// Flags after the subcommand, such as list --show-origin, belong to it
+	c.flags.SetInterspersed(false)
 
 	// Get flag configurations from registry
 	registry := configflags.NewFlagRegistry()
@@ -79,6 +93,15 @@ func (c *cmd) Run(args []string) int {
 	case "edit":
 		editCmd := edit.New(c.UI)
 		return editCmd.Run(subArgs)
+	case "export":
+		exportCmd := export.New(c.UI)
+		return exportCmd.Run(subArgs)
+	case "import":
+		importCmd := importconfig.New(c.UI)
+		return importCmd.Run(subArgs)
+	case "validate":
+		validateCmd := validate.New(c.ctx, c.UI)
+		return validateCmd.Run(subArgs)
 	default:
 		c.UI.Error(fmt.Sprintf("Unknown subcommand: %s", subcommand))
 		c.UI.Error("")
@@ -97,6 +120,9 @@ func (c *cmd) showUsage() {
 	c.UI.Error("  unset <key>           Reset a configuration value to its default")
 	c.UI.Error("  list                  List all configuration variables and their values")
 	c.UI.Error("  edit [config-file]    Open configuration file in an editor")
+	c.UI.Error("  export                Print the configuration for config import")
+	c.UI.Error("  import <file>         Import a configuration written by config export")
+	c.UI.Error("  validate [file]       Check a configuration file")
 	c.UI.Error("")
 
 	// Show flags (note: hidden flags like --list won't appear here)
@@ -117,14 +143,11 @@ Subcommands:
   unset <key>          Reset a configuration value to its default
   list                 List all configuration variables and their values
   edit [config-file]   Open configuration file in an editor
+  export               Print the configuration for config import
+  import <file>        Import a configuration written by config export
+  validate [file]      Check a configuration file
 
-Available configuration keys:
-  sso.start_url        Your AWS SSO start URL
-  sso.region          AWS region for SSO (e.g., us-east-1)
-  sso.role            SSO role name (e.g., AdministratorAccess)
-  aws.default_region  Default AWS region for profiles
-  aws.config_file     Path to AWS config file
-
+` + shared.KeysHelp() + `
 Examples:
   # Get the SSO start URL
   aws-sso-config config get sso.start_url
@@ -152,6 +175,13 @@ Examples:
 
   # Edit a specific configuration file
   aws-sso-config config edit /path/to/config
+
+  # Share your settings and import them on another machine
+  aws-sso-config config export > team.toml
+  aws-sso-config config import team.toml
+
+  # Check the configuration file, e.g. in CI
+  aws-sso-config config validate
 `
 
 	// Add pflag usage information (excluding hidden flags)
@@ -265,6 +265,8 @@ func TestValidKey(t *testing.T) {
 		"sso.start_url",
 		"sso.region",
 		"sso.role",
+		"sso.token_store",
+		"sso.credential_refresh_minutes",
 		"aws.default_region",
 		"aws.config_file",
 	}
@@ -99,16 +99,21 @@ func (c *cmd) ensureConfigFileExists(configFile string) error {
 			return fmt.Errorf("failed to create config directory: %w", err)
 		}
 
-		// Create a default config file
 		cm := appconfig.NewConfigManager(configFile)
-		config, err := cm.Load() // This will create the file with defaults
+		config, err := cm.Load()
 		if err != nil {
-			return fmt.Errorf("failed to create default config file: %w", err)
+			return fmt.Errorf("failed to load configuration: %w", err)
 		}
 
-		// Validate the created config
-		if err := config.Validate(); err != nil {
-			return fmt.Errorf("default config validation failed: %w", err)
+		// Defaults in the user file would override the settings of a system or project
+		// file, start an empty one then
+		if fromOtherFile(config) {
+			content := "# Settings here override the system file, " + appconfig.SystemConfigFile + "\n"
+			if err := os.WriteFile(configFile, []byte(content), 0600); err != nil {
+				return fmt.Errorf("failed to create config file: %w", err)
+			}
+		} else if err := cm.Create(&appconfig.Config{}); err != nil {
+			return fmt.Errorf("failed to create default config file: %w", err)
 		}
 
 		c.UI.Output(fmt.Sprintf("Created default configuration file at: %s", configFile))
@@ -117,6 +122,16 @@ func (c *cmd) ensureConfigFileExists(configFile string) error {
 	return nil
 }
 
+// fromOtherFile reports whether a system or project file sets any of config's values
+func fromOtherFile(config *appconfig.Config) bool {
+	for _, origin := range config.Origins {
+		if origin.Layer == appconfig.LayerSystem || origin.Layer == appconfig.LayerProject {
+			return true
+		}
+	}
+	return false
+}
+
 // validateConfigFile validates the configuration file after editing
 func (c *cmd) validateConfigFile(configFile string) error {
 	cm := appconfig.NewConfigManager(configFile)
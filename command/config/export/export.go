@@ -0,0 +1,66 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/mitchellh/cli"
+	"github.com/spf13/pflag"
+
+	appconfig "github.com/blairham/aws-sso-config/providers/config"
+)
+
+type cmd struct {
+	UI    cli.Ui
+	flags *pflag.FlagSet
+
+	configFile string
+}
+
+func New(ui cli.Ui) *cmd {
+	c := &cmd{UI: ui}
+	c.flags = pflag.NewFlagSet("export", pflag.ContinueOnError)
+	c.flags.StringVarP(&c.configFile, "config", "c", "", "Path to configuration file")
+	return c
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+	if c.flags.NArg() != 0 {
+		c.UI.Error("Usage: aws-sso-config config export [--config <file>] > team.toml")
+		return 1
+	}
+
+	var out bytes.Buffer
+	if err := appconfig.NewConfigManager(c.configFile).Export(&out); err != nil {
+		c.UI.Error(fmt.Sprintf("Error exporting config: %v", err))
+		return 1
+	}
+	c.UI.Output(strings.TrimSuffix(out.String(), "\n"))
+	return 0
+}
+
+func (c *cmd) Help() string {
+	return `Usage: aws-sso-config config export [--config <file>] > team.toml
+
+  Print the configuration as TOML, for config import on another machine.
+
+  The settings of the system, user and project files are merged, including
+  contexts, [[repos]] mappings and [[generate.chains]]. Built-in defaults,
+  environment variables and current_context are left out, so the export
+  only holds what was set on purpose.
+
+Flags:
+` + c.flags.FlagUsages() + `
+Examples:
+  # Share your settings with the team
+  aws-sso-config config export > team.toml
+`
+}
+
+func (c *cmd) Synopsis() string {
+	return "Print the configuration for config import"
+}
@@ -0,0 +1,26 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExport(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(configFile, []byte("current_context = \"work\"\n\n[sso]\nrole = \"Dev\"\n"), 0600))
+
+	ui := cli.NewMockUi()
+	code := New(ui).Run([]string{"-c", configFile})
+	require.Equal(t, 0, code, ui.ErrorWriter.String())
+	assert.Contains(t, ui.OutputWriter.String(), "[sso]\nrole = 'Dev'\n")
+	assert.NotContains(t, ui.OutputWriter.String(), "current_context")
+
+	ui = cli.NewMockUi()
+	assert.Equal(t, 1, New(ui).Run([]string{"extra"}))
+	assert.Contains(t, ui.ErrorWriter.String(), "Usage: aws-sso-config config export")
+}
@@ -18,8 +18,14 @@ func New(ui cli.Ui) *cmd {
 }
 
 func (c *cmd) Run(args []string) int {
+	args, format, err := shared.ExtractOutput(args)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
 	if len(args) != 1 {
-		c.UI.Error("Usage: aws-sso-config config get <key>")
+		c.UI.Error("Usage: aws-sso-config config get <key> [--output <format>]")
 		c.UI.Error("")
 		shared.PrintAvailableKeys(c.UI)
 		return 1
@@ -42,28 +48,34 @@ func (c *cmd) Run(args []string) int {
 		return 1
 	}
 
-	// Get the value for the specified key
-	value, err := shared.GetConfigValue(config, key)
+	entries, err := shared.Entries(config, []string{key})
 	if err != nil {
 		c.UI.Error(err.Error())
 		return 1
 	}
+	output, err := shared.FormatEntry(format, entries[0])
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error formatting %s: %v", key, err))
+		return 1
+	}
 
-	c.UI.Output(value)
+	c.UI.Output(output)
 	return 0
 }
 
 func (c *cmd) Help() string {
-	return `Usage: aws-sso-config config get <key>
+	return `Usage: aws-sso-config config get <key> [--output <format>]
 
   Get a configuration value.
 
-Available configuration keys:
-  sso.start_url        Your AWS SSO start URL
-  sso.region          AWS region for SSO (e.g., us-east-1)
-  sso.role            SSO role name (e.g., AdministratorAccess)
-  aws.default_region  Default AWS region for profiles
-  aws.config_file     Path to AWS config file
+  With --output json, yaml or toml the key is printed as an object with its
+  value, default, source (the layer it came from), environment variable and
+  description. --output env prints a VARIABLE='value' line that can be
+  sourced by a shell, after a comment with the same details.
+
+` + shared.KeysHelp() + `
+Flags:
+  -o, --output <format>  Output format: text (default), json, yaml, toml or env
 
 Examples:
   # Get the SSO start URL
@@ -71,6 +83,9 @@ Examples:
 
   # Get the default region
   aws-sso-config config get aws.default_region
+
+  # Get the start URL and where it is set as JSON
+  aws-sso-config config get sso.start_url --output json
 `
 }
 
@@ -67,3 +67,16 @@ func TestGetHelp(t *testing.T) {
 	assert.Contains(t, help, "sso.start_url")
 	assert.Contains(t, help, "Examples:")
 }
+
+func TestGetOutputEnv(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("AWS_SSO_CONFIG_SSO_REGION", "eu-west-1")
+
+	ui := cli.NewMockUi()
+	c := New(ui)
+
+	exitCode := c.Run([]string{"sso.region", "-o", "env"})
+	assert.Equal(t, 0, exitCode, ui.ErrorWriter.String())
+	assert.Equal(t, "# sso.region: AWS region for SSO (e.g., us-east-1) (source \"env:AWS_SSO_CONFIG_SSO_REGION\", default \"us-east-1\")\n"+
+		"AWS_SSO_CONFIG_SSO_REGION='eu-west-1'\n", ui.OutputWriter.String())
+}
@@ -0,0 +1,172 @@
+package importconfig
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/mitchellh/cli"
+	"github.com/spf13/pflag"
+
+	appconfig "github.com/blairham/aws-sso-config/providers/config"
+)
+
+// maxImportSize bounds what is read from a URL, far more than any configuration needs
+const maxImportSize = 1 << 20
+
+// fetchTimeout bounds the download of an https bootstrap file
+const fetchTimeout = 30 * time.Second
+
+type cmd struct {
+	UI    cli.Ui
+	flags *pflag.FlagSet
+
+	configFile string
+	fromURL    string
+	merge      bool
+	replace    bool
+}
+
+func New(ui cli.Ui) *cmd {
+	c := &cmd{UI: ui}
+	c.flags = pflag.NewFlagSet("import", pflag.ContinueOnError)
+	c.flags.StringVarP(&c.configFile, "config", "c", "", "Path to configuration file to write")
+	c.flags.StringVar(&c.fromURL, "from-url", "", "Read the configuration from a file:// or https:// URL")
+	c.flags.BoolVar(&c.merge, "merge", false, "Set the imported values over your settings (default)")
+	c.flags.BoolVar(&c.replace, "replace", false, "Replace your configuration file with the imported one")
+	return c
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+	if c.merge && c.replace {
+		c.UI.Error("--merge and --replace cannot be used together")
+		return 1
+	}
+	if (c.flags.NArg() == 1) == (c.fromURL != "") || c.flags.NArg() > 1 {
+		c.UI.Error("Usage: aws-sso-config config import [--merge|--replace] <file> | --from-url <url>")
+		return 1
+	}
+
+	source := c.fromURL
+	var in io.ReadCloser
+	var err error
+	if source == "" {
+		source = c.flags.Arg(0)
+		in, err = openFile(source)
+	} else {
+		in, err = openURL(source)
+	}
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error reading %s: %v", source, err))
+		return 1
+	}
+	defer in.Close()
+
+	mode := appconfig.ImportMerge
+	if c.replace {
+		mode = appconfig.ImportReplace
+	}
+	cm := appconfig.NewConfigManager(c.configFile)
+	if err := cm.Import(in, mode); err != nil {
+		c.UI.Error(fmt.Sprintf("Error importing %s: %v", source, err))
+		return 1
+	}
+
+	c.UI.Output(fmt.Sprintf("Imported %s into %s", source, cm.Path()))
+	return 0
+}
+
+// openFile opens a file to import, standard input for -
+func openFile(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(path) // #nosec G304 - the user names the file to import
+}
+
+// openURL opens a file:// URL or downloads an https:// one
+func openURL(rawURL string) (io.ReadCloser, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "file":
+		if u.Host != "" && u.Host != "localhost" {
+			return nil, fmt.Errorf("file URLs must be local, e.g. file:///etc/team.toml")
+		}
+		return openFile(u.Path)
+	case "https":
+		ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		resp, err := http.DefaultClient.Do(req) // #nosec G107 G704 - the user names the URL to import
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			cancel()
+			return nil, fmt.Errorf("unexpected HTTP status %s", resp.Status)
+		}
+		return &limitedBody{Reader: io.LimitReader(resp.Body, maxImportSize), body: resp.Body, cancel: cancel}, nil
+	default:
+		return nil, fmt.Errorf("unsupported URL scheme %q, use file:// or https://", u.Scheme)
+	}
+}
+
+// limitedBody reads at most maxImportSize of a response and releases it on Close
+type limitedBody struct {
+	io.Reader
+	body   io.Closer
+	cancel context.CancelFunc
+}
+
+func (b *limitedBody) Close() error {
+	defer b.cancel()
+	return b.body.Close()
+}
+
+func (c *cmd) Help() string {
+	return `Usage: aws-sso-config config import [--merge|--replace] <file> | --from-url <url>
+
+  Import a configuration written by config export, such as a team file with
+  the organization's start URL, regions, role, contexts, [[repos]] mappings
+  and [[generate.chains]].
+
+  Every value is checked with the same validators as config set, and unknown
+  sections or keys are rejected. Nothing is written unless the whole file is
+  valid. Only your configuration file (~/.awsssoconfig) is written.
+
+  By default the imported values are merged over your settings, which keeps
+  the keys the file does not set. --replace replaces your file instead.
+
+  <file> may be - to read standard input. --from-url reads a file:// URL, or
+  downloads an https:// one.
+
+Flags:
+` + c.flags.FlagUsages() + `
+Examples:
+  # Bootstrap a new machine from the team file
+  aws-sso-config config import --from-url file:///srv/shared/aws-sso-config.toml
+
+  # Start over from a shared file
+  aws-sso-config config import --replace team.toml
+`
+}
+
+func (c *cmd) Synopsis() string {
+	return "Import a configuration written by config export"
+}
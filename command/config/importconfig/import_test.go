@@ -0,0 +1,60 @@
+package importconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	appconfig "github.com/blairham/aws-sso-config/providers/config"
+)
+
+func TestImportFromURL(t *testing.T) {
+	t.Setenv(appconfig.ContextEnv, "")
+	dir := t.TempDir()
+	team := filepath.Join(dir, "team.toml")
+	require.NoError(t, os.WriteFile(team, []byte("[sso]\nstart_url = \"https://team.awsapps.com/start\"\n"), 0600))
+	configFile := filepath.Join(dir, "config.toml")
+
+	ui := cli.NewMockUi()
+	code := New(ui).Run([]string{"--config", configFile, "--from-url", "file://" + team})
+	require.Equal(t, 0, code, ui.ErrorWriter.String())
+	assert.Equal(t, "Imported file://"+team+" into "+configFile+"\n", ui.OutputWriter.String())
+
+	data, err := os.ReadFile(configFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "https://team.awsapps.com/start")
+}
+
+func TestImportErrors(t *testing.T) {
+	dir := t.TempDir()
+	bad := filepath.Join(dir, "bad.toml")
+	require.NoError(t, os.WriteFile(bad, []byte("[sso]\nregion = \"narnia\"\n"), 0600))
+	configFile := filepath.Join(dir, "config.toml")
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"no source", nil, "Usage: aws-sso-config config import"},
+		{"both sources", []string{bad, "--from-url", "file://" + bad}, "Usage: aws-sso-config config import"},
+		{"both modes", []string{"--merge", "--replace", bad}, "cannot be used together"},
+		{"missing file", []string{filepath.Join(dir, "missing.toml")}, "no such file or directory"},
+		{"http", []string{"--from-url", "http://example.com/team.toml"}, `unsupported URL scheme "http"`},
+		{"remote file", []string{"--from-url", "file://server/team.toml"}, "file URLs must be local"},
+		{"invalid value", []string{bad}, `"narnia" is not an AWS region`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ui := cli.NewMockUi()
+			code := New(ui).Run(append([]string{"--config", configFile}, tt.args...))
+			assert.Equal(t, 1, code)
+			assert.Contains(t, ui.ErrorWriter.String(), tt.wantErr)
+			assert.NoFileExists(t, configFile)
+		})
+	}
+}
@@ -19,20 +19,30 @@ func New(ui cli.Ui) *cmd {
 }
 
 func (c *cmd) Run(args []string) int {
+	args, format, err := shared.ExtractOutput(args)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
 	// Parse arguments for paging options
 	forcePaging := false
+	showOrigin := false
 	var filteredArgs []string
 
 	for _, arg := range args {
-		if arg == "--force-paging" {
+		switch arg {
+		case "--force-paging":
 			forcePaging = true
-		} else {
+		case "--show-origin":
+			showOrigin = true
+		default:
 			filteredArgs = append(filteredArgs, arg)
 		}
 	}
 
 	if len(filteredArgs) != 0 {
-		c.UI.Error("Usage: aws-sso-config config list [--force-paging]")
+		c.UI.Error("Usage: aws-sso-config config list [--output <format>] [--show-origin] [--force-paging]")
 		c.UI.Error("")
 		c.UI.Error("This command takes no arguments except optional flags.")
 		return 1
@@ -45,6 +55,22 @@ func (c *cmd) Run(args []string) int {
 		return 1
 	}
 
+	// Machine-readable formats list every key, set or not, and are never paged
+	if format != shared.OutputText {
+		entries, err := shared.Entries(config, shared.ValidKeys)
+		if err != nil {
+			c.UI.Error(err.Error())
+			return 1
+		}
+		output, err := shared.FormatEntries(format, entries)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error formatting configuration: %v", err))
+			return 1
+		}
+		c.UI.Output(output)
+		return 0
+	}
+
 	// Collect all output lines for paging
 	var outputLines []string
 
@@ -56,9 +82,14 @@ func (c *cmd) Run(args []string) int {
 			continue
 		}
 		// Only output non-empty values
-		if value != "" {
-			outputLines = append(outputLines, fmt.Sprintf("%s=%s", key, value))
+		if value == "" {
+			continue
+		}
+		line := fmt.Sprintf("%s=%s", key, value)
+		if showOrigin {
+			line = config.Origins[key].String() + "\t" + line
 		}
+		outputLines = append(outputLines, line)
 	}
 
 	// Use pager for output
@@ -72,11 +103,24 @@ func (c *cmd) Run(args []string) int {
 }
 
 func (c *cmd) Help() string {
-	return `Usage: aws-sso-config config list [--force-paging]
+	return `Usage: aws-sso-config config list [--output <format>] [--show-origin] [--force-paging]
 
   List all configuration variables set in the config file with their values.
   Output format is key=value, one per line, similar to 'git config --list'.
 
+  Values are merged from these layers, each overriding the ones before:
+  built-in defaults, the system file (/etc/aws-sso-config/config.toml),
+  your file (~/.awsssoconfig), the project file (.aws-sso-config.toml in
+  the current directory or the nearest parent) and environment variables.
+  With --show-origin each line starts with the layer the value came from,
+  e.g. "project:/src/app/.aws-sso-config.toml", followed by a tab.
+
+  For scripts, --output json, yaml or toml prints every key, including
+  empty ones, with its value, default, source, environment variable and
+  description: an array in JSON and YAML, a [[keys]] array of tables in
+  TOML. --output env prints VARIABLE='value' lines that can be sourced by
+  a shell or read as a dotenv file. These formats are never paged.
+
   The output will automatically use an interactive pager (like 'less') when the
   output would be too long for the terminal screen. The pager provides full
   navigation with arrow keys, search functionality, and more.
@@ -95,7 +139,9 @@ Interactive Pager Controls:
   h                   Show help (in less)
 
 Flags:
-  --force-paging       Force paging even for short output (for testing)
+  -o, --output <format>  Output format: text (default), json, yaml, toml or env
+  --show-origin          Show the layer each value came from
+  --force-paging         Force paging even for short output (for testing)
 
 Environment Variables:
   NO_PAGER            Disable paging entirely
@@ -107,6 +153,12 @@ Examples:
   # List all configuration values (auto-paging)
   aws-sso-config config list
 
+  # Show where each value is set
+  aws-sso-config config list --show-origin
+
+  # Read a value in a script
+  aws-sso-config config list --output json | jq -r '.[] | select(.key == "sso.region") | .value'
+
   # Force interactive paging (useful for testing)
   aws-sso-config config list --force-paging
 
@@ -2,10 +2,16 @@ package list
 
 import (
 	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/mitchellh/cli"
+	"github.com/mitchellh/go-homedir"
+
+	"github.com/blairham/aws-sso-config/command/config/shared"
 )
 
 func TestListCommand(t *testing.T) {
@@ -107,3 +113,87 @@ func TestListSynopsis(t *testing.T) {
 		t.Errorf("Expected synopsis %q, got %q", expected, synopsis)
 	}
 }
+
+func TestListShowOrigin(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("NO_PAGER", "1")
+	homedir.DisableCache = true
+	t.Cleanup(func() { homedir.DisableCache = false })
+	project := filepath.Join(home, "src", "app")
+	if err := os.MkdirAll(project, 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".awsssoconfig"), []byte("[sso]\nrole = \"Mine\"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(project, ".aws-sso-config.toml"), []byte("[sso]\nregion = \"eu-west-1\"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(project)
+
+	var stdout, stderr bytes.Buffer
+	cmd := New(&cli.BasicUi{Writer: &stdout, ErrorWriter: &stderr})
+	if code := cmd.Run([]string{"--show-origin"}); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+
+	output := stdout.String()
+	for _, want := range []string{
+		"user:" + filepath.Join(home, ".awsssoconfig") + "\tsso.role=Mine\n",
+		"project:" + filepath.Join(project, ".aws-sso-config.toml") + "\tsso.region=eu-west-1\n",
+		"default\tsso.token_store=file\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got %s", want, output)
+		}
+	}
+}
+
+func TestListOutputJSON(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Chdir(home)
+	homedir.DisableCache = true
+	t.Cleanup(func() { homedir.DisableCache = false })
+	config := "[sso]\nrole = \"It's \\\"quoted\\\"\"\n"
+	if err := os.WriteFile(filepath.Join(home, ".awsssoconfig"), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := New(&cli.BasicUi{Writer: &stdout, ErrorWriter: &stderr})
+	if code := cmd.Run([]string{"--output", "json"}); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+
+	var entries []shared.Entry
+	if err := json.Unmarshal(stdout.Bytes(), &entries); err != nil {
+		t.Fatalf("Expected JSON output, got %v: %s", err, stdout.String())
+	}
+	if len(entries) != len(shared.ValidKeys) {
+		t.Errorf("Expected %d keys, got %d", len(shared.ValidKeys), len(entries))
+	}
+	want := shared.Entry{
+		Key:         "sso.role",
+		Value:       `It's "quoted"`,
+		Default:     "AdministratorAccess",
+		Source:      "user:" + filepath.Join(home, ".awsssoconfig"),
+		Env:         "AWS_SSO_CONFIG_SSO_ROLE",
+		Description: "SSO role name (e.g., AdministratorAccess)",
+	}
+	if entries[2] != want {
+		t.Errorf("Expected %+v, got %+v", want, entries[2])
+	}
+}
+
+func TestListUnsupportedOutput(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	cmd := New(&cli.BasicUi{Writer: &stdout, ErrorWriter: &stderr})
+	if code := cmd.Run([]string{"--output=xml"}); code != 1 {
+		t.Errorf("Expected exit code 1, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "unsupported output format: xml") {
+		t.Errorf("Expected unsupported format error, got %s", stderr.String())
+	}
+}
@@ -65,13 +65,12 @@ func (c *cmd) Help() string {
   The value can be provided with or without quotes. Multiple words
   will be joined with spaces to form the complete value.
 
-Available configuration keys:
-  sso.start_url        Your AWS SSO start URL
-  sso.region          AWS region for SSO (e.g., us-east-1)
-  sso.role            SSO role name (e.g., AdministratorAccess)
-  aws.default_region  Default AWS region for profiles
-  aws.config_file     Path to AWS config file
+  Values are checked before they are saved: start URLs must use https
+  (and /start on awsapps.com), regions must be known AWS regions, role
+  names must use IAM's character set and the AWS config file's
+  directory must exist and be writable. Errors suggest a correction.
 
+` + shared.KeysHelp() + `
 Examples:
   # Set the SSO start URL (no quotes needed)
   aws-sso-config config set sso.start_url https://mycompany.awsapps.com/start
@@ -83,7 +82,7 @@ Examples:
   aws-sso-config config set aws.config_file ~/.aws/config
 
   # Values with spaces work without quotes
-  aws-sso-config config set sso.role Administrator Access Role
+  aws-sso-config config set aws.config_file ~/My Documents/aws/config
 
   # Quotes still work if preferred
   aws-sso-config config set sso.start_url "https://mycompany.awsapps.com/start"
@@ -100,11 +100,25 @@ func TestSetMultipleWordValue(t *testing.T) {
 	c := New(ui)
 
 	// Test setting a value with multiple words (without quotes)
-	exitCode := c.Run([]string{"sso.role", "Administrator", "Access", "Role"})
+	assert.NoError(t, os.Mkdir(filepath.Join(tmpDir, "My Documents"), 0750))
+	exitCode := c.Run([]string{"aws.config_file", filepath.Join(tmpDir, "My"), "Documents/config"})
 	assert.Equal(t, 0, exitCode)
 
 	output := ui.OutputWriter.String()
-	assert.Contains(t, output, "Updated sso.role = Administrator Access Role")
+	assert.Contains(t, output, "Updated aws.config_file = "+filepath.Join(tmpDir, "My Documents", "config"))
+}
+
+func TestSetRejectsInvalidValue(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	ui := cli.NewMockUi()
+	c := New(ui)
+
+	exitCode := c.Run([]string{"sso.role", "Administrator", "Access"})
+	assert.Equal(t, 1, exitCode)
+	assert.Contains(t, ui.ErrorWriter.String(), `"Administrator Access" is not a valid role name, did you mean AdministratorAccess?`)
+	assert.NoFileExists(t, filepath.Join(tmpDir, ".awsssoconfig"))
 }
 
 func TestSetSingleWordValue(t *testing.T) {
@@ -1,28 +1,30 @@
 package shared
 
-// Configuration key constants
+import appconfig "github.com/blairham/aws-sso-config/providers/config"
+
+// Configuration key constants for code that refers to a key by name. Keys themselves
+// are registered by the struct tags of the config sections, see appconfig.Key.
 const (
-	KeySSOStartURL      = "sso.start_url"
-	KeySSORegion        = "sso.region"
-	KeySSORole          = "sso.role"
-	KeyAWSDefaultRegion = "aws.default_region"
-	KeyAWSConfigFile    = "aws.config_file"
+	KeySSOStartURL                 = "sso.start_url"
+	KeySSORegion                   = "sso.region"
+	KeySSORole                     = "sso.role"
+	KeySSOTokenStore               = "sso.token_store"
+	KeySSOCredentialRefreshMinutes = "sso.credential_refresh_minutes"
+	KeyAWSDefaultRegion            = "aws.default_region"
+	KeyAWSConfigFile               = "aws.config_file"
 )
 
 // ValidKeys contains all valid configuration keys
-var ValidKeys = []string{
-	KeySSOStartURL,
-	KeySSORegion,
-	KeySSORole,
-	KeyAWSDefaultRegion,
-	KeyAWSConfigFile,
-}
+var ValidKeys = appconfig.KeyNames()
 
 // KeyDescriptions maps configuration keys to their descriptions
-var KeyDescriptions = map[string]string{
-	KeySSOStartURL:      "Your AWS SSO start URL",
-	KeySSORegion:        "AWS region for SSO (e.g., us-east-1)",
-	KeySSORole:          "SSO role name (e.g., AdministratorAccess)",
-	KeyAWSDefaultRegion: "Default AWS region for profiles",
-	KeyAWSConfigFile:    "Path to AWS config file",
+var KeyDescriptions = keyDescriptions()
+
+// keyDescriptions collects the description of every registered key
+func keyDescriptions() map[string]string {
+	descriptions := make(map[string]string)
+	for _, k := range appconfig.Keys() {
+		descriptions[k.Name] = k.Description
+	}
+	return descriptions
 }
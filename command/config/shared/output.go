@@ -0,0 +1,167 @@
+package shared
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"go.yaml.in/yaml/v3"
+
+	appconfig "github.com/blairham/aws-sso-config/providers/config"
+)
+
+// Output formats of config get and config list
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+	OutputYAML = "yaml"
+	OutputTOML = "toml"
+	OutputEnv  = "env"
+)
+
+// OutputFormats lists the formats accepted by --output
+var OutputFormats = []string{OutputText, OutputJSON, OutputYAML, OutputTOML, OutputEnv}
+
+// Entry describes one configuration key in machine-readable output. Values are
+// strings, as printed by config get.
+type Entry struct {
+	Key         string `json:"key" yaml:"key" toml:"key"`
+	Value       string `json:"value" yaml:"value" toml:"value"`
+	Default     string `json:"default" yaml:"default" toml:"default"`
+	Source      string `json:"source" yaml:"source" toml:"source"`
+	Env         string `json:"env" yaml:"env" toml:"env"`
+	Description string `json:"description" yaml:"description" toml:"description"`
+}
+
+// ExtractOutput removes --output/-o and its value from args, returning the format,
+// text when it is not given
+func ExtractOutput(args []string) ([]string, string, error) {
+	format := OutputText
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--output" || arg == "-o":
+			if i+1 >= len(args) {
+				return nil, "", fmt.Errorf("flag needs an argument: %s", arg)
+			}
+			i++
+			format = args[i]
+		case strings.HasPrefix(arg, "--output="):
+			format = strings.TrimPrefix(arg, "--output=")
+		default:
+			rest = append(rest, arg)
+			continue
+		}
+		if !slices.Contains(OutputFormats, format) {
+			return nil, "", fmt.Errorf("unsupported output format: %s (expected %s)", format, strings.Join(OutputFormats, ", "))
+		}
+	}
+	return rest, format, nil
+}
+
+// Entries describes the given keys of config, where each value came from included
+func Entries(config *appconfig.Config, keys []string) ([]Entry, error) {
+	entries := make([]Entry, 0, len(keys))
+	for _, key := range keys {
+		k, ok := appconfig.LookupKey(key)
+		if !ok {
+			return nil, fmt.Errorf("unknown configuration key: %s", key)
+		}
+		origin, ok := config.Origins[key]
+		if !ok {
+			origin = appconfig.Origin{Layer: appconfig.LayerDefault}
+		}
+		entries = append(entries, Entry{
+			Key:         k.Name,
+			Value:       k.Get(config),
+			Default:     k.Default(),
+			Source:      origin.String(),
+			Env:         k.Env,
+			Description: k.Description,
+		})
+	}
+	return entries, nil
+}
+
+// FormatEntry formats a single entry, as printed by config get
+func FormatEntry(format string, entry Entry) (string, error) {
+	switch format {
+	case OutputJSON:
+		return marshalJSON(entry)
+	case OutputYAML:
+		return marshalYAML(entry)
+	case OutputTOML:
+		return marshalTOML(entry)
+	case OutputEnv:
+		return envLines([]Entry{entry}), nil
+	default:
+		return entry.Value, nil
+	}
+}
+
+// FormatEntries formats a list of entries, as printed by config list: an array
+// in JSON and YAML, and a [[keys]] array of tables in TOML
+func FormatEntries(format string, entries []Entry) (string, error) {
+	switch format {
+	case OutputJSON:
+		return marshalJSON(entries)
+	case OutputYAML:
+		return marshalYAML(entries)
+	case OutputTOML:
+		return marshalTOML(struct {
+			Keys []Entry `toml:"keys"`
+		}{entries})
+	case OutputEnv:
+		return envLines(entries), nil
+	default:
+		lines := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			lines = append(lines, entry.Key+"="+entry.Value)
+		}
+		return strings.Join(lines, "\n"), nil
+	}
+}
+
+func marshalJSON(v any) (string, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func marshalYAML(v any) (string, error) {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(data), "\n"), nil
+}
+
+func marshalTOML(v any) (string, error) {
+	data, err := toml.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(data), "\n"), nil
+}
+
+// envLines formats entries as VARIABLE='value' lines that can be sourced by a
+// POSIX shell or read as a dotenv file, each after a comment describing the key
+func envLines(entries []Entry) string {
+	lines := make([]string, 0, 2*len(entries))
+	for _, entry := range entries {
+		lines = append(lines,
+			fmt.Sprintf("# %s: %s (source %q, default %q)", entry.Key, entry.Description, entry.Source, entry.Default),
+			entry.Env+"="+shellQuote(entry.Value))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// shellQuote wraps a value in single quotes for POSIX shells
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
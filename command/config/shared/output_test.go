@@ -0,0 +1,85 @@
+package shared
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	appconfig "github.com/blairham/aws-sso-config/providers/config"
+)
+
+func TestExtractOutput(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantArgs   []string
+		wantFormat string
+		wantErr    string
+	}{
+		{"default", []string{"sso.region"}, []string{"sso.region"}, OutputText, ""},
+		{"long flag", []string{"--output", "json", "sso.region"}, []string{"sso.region"}, OutputJSON, ""},
+		{"equals", []string{"sso.region", "--output=yaml"}, []string{"sso.region"}, OutputYAML, ""},
+		{"short flag", []string{"-o", "env", "--show-origin"}, []string{"--show-origin"}, OutputEnv, ""},
+		{"missing value", []string{"--output"}, nil, "", "flag needs an argument: --output"},
+		{"unsupported", []string{"-o", "xml"}, nil, "", "unsupported output format: xml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, format, err := ExtractOutput(tt.args)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantArgs, args)
+			assert.Equal(t, tt.wantFormat, format)
+		})
+	}
+}
+
+func TestFormatEntries(t *testing.T) {
+	config := appconfig.Default()
+	config.SSO.Role = "It's \"odd\"\n"
+	config.Origins = map[string]appconfig.Origin{
+		KeySSORole: {Layer: appconfig.LayerEnv, Source: "AWS_SSO_CONFIG_SSO_ROLE"},
+	}
+	entries, err := Entries(config, []string{KeySSORole, KeySSOCredentialRefreshMinutes})
+	require.NoError(t, err)
+	assert.Equal(t, "default", entries[1].Source, "keys without an origin come from the defaults")
+
+	_, err = Entries(config, []string{"sso.nope"})
+	assert.ErrorContains(t, err, "unknown configuration key")
+
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{OutputText, []string{"sso.role=It's \"odd\"\n\nsso.credential_refresh_minutes=5"}},
+		{OutputJSON, []string{`"value": "It's \"odd\"\n"`, `"source": "env:AWS_SSO_CONFIG_SSO_ROLE"`, `"default": "5"`}},
+		{OutputYAML, []string{"- key: sso.role\n  value: |\n    It's \"odd\"\n", "env: AWS_SSO_CONFIG_SSO_CREDENTIAL_REFRESH_MINUTES"}},
+		{OutputTOML, []string{"[[keys]]\nkey = 'sso.role'", `value = "It's \"odd\"\n"`}},
+		{OutputEnv, []string{
+			"# sso.role: SSO role name (e.g., AdministratorAccess) (source \"env:AWS_SSO_CONFIG_SSO_ROLE\", default \"AdministratorAccess\")\n",
+			"AWS_SSO_CONFIG_SSO_ROLE='It'\\''s \"odd\"\n'\n",
+			"AWS_SSO_CONFIG_SSO_CREDENTIAL_REFRESH_MINUTES='5'",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			output, err := FormatEntries(tt.format, entries)
+			require.NoError(t, err)
+			for _, want := range tt.want {
+				assert.Contains(t, output, want)
+			}
+		})
+	}
+
+	t.Run("single entry", func(t *testing.T) {
+		output, err := FormatEntry(OutputJSON, entries[1])
+		require.NoError(t, err)
+		assert.Contains(t, output, `"key": "sso.credential_refresh_minutes"`)
+		assert.NotContains(t, output, "[")
+	})
+}
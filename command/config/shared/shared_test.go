@@ -1,6 +1,7 @@
 package shared
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -14,6 +15,8 @@ func TestIsValidKey(t *testing.T) {
 		"sso.start_url",
 		"sso.region",
 		"sso.role",
+		"sso.token_store",
+		"sso.credential_refresh_minutes",
 		"aws.default_region",
 		"aws.config_file",
 	}
@@ -37,15 +40,17 @@ func TestIsValidKey(t *testing.T) {
 
 func TestValidKeysConstant(t *testing.T) {
 	// Verify that ValidKeys has the expected number of keys
-	assert.Len(t, ValidKeys, 5, "ValidKeys should contain 5 keys")
+	assert.Len(t, ValidKeys, 7, "ValidKeys should contain 7 keys")
 
 	// Verify all expected keys are present
 	expectedKeys := map[string]bool{
-		"sso.start_url":      true,
-		"sso.region":         true,
-		"sso.role":           true,
-		"aws.default_region": true,
-		"aws.config_file":    true,
+		"sso.start_url":                  true,
+		"sso.region":                     true,
+		"sso.role":                       true,
+		"sso.token_store":                true,
+		"sso.credential_refresh_minutes": true,
+		"aws.default_region":             true,
+		"aws.config_file":                true,
 	}
 
 	for _, key := range ValidKeys {
@@ -68,6 +73,8 @@ func TestGetConfigValue(t *testing.T) {
 	config.SSO.StartURL = "https://test.awsapps.com/start"
 	config.SSO.Region = "us-west-2"
 	config.SSO.Role = "TestRole"
+	config.SSO.TokenStore = "keyring"
+	config.SSO.CredentialRefreshMinutes = 10
 	config.AWS.DefaultRegion = "us-east-1"
 	config.AWS.ConfigFile = "/test/config"
 
@@ -78,6 +85,8 @@ func TestGetConfigValue(t *testing.T) {
 		{KeySSOStartURL, "https://test.awsapps.com/start"},
 		{KeySSORegion, "us-west-2"},
 		{KeySSORole, "TestRole"},
+		{KeySSOTokenStore, "keyring"},
+		{KeySSOCredentialRefreshMinutes, "10"},
 		{KeyAWSDefaultRegion, "us-east-1"},
 		{KeyAWSConfigFile, "/test/config"},
 	}
@@ -94,10 +103,17 @@ func TestGetConfigValue(t *testing.T) {
 	_, err := GetConfigValue(config, "invalid_key")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "unknown configuration key")
+
+	// Test invalid minutes
+	for _, value := range []string{"soon", "-1", "1.5"} {
+		err = SetConfigValue(config, KeySSOCredentialRefreshMinutes, value)
+		assert.ErrorContains(t, err, "is not a whole number of minutes")
+	}
 }
 
 func TestSetConfigValue(t *testing.T) {
 	config := &appconfig.Config{}
+	configFile := filepath.Join(t.TempDir(), "config")
 
 	tests := []struct {
 		key   string
@@ -106,8 +122,10 @@ func TestSetConfigValue(t *testing.T) {
 		{KeySSOStartURL, "https://new.awsapps.com/start"},
 		{KeySSORegion, "eu-west-1"},
 		{KeySSORole, "NewRole"},
+		{KeySSOTokenStore, "encrypted-file"},
+		{KeySSOCredentialRefreshMinutes, "15"},
 		{KeyAWSDefaultRegion, "ap-south-1"},
-		{KeyAWSConfigFile, "/new/config"},
+		{KeyAWSConfigFile, configFile},
 	}
 
 	for _, tt := range tests {
@@ -131,11 +149,13 @@ func TestSetConfigValue(t *testing.T) {
 func TestAllValidKeysHaveConstants(t *testing.T) {
 	// Ensure all valid keys have corresponding constants
 	expectedConstants := map[string]string{
-		"sso.start_url":      KeySSOStartURL,
-		"sso.region":         KeySSORegion,
-		"sso.role":           KeySSORole,
-		"aws.default_region": KeyAWSDefaultRegion,
-		"aws.config_file":    KeyAWSConfigFile,
+		"sso.start_url":                  KeySSOStartURL,
+		"sso.region":                     KeySSORegion,
+		"sso.role":                       KeySSORole,
+		"sso.token_store":                KeySSOTokenStore,
+		"sso.credential_refresh_minutes": KeySSOCredentialRefreshMinutes,
+		"aws.default_region":             KeyAWSDefaultRegion,
+		"aws.config_file":                KeyAWSConfigFile,
 	}
 
 	for validKey, expectedConstant := range expectedConstants {
@@ -149,6 +169,8 @@ func TestKeyConstantsMatch(t *testing.T) {
 	assert.Equal(t, "sso.start_url", KeySSOStartURL)
 	assert.Equal(t, "sso.region", KeySSORegion)
 	assert.Equal(t, "sso.role", KeySSORole)
+	assert.Equal(t, "sso.token_store", KeySSOTokenStore)
+	assert.Equal(t, "sso.credential_refresh_minutes", KeySSOCredentialRefreshMinutes)
 	assert.Equal(t, "aws.default_region", KeyAWSDefaultRegion)
 	assert.Equal(t, "aws.config_file", KeyAWSConfigFile)
 }
@@ -36,51 +36,40 @@ func (c *cmd) Run(args []string) int {
 	}
 
 	// Load current config
-	config, err := appconfig.LoadConfigForKey("", key)
-	if err != nil {
+	if _, err := appconfig.LoadConfigForKey("", key); err != nil {
 		c.UI.Error(fmt.Sprintf("Error loading config: %v", err))
 		return 1
 	}
 
-	// Get the default value for this key
-	defaultValue, err := c.getDefaultValue(key)
-	if err != nil {
-		c.UI.Error(fmt.Sprintf("Error getting default value: %v", err))
+	// Remove the value from the user file
+	if err := shared.ResetConfigValue("", key); err != nil {
+		c.UI.Error(fmt.Sprintf("Error saving config: %v", err))
 		return 1
 	}
 
-	// Reset the value to default
-	if err := shared.SetConfigValue(config, key, defaultValue); err != nil {
-		c.UI.Error(fmt.Sprintf("Error setting config value: %v", err))
+	// Report the value that applies now, which a system or project file may set
+	config, err := appconfig.Load("")
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error loading config: %v", err))
 		return 1
 	}
-
-	// Save the updated configuration
-	if err := shared.SaveConfigValue("", key, defaultValue); err != nil {
-		c.UI.Error(fmt.Sprintf("Error saving config: %v", err))
+	value, err := shared.GetConfigValue(config, key)
+	if err != nil {
+		c.UI.Error(err.Error())
 		return 1
 	}
+	if origin := config.Origins[key]; origin.Layer != appconfig.LayerDefault {
+		c.UI.Output(fmt.Sprintf("Reset %s, now %s from %s", key, value, origin))
+		return 0
+	}
 
-	c.UI.Output(fmt.Sprintf("Reset %s to default value: %s", key, defaultValue))
+	c.UI.Output(fmt.Sprintf("Reset %s to default value: %s", key, value))
 	return 0
 }
 
 // getDefaultValue returns the default value for a given configuration key
 func (c *cmd) getDefaultValue(key string) (string, error) {
-	switch key {
-	case shared.KeySSOStartURL:
-		return appconfig.DefaultSSO().StartURL, nil
-	case shared.KeySSORegion:
-		return appconfig.DefaultSSO().Region, nil
-	case shared.KeySSORole:
-		return appconfig.DefaultSSO().Role, nil
-	case shared.KeyAWSDefaultRegion:
-		return appconfig.DefaultAWS().DefaultRegion, nil
-	case shared.KeyAWSConfigFile:
-		return appconfig.DefaultAWS().ConfigFile, nil
-	default:
-		return "", fmt.Errorf("unknown configuration key: %s", key)
-	}
+	return shared.DefaultValue(key)
 }
 
 func (c *cmd) Help() string {
@@ -88,16 +77,11 @@ func (c *cmd) Help() string {
 
   Reset a configuration value to its default.
 
-  This command removes any custom configuration for the specified key
-  and restores it to the default value.
-
-Available configuration keys:
-  sso.start_url        Your AWS SSO start URL
-  sso.region          AWS region for SSO (e.g., us-east-1)
-  sso.role            SSO role name (e.g., AdministratorAccess)
-  aws.default_region  Default AWS region for profiles
-  aws.config_file     Path to AWS config file
+  This command removes the specified key from your configuration file,
+  or from the selected context's settings in it, and restores the
+  default value or the value set by the system or project file.
 
+` + shared.KeysHelp() + `
 Examples:
   # Reset SSO start URL to default
   aws-sso-config config unset sso.start_url
@@ -101,6 +101,8 @@ func TestCmd_getDefaultValue(t *testing.T) {
 		{shared.KeySSOStartURL, appconfig.DefaultSSO().StartURL, false},
 		{shared.KeySSORegion, appconfig.DefaultSSO().Region, false},
 		{shared.KeySSORole, appconfig.DefaultSSO().Role, false},
+		{shared.KeySSOTokenStore, "file", false},
+		{shared.KeySSOCredentialRefreshMinutes, "5", false},
 		{shared.KeyAWSDefaultRegion, appconfig.DefaultAWS().DefaultRegion, false},
 		{shared.KeyAWSConfigFile, appconfig.DefaultAWS().ConfigFile, false},
 		{"invalid.key", "", true},
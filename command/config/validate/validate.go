@@ -0,0 +1,147 @@
+package validate
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc"
+	"github.com/mitchellh/cli"
+	"github.com/spf13/pflag"
+
+	awsprovider "github.com/blairham/aws-sso-config/providers/aws"
+	appconfig "github.com/blairham/aws-sso-config/providers/config"
+)
+
+type cmd struct {
+	UI    cli.Ui
+	ctx   context.Context
+	flags *pflag.FlagSet
+
+	online bool
+
+	// Dependencies for testing
+	configLoader  func(context.Context) (aws.Config, error)
+	clientFactory func(aws.Config) awsprovider.SSOOIDCClient
+}
+
+func New(ctx context.Context, ui cli.Ui) *cmd {
+	return NewWithDependencies(ctx, ui, awsprovider.LoadDefaultConfig, func(cfg aws.Config) awsprovider.SSOOIDCClient {
+		return ssooidc.NewFromConfig(cfg)
+	})
+}
+
+// NewWithDependencies creates a new command with injected dependencies for testing
+func NewWithDependencies(
+	ctx context.Context,
+	ui cli.Ui,
+	configLoader func(context.Context) (aws.Config, error),
+	clientFactory func(aws.Config) awsprovider.SSOOIDCClient,
+) *cmd {
+	c := &cmd{UI: ui, ctx: ctx, configLoader: configLoader, clientFactory: clientFactory}
+	c.flags = pflag.NewFlagSet("validate", pflag.ContinueOnError)
+	c.flags.BoolVar(&c.online, "online", false, "Also check each start URL with the SSO OIDC service")
+	return c
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+	if c.flags.NArg() > 1 {
+		c.UI.Error("Usage: aws-sso-config config validate [--online] [file]")
+		return 1
+	}
+
+	path := c.flags.Arg(0)
+	if path == "" {
+		path = appconfig.NewConfigManager("").Path()
+	}
+	report, err := appconfig.CheckFile(path)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error reading %s: %v", path, err))
+		return 1
+	}
+
+	problems := report.Problems
+	if c.online {
+		onlineProblems, err := c.checkOnline(path, report.Portals)
+		if err != nil {
+			c.UI.Error(err.Error())
+			return 1
+		}
+		problems = append(problems, onlineProblems...)
+		slices.SortStableFunc(problems, func(a, b appconfig.Problem) int { return a.Line - b.Line })
+	}
+
+	for _, p := range problems {
+		if p.Line > 0 {
+			c.UI.Error(fmt.Sprintf("%s:%d: %s", path, p.Line, p.Message))
+		} else {
+			c.UI.Error(fmt.Sprintf("%s: %s", path, p.Message))
+		}
+	}
+	if len(problems) > 0 {
+		c.UI.Error(fmt.Sprintf("%d problem(s) found", len(problems)))
+		return 1
+	}
+
+	c.UI.Output(fmt.Sprintf("%s is valid", path))
+	return 0
+}
+
+// checkOnline starts a device authorization for each start URL in its SSO region
+func (c *cmd) checkOnline(path string, portals []appconfig.Portal) ([]appconfig.Problem, error) {
+	if len(portals) == 0 {
+		c.UI.Warn(fmt.Sprintf("%s sets no start URL, --online has nothing to check", path))
+		return nil, nil
+	}
+
+	cfg, err := c.configLoader(c.ctx)
+	if err != nil {
+		return nil, err
+	}
+	var problems []appconfig.Problem
+	for _, portal := range portals {
+		cfg.Region = portal.Region
+		if err := awsprovider.CheckStartURL(c.ctx, c.clientFactory(cfg), portal.StartURL); err != nil {
+			message := fmt.Sprintf("start URL %s does not respond in %s: %v", portal.StartURL, portal.Region, err)
+			problems = append(problems, appconfig.Problem{Line: portal.Line, Message: message})
+		}
+	}
+	return problems, nil
+}
+
+func (c *cmd) Help() string {
+	return `Usage: aws-sso-config config validate [--online] [file]
+
+  Check a configuration file, ~/.awsssoconfig by default, for use in CI.
+  Only the given file is checked; other layers are not merged in.
+
+  The file is parsed as strict TOML in the current layout. Unknown sections
+  and keys are reported with their line numbers, as is any value that fails
+  the same validators as config set, an undefined current_context, and
+  invalid [[generate.chains]] or [[repos]] entries.
+
+  With --online each start URL in the file, at the top level or in a
+  context, is checked by starting an SSO OIDC device authorization in its
+  SSO region. Nobody is asked to approve it.
+
+  Problems are printed as file:line: message. The exit code is 1 when any
+  problem is found.
+
+Flags:
+` + c.flags.FlagUsages() + `
+Examples:
+  # Check your configuration
+  aws-sso-config config validate
+
+  # Check a team file in CI, including its start URLs
+  aws-sso-config config validate --online team.toml
+`
+}
+
+func (c *cmd) Synopsis() string {
+	return "Check a configuration file"
+}
@@ -0,0 +1,110 @@
+package validate
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	awsprovider "github.com/blairham/aws-sso-config/providers/aws"
+)
+
+// mockClient implements awsprovider.SSOOIDCClient, rejecting the start URLs in bad
+type mockClient struct {
+	region  string
+	bad     map[string]bool
+	regions *[]string
+}
+
+func (m *mockClient) RegisterClient(context.Context, *ssooidc.RegisterClientInput, ...func(*ssooidc.Options)) (*ssooidc.RegisterClientOutput, error) {
+	*m.regions = append(*m.regions, m.region)
+	return &ssooidc.RegisterClientOutput{ClientId: aws.String("id"), ClientSecret: aws.String("secret")}, nil
+}
+
+func (m *mockClient) StartDeviceAuthorization(
+	_ context.Context,
+	params *ssooidc.StartDeviceAuthorizationInput,
+	_ ...func(*ssooidc.Options),
+) (*ssooidc.StartDeviceAuthorizationOutput, error) {
+	if m.bad[aws.ToString(params.StartUrl)] {
+		return nil, errors.New("InvalidRequestException")
+	}
+	return &ssooidc.StartDeviceAuthorizationOutput{DeviceCode: aws.String("code")}, nil
+}
+
+func (m *mockClient) CreateToken(context.Context, *ssooidc.CreateTokenInput, ...func(*ssooidc.Options)) (*ssooidc.CreateTokenOutput, error) {
+	return nil, errors.New("not used")
+}
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func newCmd(ui cli.Ui, bad map[string]bool, regions *[]string) *cmd {
+	loader := func(context.Context) (aws.Config, error) { return aws.Config{}, nil }
+	return NewWithDependencies(context.Background(), ui, loader, func(cfg aws.Config) awsprovider.SSOOIDCClient {
+		return &mockClient{region: cfg.Region, bad: bad, regions: regions}
+	})
+}
+
+const teamConfig = `[sso]
+start_url = "https://team.awsapps.com/start"
+region = "eu-west-1"
+
+[contexts.old.sso]
+start_url = "https://old.awsapps.com/start"
+`
+
+func TestValidateValid(t *testing.T) {
+	path := writeFile(t, teamConfig)
+	ui := cli.NewMockUi()
+	var regions []string
+
+	code := newCmd(ui, nil, &regions).Run([]string{path})
+	require.Equal(t, 0, code, ui.ErrorWriter.String())
+	assert.Equal(t, path+" is valid\n", ui.OutputWriter.String())
+	assert.Empty(t, regions, "start URLs are only checked with --online")
+}
+
+func TestValidateProblems(t *testing.T) {
+	path := writeFile(t, "[sso]\nregion = \"narnia\"\n\n[proxy]\nurl = \"http://proxy\"\n")
+	ui := cli.NewMockUi()
+
+	code := newCmd(ui, nil, new([]string)).Run([]string{path})
+	assert.Equal(t, 1, code)
+	assert.Equal(t, path+":2: invalid value for sso.region: \"narnia\" is not an AWS region\n"+
+		path+":4: unknown configuration section: proxy\n"+
+		"2 problem(s) found\n", ui.ErrorWriter.String())
+}
+
+func TestValidateOnline(t *testing.T) {
+	path := writeFile(t, teamConfig)
+	ui := cli.NewMockUi()
+	var regions []string
+
+	code := newCmd(ui, map[string]bool{"https://old.awsapps.com/start": true}, &regions).Run([]string{"--online", path})
+	assert.Equal(t, 1, code)
+	assert.Equal(t, []string{"eu-west-1", "eu-west-1"}, regions, "a context without a region uses the top-level one")
+	assert.Contains(t, ui.ErrorWriter.String(), path+":6: start URL https://old.awsapps.com/start does not respond in eu-west-1")
+	assert.Contains(t, ui.ErrorWriter.String(), "1 problem(s) found")
+}
+
+func TestValidateErrors(t *testing.T) {
+	ui := cli.NewMockUi()
+	assert.Equal(t, 1, newCmd(ui, nil, new([]string)).Run([]string{"a.toml", "b.toml"}))
+	assert.Contains(t, ui.ErrorWriter.String(), "Usage: aws-sso-config config validate")
+
+	ui = cli.NewMockUi()
+	assert.Equal(t, 1, newCmd(ui, nil, new([]string)).Run([]string{filepath.Join(t.TempDir(), "missing.toml")}))
+	assert.Contains(t, ui.ErrorWriter.String(), "no such file or directory")
+}
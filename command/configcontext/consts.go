@@ -0,0 +1,41 @@
+package configcontext
+
+const synopsis = "List, select and manage configuration contexts"
+const help = `
+Usage: aws-sso-config context [options] <subcommand> [args]
+
+  Manage named contexts in the configuration file. A context holds its
+  own [contexts.<name>.sso] and [contexts.<name>.aws] settings, which
+  are merged over the top-level [sso] and [aws] settings when it is
+  selected. Keys a context does not set keep their top-level values.
+
+  The context is selected by, in order:
+
+    1. the --context <name> flag, accepted by every command
+    2. the AWS_SSO_CONFIG_CONTEXT environment variable
+    3. current_context in the configuration file, set by context use
+
+  While a context is selected, config set and config unset change the
+  context's settings rather than the top-level ones.
+
+Subcommands:
+  list                       List contexts, marking the selected one
+  current                    Print the selected context
+  use <name>                 Select a context in the configuration file
+  use --none                 Go back to the top-level settings
+  create <name> key=value... Create a context with the given settings
+  delete <name>              Delete a context
+
+Examples:
+
+  # Create contexts for two organizations
+  aws-sso-config context create work sso.start_url=https://work.awsapps.com/start
+  aws-sso-config context create client-a \
+    sso.start_url=https://client-a.awsapps.com/start sso.region=eu-west-1
+
+  # Select one for every later command
+  aws-sso-config context use work
+
+  # Or for a single command
+  aws-sso-config --context client-a login
+`
@@ -0,0 +1,177 @@
+package configcontext
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mitchellh/cli"
+	"github.com/spf13/pflag"
+
+	appconfig "github.com/blairham/aws-sso-config/providers/config"
+)
+
+type cmd struct {
+	UI    cli.Ui
+	flags *pflag.FlagSet
+	help  string
+
+	configFile string
+	none       bool
+}
+
+func New(ui cli.Ui) *cmd {
+	c := &cmd{UI: ui}
+	c.Init()
+	return c
+}
+
+func (c *cmd) Init() {
+	c.flags = pflag.NewFlagSet("context", pflag.ContinueOnError)
+	c.flags.StringVarP(&c.configFile, "config", "c", "", "Path to configuration file")
+	c.flags.BoolVar(&c.none, "none", false, "With use, go back to the top-level settings")
+
+	c.help = help + "\n" + c.flags.FlagUsages()
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+	if c.flags.NArg() == 0 {
+		c.UI.Error("Usage: aws-sso-config context [options] <subcommand> [args]")
+		c.UI.Error("Subcommands: list, current, use, create, delete")
+		return 1
+	}
+
+	cm := appconfig.NewConfigManager(c.configFile)
+	subcommand, subArgs := c.flags.Arg(0), c.flags.Args()[1:]
+
+	var err error
+	switch subcommand {
+	case "list":
+		err = c.list(cm)
+	case "current":
+		return c.current(cm)
+	case "use":
+		err = c.use(cm, subArgs)
+	case "create":
+		err = c.create(cm, subArgs)
+	case "delete":
+		err = c.delete(cm, subArgs)
+	default:
+		err = fmt.Errorf("unknown subcommand: %s", subcommand)
+	}
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+	return 0
+}
+
+// list prints each context, marking the selected one like git branch
+func (c *cmd) list(cm *appconfig.ConfigManager) error {
+	names, err := cm.Contexts()
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		c.UI.Error("No contexts are defined. Create one with: aws-sso-config context create <name> key=value...")
+		return nil
+	}
+
+	selection, err := cm.CurrentContext()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		marker := " "
+		if name == selection.Name {
+			marker = "*"
+		}
+		c.UI.Output(fmt.Sprintf("%s %s", marker, name))
+	}
+	return nil
+}
+
+// current prints the selected context, failing when the top-level settings apply
+func (c *cmd) current(cm *appconfig.ConfigManager) int {
+	selection, err := cm.CurrentContext()
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+	if selection.Name == "" {
+		c.UI.Error("No context is selected, the top-level settings apply")
+		return 1
+	}
+	c.UI.Output(selection.Name)
+	return 0
+}
+
+// use records the selected context, or clears it with --none
+func (c *cmd) use(cm *appconfig.ConfigManager, args []string) error {
+	if c.none == (len(args) == 1) || len(args) > 1 {
+		return fmt.Errorf("usage: aws-sso-config context use <name> | --none")
+	}
+
+	name := ""
+	if !c.none {
+		name = args[0]
+	}
+	if err := cm.UseContext(name); err != nil {
+		return err
+	}
+
+	if name == "" {
+		c.UI.Output("Switched to the top-level settings")
+	} else {
+		c.UI.Output(fmt.Sprintf("Switched to context %s", name))
+	}
+	if selection, err := cm.CurrentContext(); err == nil && selection.FromEnvironment && selection.Name != name {
+		c.UI.Warn(fmt.Sprintf("%s selects %s in this shell, which takes precedence", appconfig.ContextEnv, selection.Name))
+	}
+	return nil
+}
+
+// create defines a context from key=value arguments
+func (c *cmd) create(cm *appconfig.ConfigManager, args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("usage: aws-sso-config context create <name> key=value...")
+	}
+
+	values := make(map[string]string, len(args)-1)
+	for _, arg := range args[1:] {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return fmt.Errorf("expected key=value, got %q", arg)
+		}
+		values[key] = value
+	}
+	if err := cm.CreateContext(args[0], values); err != nil {
+		return err
+	}
+
+	c.UI.Output(fmt.Sprintf("Created context %s", args[0]))
+	return nil
+}
+
+// delete removes a context
+func (c *cmd) delete(cm *appconfig.ConfigManager, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: aws-sso-config context delete <name>")
+	}
+	if err := cm.DeleteContext(args[0]); err != nil {
+		return err
+	}
+
+	c.UI.Output(fmt.Sprintf("Deleted context %s", args[0]))
+	return nil
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
+
+func (c *cmd) Help() string {
+	return c.help
+}
@@ -0,0 +1,95 @@
+package configcontext
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	appconfig "github.com/blairham/aws-sso-config/providers/config"
+)
+
+func run(t *testing.T, configFile string, args ...string) (int, *cli.MockUi) {
+	t.Helper()
+	ui := cli.NewMockUi()
+	code := New(ui).Run(append([]string{"--config", configFile}, args...))
+	return code, ui
+}
+
+func TestContextCommand(t *testing.T) {
+	t.Setenv(appconfig.ContextEnv, "")
+	configFile := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(configFile, []byte("[sso]\nregion = \"us-east-1\"\n"), 0600))
+
+	code, ui := run(t, configFile, "list")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, ui.ErrorWriter.String(), "No contexts are defined")
+
+	code, ui = run(t, configFile, "create", "work", "sso.start_url=https://work.awsapps.com/start")
+	require.Equal(t, 0, code, ui.ErrorWriter.String())
+	assert.Equal(t, "Created context work\n", ui.OutputWriter.String())
+
+	code, ui = run(t, configFile, "create", "client-a", "sso.region=eu-west-1", "aws.default_region=eu-west-1")
+	require.Equal(t, 0, code, ui.ErrorWriter.String())
+
+	code, ui = run(t, configFile, "current")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, ui.ErrorWriter.String(), "No context is selected")
+
+	code, ui = run(t, configFile, "use", "work")
+	require.Equal(t, 0, code, ui.ErrorWriter.String())
+	assert.Equal(t, "Switched to context work\n", ui.OutputWriter.String())
+
+	code, ui = run(t, configFile, "list")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "  client-a\n* work\n", ui.OutputWriter.String())
+
+	code, ui = run(t, configFile, "current")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "work\n", ui.OutputWriter.String())
+
+	t.Setenv(appconfig.ContextEnv, "client-a")
+	code, ui = run(t, configFile, "current")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "client-a\n", ui.OutputWriter.String())
+	t.Setenv(appconfig.ContextEnv, "")
+
+	code, ui = run(t, configFile, "use", "--none")
+	require.Equal(t, 0, code, ui.ErrorWriter.String())
+	assert.Equal(t, "Switched to the top-level settings\n", ui.OutputWriter.String())
+
+	code, ui = run(t, configFile, "delete", "work")
+	require.Equal(t, 0, code, ui.ErrorWriter.String())
+	code, ui = run(t, configFile, "list")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "  client-a\n", ui.OutputWriter.String())
+}
+
+func TestContextCommandErrors(t *testing.T) {
+	t.Setenv(appconfig.ContextEnv, "")
+	configFile := filepath.Join(t.TempDir(), "config.toml")
+
+	tests := []struct {
+		args    []string
+		wantErr string
+	}{
+		{nil, "Usage: aws-sso-config context"},
+		{[]string{"rename"}, "unknown subcommand: rename"},
+		{[]string{"use"}, "usage: aws-sso-config context use"},
+		{[]string{"use", "--none", "work"}, "usage: aws-sso-config context use"},
+		{[]string{"use", "missing"}, `unknown context "missing"`},
+		{[]string{"create", "work"}, "usage: aws-sso-config context create"},
+		{[]string{"create", "work", "sso.region"}, `expected key=value, got "sso.region"`},
+		{[]string{"create", "work", "sso.region=narnia"}, "is not an AWS region"},
+		{[]string{"delete", "missing"}, `unknown context "missing"`},
+	}
+
+	for _, tt := range tests {
+		code, ui := run(t, configFile, tt.args...)
+		assert.Equal(t, 1, code, tt.args)
+		assert.Contains(t, ui.ErrorWriter.String(), tt.wantErr, tt.args)
+	}
+}
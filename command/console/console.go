@@ -0,0 +1,157 @@
+package console
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/mitchellh/cli"
+	"github.com/spf13/pflag"
+
+	awsprovider "github.com/blairham/aws-sso-config/providers/aws"
+	appconfig "github.com/blairham/aws-sso-config/providers/config"
+)
+
+type cmd struct {
+	UI    cli.Ui
+	ctx   context.Context
+	flags *pflag.FlagSet
+	help  string
+
+	configFile string
+	profile    string
+	account    string
+	role       string
+	region     string
+	service    string
+	print      bool
+	noBrowser  bool
+	qrCode     bool
+
+	// Dependencies for testing
+	newFetcher   func(aws.Config, awsprovider.LoginOptions) *awsprovider.CredentialFetcher
+	newProvider  func(aws.Config, awsprovider.LoginOptions) *awsprovider.AWSProvider
+	signin       *awsprovider.ConsoleSignin
+	configLoader func(context.Context) (aws.Config, error)
+}
+
+func New(ctx context.Context, ui cli.Ui) *cmd {
+	return NewWithDependencies(
+		ctx,
+		ui,
+		awsprovider.NewCredentialFetcher,
+		awsprovider.NewAWSProvider,
+		awsprovider.NewConsoleSignin(),
+		awsprovider.LoadDefaultConfig,
+	)
+}
+
+// NewWithDependencies creates a new command with injected dependencies for testing
+func NewWithDependencies(
+	ctx context.Context,
+	ui cli.Ui,
+	newFetcher func(aws.Config, awsprovider.LoginOptions) *awsprovider.CredentialFetcher,
+	newProvider func(aws.Config, awsprovider.LoginOptions) *awsprovider.AWSProvider,
+	signin *awsprovider.ConsoleSignin,
+	configLoader func(context.Context) (aws.Config, error),
+) *cmd {
+	c := &cmd{UI: ui, ctx: ctx}
+	c.Init()
+	c.newFetcher = newFetcher
+	c.newProvider = newProvider
+	c.signin = signin
+	c.configLoader = configLoader
+	return c
+}
+
+func (c *cmd) Init() {
+	c.flags = pflag.NewFlagSet("console", pflag.ContinueOnError)
+	c.flags.StringVarP(&c.configFile, "config", "c", "", "Path to configuration file")
+	c.flags.StringVarP(&c.profile, "profile", "p", "", "SSO profile in the AWS config file")
+	c.flags.StringVar(&c.account, "account", "", "Account ID, instead of --profile")
+	c.flags.StringVar(&c.role, "role", "", "Role name, used with --account (defaults to sso.role)")
+	c.flags.StringVar(&c.region, "region", "", "Console region, overrides the profile's region")
+	c.flags.StringVarP(&c.service, "service", "s", "", "Console service to open, e.g. s3 or ec2 (defaults to the console home page)")
+	c.flags.BoolVar(&c.print, "print", false, "Print the sign-in URL instead of opening it")
+	c.flags.BoolVar(&c.noBrowser, "no-browser", false, "Print the login code and sign-in URL instead of opening a browser (auto-detected when unset)")
+	c.flags.BoolVar(&c.qrCode, "qr", false, "Also show the login URL as a terminal QR code")
+
+	c.help = help + "\n" + c.flags.FlagUsages()
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	appCfg, err := appconfig.Load(c.configFile)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Configuration error: %v", err))
+		return 1
+	}
+
+	target, err := awsprovider.ResolveTarget(appCfg, c.profile, c.account, c.role)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+	if c.region != "" {
+		target.Region = c.region
+	}
+
+	cfg, err := c.configLoader(c.ctx)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	opts := c.loginOptions()
+	creds, err := c.newFetcher(cfg, opts).Fetch(c.ctx, target, appCfg)
+	if err != nil {
+		c.UI.Error(err.Error())
+		if hint := awsprovider.ErrorHint(err); hint != "" {
+			c.UI.Error(hint)
+		}
+		return awsprovider.ExitCode(err)
+	}
+
+	signinURL, err := c.signin.URL(c.ctx, creds, c.service, target.Region)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if c.print || opts.NoBrowser {
+		c.UI.Output(signinURL)
+		return 0
+	}
+
+	if err := c.newProvider(cfg, opts).BrowserOpener(signinURL); err != nil {
+		c.UI.Warn(fmt.Sprintf("Failed to open browser automatically: %v", err))
+		c.UI.Output(signinURL)
+		return 0
+	}
+
+	c.UI.Info(fmt.Sprintf("Opened the AWS console for %s in the browser", target.AccountID+"/"+target.RoleName))
+	return 0
+}
+
+// loginOptions keeps login prompts off stdout, so that --print output is only the URL
+func (c *cmd) loginOptions() awsprovider.LoginOptions {
+	opts := awsprovider.DefaultLoginOptions()
+	if c.flags.Changed("no-browser") {
+		opts.NoBrowser = c.noBrowser
+	}
+	opts.QRCode = c.qrCode
+	opts.Out = os.Stderr
+	return opts
+}
+
+func (c *cmd) Help() string {
+	return c.help
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
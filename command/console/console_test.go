@@ -0,0 +1,105 @@
+package console
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blairham/aws-sso-config/internal/testutil"
+	awsprovider "github.com/blairham/aws-sso-config/providers/aws"
+)
+
+// newTestCmd returns a command whose federation endpoint is a test server and whose
+// browser opener records the URL it was given
+func newTestCmd(t *testing.T, openErr error) (*cmd, *cli.MockUi, *string) {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"SigninToken":"signin-token"}`))
+	}))
+	t.Cleanup(ts.Close)
+
+	opened := new(string)
+	newProvider := func(aws.Config, awsprovider.LoginOptions) *awsprovider.AWSProvider {
+		return &awsprovider.AWSProvider{BrowserOpener: func(u string) error {
+			*opened = u
+			return openErr
+		}}
+	}
+
+	ui := cli.NewMockUi()
+	signin := &awsprovider.ConsoleSignin{Client: ts.Client(), FederationURL: ts.URL}
+	return NewWithDependencies(context.Background(), ui, testutil.StubFetcher, newProvider, signin, testutil.ConfigLoader), ui, opened
+}
+
+func destination(t *testing.T, signinURL string) string {
+	t.Helper()
+	parsed, err := url.Parse(strings.TrimSpace(signinURL))
+	require.NoError(t, err)
+	return parsed.Query().Get("Destination")
+}
+
+func TestConsoleOpensBrowser(t *testing.T) {
+	c, ui, opened := newTestCmd(t, nil)
+
+	exitCode := c.Run([]string{"--config", testutil.WriteConfigs(t), "--profile", "dev", "--no-browser=false", "--service", "s3"})
+
+	assert.Equal(t, 0, exitCode, ui.ErrorWriter.String())
+	assert.Equal(t, "https://console.aws.amazon.com/s3/home?region=eu-west-1", destination(t, *opened))
+	assert.Contains(t, ui.OutputWriter.String(), "Opened the AWS console for 123456789012/Developer")
+	assert.NotContains(t, ui.OutputWriter.String(), "signin-token")
+}
+
+func TestConsolePrint(t *testing.T) {
+	c, ui, opened := newTestCmd(t, nil)
+
+	exitCode := c.Run([]string{"--config", testutil.WriteConfigs(t), "--profile", "dev", "--region", "us-west-2", "--print"})
+
+	assert.Equal(t, 0, exitCode, ui.ErrorWriter.String())
+	assert.Empty(t, *opened)
+	assert.Equal(t, "https://console.aws.amazon.com/console/home?region=us-west-2", destination(t, ui.OutputWriter.String()))
+}
+
+func TestConsolePrintsWhenBrowserFails(t *testing.T) {
+	c, ui, _ := newTestCmd(t, errors.New("no browser"))
+
+	exitCode := c.Run([]string{"--config", testutil.WriteConfigs(t), "--profile", "dev", "--no-browser=false"})
+
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, ui.ErrorWriter.String(), "Failed to open browser automatically")
+	assert.Contains(t, ui.OutputWriter.String(), "SigninToken=signin-token")
+}
+
+func TestConsoleErrors(t *testing.T) {
+	t.Run("requires a profile or account", func(t *testing.T) {
+		c, ui, _ := newTestCmd(t, nil)
+
+		assert.Equal(t, 1, c.Run([]string{"--config", testutil.WriteConfigs(t)}))
+		assert.Contains(t, ui.ErrorWriter.String(), "either --profile or --account is required")
+	})
+
+	t.Run("federation errors", func(t *testing.T) {
+		c, ui, _ := newTestCmd(t, nil)
+		c.signin.FederationURL = "http://127.0.0.1:0"
+
+		assert.Equal(t, 1, c.Run([]string{"--config", testutil.WriteConfigs(t), "--profile", "dev", "--print"}))
+		assert.Contains(t, ui.ErrorWriter.String(), "failed to get console sign-in token")
+	})
+}
+
+func TestConsoleHelp(t *testing.T) {
+	c := New(context.Background(), cli.NewMockUi())
+
+	assert.Contains(t, c.Help(), "Usage: aws-sso-config console")
+	assert.Contains(t, c.Help(), "--service")
+	assert.Contains(t, c.Help(), "--print")
+	assert.Equal(t, synopsis, c.Synopsis())
+}
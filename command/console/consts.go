@@ -0,0 +1,25 @@
+package console
+
+const synopsis = "Open the AWS console for a profile"
+const help = `
+Usage: aws-sso-config console [options]
+
+  Open the AWS console signed in to an SSO role, without going through the
+  SSO portal. The role is read from an SSO profile in the AWS config file
+  with --profile, or given directly with --account and --role.
+
+  Role credentials are exchanged for a federation sign-in URL, which is
+  opened in the browser or printed with --print. The URL is valid for 15
+  minutes and grants access to the console, treat it like a password.
+
+Examples:
+
+  # Open the console home page for a profile
+  aws-sso-config console --profile my-account
+
+  # Open S3 in a specific region
+  aws-sso-config console --profile my-account --service s3 --region eu-west-1
+
+  # Print the URL, e.g. to open it in another browser profile
+  aws-sso-config console --account 123456789012 --role ReadOnly --print
+`
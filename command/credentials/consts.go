@@ -0,0 +1,30 @@
+package credentials
+
+const synopsis = "Print short-lived role credentials for a profile"
+const help = `
+Usage: aws-sso-config credentials [options]
+
+  Print short-lived credentials for an SSO role, logging in or refreshing
+  the SSO token when needed. The role is read from an SSO profile in the
+  AWS config file with --profile, or given directly with --account and
+  --role.
+
+  Formats:
+    process   credential_process JSON for the AWS CLI and SDKs (default)
+    env       shell export lines, for eval
+    dotenv    KEY=value lines for .env files
+
+  Login prompts are written to stderr so that the output can be consumed
+  directly.
+
+Examples:
+
+  # Use as a credential_process in ~/.aws/config
+  credential_process = aws-sso-config credentials --profile my-account
+
+  # Export credentials into the current shell
+  eval "$(aws-sso-config credentials --profile my-account --format env)"
+
+  # Write a .env file for an account and role
+  aws-sso-config credentials --account 123456789012 --role ReadOnly --format dotenv > .env
+`
@@ -0,0 +1,185 @@
+package credentials
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/mitchellh/cli"
+	"github.com/spf13/pflag"
+
+	awsprovider "github.com/blairham/aws-sso-config/providers/aws"
+	appconfig "github.com/blairham/aws-sso-config/providers/config"
+)
+
+const (
+	formatProcess = "process"
+	formatEnv     = "env"
+	formatDotenv  = "dotenv"
+)
+
+// processOutput is the credential_process output format, see
+// https://docs.aws.amazon.com/cli/latest/userguide/cli-configure-sourcing-external.html
+type processOutput struct {
+	Version         int    `json:"Version"`
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string `json:"SecretAccessKey"`
+	SessionToken    string `json:"SessionToken"`
+	Expiration      string `json:"Expiration"`
+}
+
+type cmd struct {
+	UI    cli.Ui
+	ctx   context.Context
+	flags *pflag.FlagSet
+	help  string
+
+	configFile string
+	profile    string
+	account    string
+	role       string
+	region     string
+	format     string
+	noBrowser  bool
+	qrCode     bool
+
+	// Dependencies for testing
+	newFetcher   func(aws.Config, awsprovider.LoginOptions) *awsprovider.CredentialFetcher
+	configLoader func(context.Context) (aws.Config, error)
+}
+
+func New(ctx context.Context, ui cli.Ui) *cmd {
+	return NewWithDependencies(ctx, ui, awsprovider.NewCredentialFetcher, awsprovider.LoadDefaultConfig)
+}
+
+// NewWithDependencies creates a new command with injected dependencies for testing
+func NewWithDependencies(
+	ctx context.Context,
+	ui cli.Ui,
+	newFetcher func(aws.Config, awsprovider.LoginOptions) *awsprovider.CredentialFetcher,
+	configLoader func(context.Context) (aws.Config, error),
+) *cmd {
+	c := &cmd{UI: ui, ctx: ctx}
+	c.Init()
+	c.newFetcher = newFetcher
+	c.configLoader = configLoader
+	return c
+}
+
+func (c *cmd) Init() {
+	c.flags = pflag.NewFlagSet("credentials", pflag.ContinueOnError)
+	c.flags.StringVarP(&c.configFile, "config", "c", "", "Path to configuration file")
+	c.flags.StringVarP(&c.profile, "profile", "p", "", "SSO profile in the AWS config file")
+	c.flags.StringVar(&c.account, "account", "", "Account ID, instead of --profile")
+	c.flags.StringVar(&c.role, "role", "", "Role name, used with --account (defaults to sso.role)")
+	c.flags.StringVar(&c.region, "region", "", "Region to export, overrides the profile's region")
+	c.flags.StringVarP(&c.format, "format", "f", formatProcess, "Output format: process, env or dotenv")
+	c.flags.BoolVar(&c.noBrowser, "no-browser", false, "Print the login code instead of opening a browser (auto-detected when unset)")
+	c.flags.BoolVar(&c.qrCode, "qr", false, "Also show the login URL as a terminal QR code")
+
+	c.help = help + "\n" + c.flags.FlagUsages()
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	switch c.format {
+	case formatProcess, formatEnv, formatDotenv:
+	default:
+		c.UI.Error(fmt.Sprintf("Unsupported format: %s (expected process, env or dotenv)", c.format))
+		return 1
+	}
+
+	appCfg, err := appconfig.Load(c.configFile)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Configuration error: %v", err))
+		return 1
+	}
+
+	target, err := awsprovider.ResolveTarget(appCfg, c.profile, c.account, c.role)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	cfg, err := c.configLoader(c.ctx)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	creds, err := c.newFetcher(cfg, c.loginOptions()).Fetch(c.ctx, target, appCfg)
+	if err != nil {
+		c.UI.Error(err.Error())
+		if hint := awsprovider.ErrorHint(err); hint != "" {
+			c.UI.Error(hint)
+		}
+		return awsprovider.ExitCode(err)
+	}
+
+	region := target.Region
+	if c.region != "" {
+		region = c.region
+	}
+
+	return c.print(creds, region)
+}
+
+// loginOptions keeps login prompts off stdout, which carries the credentials
+func (c *cmd) loginOptions() awsprovider.LoginOptions {
+	opts := awsprovider.DefaultLoginOptions()
+	if c.flags.Changed("no-browser") {
+		opts.NoBrowser = c.noBrowser
+	}
+	opts.QRCode = c.qrCode
+	opts.Out = os.Stderr
+	return opts
+}
+
+func (c *cmd) print(creds *awsprovider.RoleCredentials, region string) int {
+	switch c.format {
+	case formatEnv:
+		for _, kv := range creds.Environ(region) {
+			key, value, _ := strings.Cut(kv, "=")
+			c.UI.Output(fmt.Sprintf("export %s=%s", key, shellQuote(value)))
+		}
+	case formatDotenv:
+		for _, kv := range creds.Environ(region) {
+			c.UI.Output(kv)
+		}
+	default:
+		data, err := json.MarshalIndent(processOutput{
+			Version:         1,
+			AccessKeyID:     creds.AccessKeyID,
+			SecretAccessKey: creds.SecretAccessKey,
+			SessionToken:    creds.SessionToken,
+			Expiration:      creds.Expiration.Format(time.RFC3339),
+		}, "", "  ")
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error encoding credentials: %v", err))
+			return 1
+		}
+		c.UI.Output(string(data))
+	}
+
+	return 0
+}
+
+// shellQuote wraps a value in single quotes for POSIX shells
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+func (c *cmd) Help() string {
+	return c.help
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
@@ -0,0 +1,134 @@
+package credentials
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blairham/aws-sso-config/internal/testutil"
+	awsprovider "github.com/blairham/aws-sso-config/providers/aws"
+	appconfig "github.com/blairham/aws-sso-config/providers/config"
+)
+
+// fakeFetcher records the target passed to Fetch and returns fixed credentials or an error
+type fakeFetcher struct {
+	target *awsprovider.RoleTarget
+	opts   awsprovider.LoginOptions
+	err    error
+}
+
+func (f *fakeFetcher) factory(_ aws.Config, opts awsprovider.LoginOptions) *awsprovider.CredentialFetcher {
+	f.opts = opts
+	return &awsprovider.CredentialFetcher{
+		Token: func(context.Context, *appconfig.Config) (awsprovider.Token, error) {
+			if f.err != nil {
+				return awsprovider.Token{}, f.err
+			}
+			return awsprovider.Token{AccessToken: "sso-token"}, nil
+		},
+		Client: func(string) awsprovider.RoleCredentialsClient { return nil },
+	}
+}
+
+func testCredentials() *awsprovider.RoleCredentials {
+	return &awsprovider.RoleCredentials{
+		AccessKeyID:     "AKIAEXAMPLE",
+		SecretAccessKey: "se'cret",
+		SessionToken:    "session",
+		Expiration:      time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestPrintFormats(t *testing.T) {
+	t.Run("process", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		c := New(context.Background(), ui)
+
+		assert.Equal(t, 0, c.print(testCredentials(), "eu-west-1"))
+
+		var out processOutput
+		require.NoError(t, json.Unmarshal([]byte(ui.OutputWriter.String()), &out))
+		assert.Equal(t, processOutput{
+			Version:         1,
+			AccessKeyID:     "AKIAEXAMPLE",
+			SecretAccessKey: "se'cret",
+			SessionToken:    "session",
+			Expiration:      "2025-01-01T12:00:00Z",
+		}, out)
+	})
+
+	t.Run("env", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		c := New(context.Background(), ui)
+		require.NoError(t, c.flags.Parse([]string{"--format", "env"}))
+
+		assert.Equal(t, 0, c.print(testCredentials(), "eu-west-1"))
+
+		out := ui.OutputWriter.String()
+		assert.Contains(t, out, "export AWS_ACCESS_KEY_ID='AKIAEXAMPLE'\n")
+		assert.Contains(t, out, `export AWS_SECRET_ACCESS_KEY='se'\''cret'`)
+		assert.Contains(t, out, "export AWS_REGION='eu-west-1'\n")
+	})
+
+	t.Run("dotenv", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		c := New(context.Background(), ui)
+		require.NoError(t, c.flags.Parse([]string{"-f", "dotenv"}))
+
+		assert.Equal(t, 0, c.print(testCredentials(), ""))
+
+		out := ui.OutputWriter.String()
+		assert.Contains(t, out, "AWS_SESSION_TOKEN=session\n")
+		assert.NotContains(t, out, "export")
+		assert.NotContains(t, out, "AWS_REGION")
+	})
+}
+
+func TestCredentialsErrors(t *testing.T) {
+	t.Run("unsupported format", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		c := New(context.Background(), ui)
+
+		assert.Equal(t, 1, c.Run([]string{"--profile", "dev", "--format", "xml"}))
+		assert.Contains(t, ui.ErrorWriter.String(), "Unsupported format: xml")
+	})
+
+	t.Run("unknown profile", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		fetcher := &fakeFetcher{}
+		c := NewWithDependencies(context.Background(), ui, fetcher.factory, testutil.ConfigLoader)
+
+		assert.Equal(t, 1, c.Run([]string{"--config", testutil.WriteConfigs(t), "--profile", "prod"}))
+		assert.Contains(t, ui.ErrorWriter.String(), "profile prod not found")
+	})
+
+	t.Run("login errors map to exit codes and keep prompts off stdout", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		fetcher := &fakeFetcher{err: awsprovider.ErrAuthorizationDenied}
+		c := NewWithDependencies(context.Background(), ui, fetcher.factory, testutil.ConfigLoader)
+
+		exitCode := c.Run([]string{"--config", testutil.WriteConfigs(t), "--profile", "dev", "--no-browser"})
+
+		assert.Equal(t, awsprovider.ExitCodeAuthorizationDenied, exitCode)
+		assert.Contains(t, ui.ErrorWriter.String(), awsprovider.ErrorHint(awsprovider.ErrAuthorizationDenied))
+		assert.Empty(t, ui.OutputWriter.String())
+		assert.Equal(t, os.Stderr, fetcher.opts.Out)
+		assert.True(t, fetcher.opts.NoBrowser)
+	})
+}
+
+func TestCredentialsHelp(t *testing.T) {
+	c := New(context.Background(), cli.NewMockUi())
+
+	assert.Contains(t, c.Help(), "Usage: aws-sso-config credentials")
+	assert.Contains(t, c.Help(), "--profile")
+	assert.Contains(t, c.Help(), "credential_process")
+	assert.Equal(t, synopsis, c.Synopsis())
+}
@@ -0,0 +1,29 @@
+package exec
+
+const synopsis = "Run a command with SSO role credentials in its environment"
+const help = `
+Usage: aws-sso-config exec [options] -- <command> [args...]
+
+  Run a command with short-lived credentials for an SSO role. The role is
+  read from an SSO profile in the AWS config file with --profile, or given
+  directly with --account and --role.
+
+  The command gets AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY,
+  AWS_SESSION_TOKEN, AWS_REGION and AWS_PROFILE in its environment.
+  Interrupt and termination signals are forwarded to it, and its exit code
+  is returned.
+
+  --context must come before the command; after it, --context is passed
+  to the command, e.g. kubectl's.
+
+  Commands cannot be nested: running exec from inside another exec is
+  refused, as the inner call would use the outer call's credentials.
+
+Examples:
+
+  # Plan with the credentials of a profile
+  aws-sso-config exec --profile my-account -- terraform plan
+
+  # Run a shell for an account and role
+  aws-sso-config exec --account 123456789012 --role ReadOnly -- $SHELL
+`
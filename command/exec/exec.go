@@ -0,0 +1,255 @@
+package exec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	osexec "os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/mitchellh/cli"
+	"github.com/spf13/pflag"
+
+	awsprovider "github.com/blairham/aws-sso-config/providers/aws"
+	appconfig "github.com/blairham/aws-sso-config/providers/config"
+)
+
+// EnvMarker is set in the child's environment to detect nested exec calls
+const EnvMarker = "AWS_SSO_CONFIG_EXEC"
+
+// clearedEnv are the variables removed from the parent environment so that
+// they cannot take precedence over, or mix with, the injected credentials
+var clearedEnv = []string{
+	"AWS_ACCESS_KEY_ID",
+	"AWS_SECRET_ACCESS_KEY",
+	"AWS_SESSION_TOKEN",
+	"AWS_SECURITY_TOKEN",
+	"AWS_CREDENTIAL_EXPIRATION",
+	"AWS_REGION",
+	"AWS_DEFAULT_REGION",
+	"AWS_PROFILE",
+}
+
+type cmd struct {
+	UI    cli.Ui
+	ctx   context.Context
+	flags *pflag.FlagSet
+	help  string
+
+	configFile  string
+	contextName string
+	profile     string
+	account     string
+	role        string
+	region      string
+	noBrowser   bool
+	qrCode      bool
+
+	// Dependencies for testing
+	newFetcher   func(aws.Config, awsprovider.LoginOptions) *awsprovider.CredentialFetcher
+	configLoader func(context.Context) (aws.Config, error)
+}
+
+func New(ctx context.Context, ui cli.Ui) *cmd {
+	return NewWithDependencies(ctx, ui, awsprovider.NewCredentialFetcher, awsprovider.LoadDefaultConfig)
+}
+
+// NewWithDependencies creates a new command with injected dependencies for testing
+func NewWithDependencies(
+	ctx context.Context,
+	ui cli.Ui,
+	newFetcher func(aws.Config, awsprovider.LoginOptions) *awsprovider.CredentialFetcher,
+	configLoader func(context.Context) (aws.Config, error),
+) *cmd {
+	c := &cmd{UI: ui, ctx: ctx}
+	c.Init()
+	c.newFetcher = newFetcher
+	c.configLoader = configLoader
+	return c
+}
+
+func (c *cmd) Init() {
+	c.flags = pflag.NewFlagSet("exec", pflag.ContinueOnError)
+	// Flags after the command name belong to the command
+	c.flags.SetInterspersed(false)
+	c.flags.StringVarP(&c.configFile, "config", "c", "", "Path to configuration file")
+	// The global --context is left to exec, so that the command's own is not taken
+	c.flags.StringVar(&c.contextName, "context", "", "Configuration context to use")
+	c.flags.StringVarP(&c.profile, "profile", "p", "", "SSO profile in the AWS config file")
+	c.flags.StringVar(&c.account, "account", "", "Account ID, instead of --profile")
+	c.flags.StringVar(&c.role, "role", "", "Role name, used with --account (defaults to sso.role)")
+	c.flags.StringVar(&c.region, "region", "", "Region for the command, overrides the profile's region")
+	c.flags.BoolVar(&c.noBrowser, "no-browser", false, "Print the login code instead of opening a browser (auto-detected when unset)")
+	c.flags.BoolVar(&c.qrCode, "qr", false, "Also show the login URL as a terminal QR code")
+
+	c.help = help + "\n" + c.flags.FlagUsages()
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	command := c.flags.Args()
+	if len(command) == 0 {
+		c.UI.Error("A command to run is required, e.g. aws-sso-config exec --profile my-account -- aws sts get-caller-identity")
+		return 1
+	}
+
+	if outer := os.Getenv(EnvMarker); outer != "" {
+		c.UI.Error(fmt.Sprintf("Already running inside aws-sso-config exec for %s, nested exec calls are not supported", outer))
+		return 1
+	}
+
+	// Selected through the environment like the global flag, which also carries it
+	// to the command
+	if c.contextName != "" {
+		if err := os.Setenv(appconfig.ContextEnv, c.contextName); err != nil {
+			c.UI.Error(fmt.Sprintf("Error selecting context: %v", err))
+			return 1
+		}
+	}
+
+	appCfg, err := appconfig.Load(c.configFile)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Configuration error: %v", err))
+		return 1
+	}
+
+	target, err := awsprovider.ResolveTarget(appCfg, c.profile, c.account, c.role)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+	if c.region != "" {
+		target.Region = c.region
+	}
+
+	cfg, err := c.configLoader(c.ctx)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	creds, err := c.newFetcher(cfg, c.loginOptions()).Fetch(c.ctx, target, appCfg)
+	if err != nil {
+		c.UI.Error(err.Error())
+		if hint := awsprovider.ErrorHint(err); hint != "" {
+			c.UI.Error(hint)
+		}
+		return awsprovider.ExitCode(err)
+	}
+
+	exitCode, err := run(command, childEnv(os.Environ(), target, creds))
+	if err != nil {
+		c.UI.Error(err.Error())
+	}
+	return exitCode
+}
+
+// loginOptions keeps login prompts off stdout, which belongs to the command
+func (c *cmd) loginOptions() awsprovider.LoginOptions {
+	opts := awsprovider.DefaultLoginOptions()
+	if c.flags.Changed("no-browser") {
+		opts.NoBrowser = c.noBrowser
+	}
+	opts.QRCode = c.qrCode
+	opts.Out = os.Stderr
+	return opts
+}
+
+// childEnv returns environ without existing AWS credentials, with the role credentials and the exec marker added
+func childEnv(environ []string, target *awsprovider.RoleTarget, creds *awsprovider.RoleCredentials) []string {
+	env := make([]string, 0, len(environ)+len(clearedEnv)+1)
+	for _, kv := range environ {
+		key, _, _ := strings.Cut(kv, "=")
+		if !isCleared(key) && key != EnvMarker {
+			env = append(env, kv)
+		}
+	}
+
+	env = append(env, creds.Environ(target.Region)...)
+
+	name := target.Profile
+	if name != "" {
+		env = append(env, "AWS_PROFILE="+name)
+	} else {
+		name = target.AccountID + "/" + target.RoleName
+	}
+
+	return append(env, EnvMarker+"="+name)
+}
+
+func isCleared(key string) bool {
+	for _, cleared := range clearedEnv {
+		if key == cleared {
+			return true
+		}
+	}
+	return false
+}
+
+// run starts the command, forwards signals to it until it exits and returns its exit code
+func run(command, env []string) (int, error) {
+	path, err := osexec.LookPath(command[0])
+	if err != nil {
+		return 127, fmt.Errorf("command not found: %s", command[0])
+	}
+
+	child := osexec.Command(path, command[1:]...)
+	child.Args[0] = command[0]
+	child.Env = env
+	child.Stdin = os.Stdin
+	child.Stdout = os.Stdout
+	child.Stderr = os.Stderr
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	if err := child.Start(); err != nil {
+		return 126, fmt.Errorf("failed to start %s: %w", command[0], err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- child.Wait() }()
+
+	for {
+		select {
+		case sig := <-signals:
+			// The child may already have exited, Wait reports the outcome either way
+			_ = child.Process.Signal(sig)
+		case err := <-done:
+			return exitCode(err)
+		}
+	}
+}
+
+// exitCode returns the child's exit code, or 128 plus the signal number when it was killed by a signal as shells do
+func exitCode(err error) (int, error) {
+	if err == nil {
+		return 0, nil
+	}
+
+	var exitErr *osexec.ExitError
+	if !errors.As(err, &exitErr) {
+		return 1, err
+	}
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal()), nil
+	}
+
+	return exitErr.ExitCode(), nil
+}
+
+func (c *cmd) Help() string {
+	return c.help
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
@@ -0,0 +1,141 @@
+package exec
+
+import (
+	"context"
+	"os"
+	osexec "os/exec"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blairham/aws-sso-config/internal/testutil"
+	awsprovider "github.com/blairham/aws-sso-config/providers/aws"
+	appconfig "github.com/blairham/aws-sso-config/providers/config"
+)
+
+func requireShell(t *testing.T) {
+	t.Helper()
+	if _, err := osexec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+}
+
+func TestChildEnv(t *testing.T) {
+	creds := &awsprovider.RoleCredentials{AccessKeyID: "AKIAEXAMPLE", SecretAccessKey: "secret", SessionToken: "session"}
+	environ := []string{"HOME=/home/test", "AWS_ACCESS_KEY_ID=AKIAOLD", "AWS_PROFILE=old", "AWS_SSO_CONFIG_EXEC=stale"}
+
+	t.Run("profile", func(t *testing.T) {
+		env := childEnv(environ, &awsprovider.RoleTarget{Profile: "dev", Region: "eu-west-1"}, creds)
+
+		assert.Contains(t, env, "HOME=/home/test")
+		assert.Contains(t, env, "AWS_ACCESS_KEY_ID=AKIAEXAMPLE")
+		assert.Contains(t, env, "AWS_REGION=eu-west-1")
+		assert.Contains(t, env, "AWS_PROFILE=dev")
+		assert.Contains(t, env, "AWS_SSO_CONFIG_EXEC=dev")
+		assert.NotContains(t, env, "AWS_ACCESS_KEY_ID=AKIAOLD")
+		assert.NotContains(t, env, "AWS_PROFILE=old")
+		assert.NotContains(t, env, "AWS_SSO_CONFIG_EXEC=stale")
+	})
+
+	t.Run("account and role", func(t *testing.T) {
+		env := childEnv(environ, &awsprovider.RoleTarget{AccountID: "123456789012", RoleName: "ReadOnly"}, creds)
+
+		assert.Contains(t, env, "AWS_SSO_CONFIG_EXEC=123456789012/ReadOnly")
+		for _, kv := range env {
+			assert.NotContains(t, kv, "AWS_PROFILE=")
+		}
+	})
+}
+
+func TestRunExitCodes(t *testing.T) {
+	requireShell(t)
+
+	code, err := run([]string{"sh", "-c", "exit 0"}, os.Environ())
+	require.NoError(t, err)
+	assert.Equal(t, 0, code)
+
+	code, err = run([]string{"sh", "-c", "exit 42"}, os.Environ())
+	require.NoError(t, err)
+	assert.Equal(t, 42, code)
+
+	code, err = run([]string{"sh", "-c", "kill -TERM $$"}, os.Environ())
+	require.NoError(t, err)
+	assert.Equal(t, 143, code)
+
+	code, err = run([]string{"aws-sso-config-no-such-command"}, os.Environ())
+	assert.ErrorContains(t, err, "command not found")
+	assert.Equal(t, 127, code)
+}
+
+func TestExecInjectsCredentials(t *testing.T) {
+	requireShell(t)
+	t.Setenv(EnvMarker, "")
+
+	ui := cli.NewMockUi()
+	c := NewWithDependencies(context.Background(), ui, testutil.StubFetcher, testutil.ConfigLoader)
+
+	script := `test "$AWS_ACCESS_KEY_ID" = AKIAEXAMPLE && test "$AWS_PROFILE" = dev && test "$AWS_REGION" = eu-west-1 && test "$AWS_SSO_CONFIG_EXEC" = dev && exit 7`
+	exitCode := c.Run([]string{"--config", testutil.WriteConfigs(t), "--profile", "dev", "--", "sh", "-c", script})
+
+	assert.Equal(t, 7, exitCode, ui.ErrorWriter.String())
+}
+
+func TestExecLeavesCommandContext(t *testing.T) {
+	requireShell(t)
+	t.Setenv(EnvMarker, "")
+	t.Setenv(appconfig.ContextEnv, "")
+
+	ui := cli.NewMockUi()
+	c := NewWithDependencies(context.Background(), ui, testutil.StubFetcher, testutil.ConfigLoader)
+
+	// The command's --context is its own, not a configuration context
+	script := `test "$1 $2" = "--context prod" && exit 7`
+	exitCode := c.Run([]string{"--config", testutil.WriteConfigs(t), "--profile", "dev", "sh", "-c", script, "sh", "--context", "prod"})
+
+	assert.Equal(t, 7, exitCode, ui.ErrorWriter.String())
+}
+
+func TestExecErrors(t *testing.T) {
+	t.Run("requires a command", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		c := NewWithDependencies(context.Background(), ui, testutil.StubFetcher, testutil.ConfigLoader)
+
+		assert.Equal(t, 1, c.Run([]string{"--profile", "dev"}))
+		assert.Contains(t, ui.ErrorWriter.String(), "A command to run is required")
+	})
+
+	t.Run("refuses nested calls", func(t *testing.T) {
+		t.Setenv(EnvMarker, "dev")
+		ui := cli.NewMockUi()
+		c := NewWithDependencies(context.Background(), ui, testutil.StubFetcher, testutil.ConfigLoader)
+
+		assert.Equal(t, 1, c.Run([]string{"--profile", "dev", "--", "true"}))
+		assert.Contains(t, ui.ErrorWriter.String(), "Already running inside aws-sso-config exec for dev")
+	})
+
+	t.Run("login errors map to exit codes", func(t *testing.T) {
+		t.Setenv(EnvMarker, "")
+		ui := cli.NewMockUi()
+		failing := func(aws.Config, awsprovider.LoginOptions) *awsprovider.CredentialFetcher {
+			return &awsprovider.CredentialFetcher{
+				Token: func(context.Context, *appconfig.Config) (awsprovider.Token, error) {
+					return awsprovider.Token{}, awsprovider.ErrLoginCancelled
+				},
+			}
+		}
+		c := NewWithDependencies(context.Background(), ui, failing, testutil.ConfigLoader)
+
+		assert.Equal(t, awsprovider.ExitCodeCancelled, c.Run([]string{"--config", testutil.WriteConfigs(t), "--profile", "dev", "--", "true"}))
+	})
+}
+
+func TestExecHelp(t *testing.T) {
+	c := New(context.Background(), cli.NewMockUi())
+
+	assert.Contains(t, c.Help(), "Usage: aws-sso-config exec")
+	assert.Contains(t, c.Help(), "--profile")
+	assert.Equal(t, synopsis, c.Synopsis())
+}
@@ -0,0 +1,43 @@
+package flags
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ContextFlag is the global flag that selects a configuration context for any command
+const ContextFlag = "--context"
+
+// ownContextCommands parse --context with their own flags, as their arguments end with
+// another program's, which may have a --context flag of its own
+var ownContextCommands = map[string]bool{"exec": true}
+
+// ExtractContext removes --context <name> or --context=<name> from args, wherever it
+// appears before a -- terminator, and returns the remaining args and the name, empty
+// when the flag was not given. For the commands in ownContextCommands only the flag
+// before the command name is taken.
+func ExtractContext(args []string) (rest []string, name string, err error) {
+	rest = make([]string, 0, len(args))
+	command := ""
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--" || ownContextCommands[command]:
+			return append(rest, args[i:]...), name, nil
+		case arg == ContextFlag:
+			if i+1 >= len(args) || args[i+1] == "--" {
+				return nil, "", fmt.Errorf("flag needs an argument: %s", ContextFlag)
+			}
+			i++
+			name = args[i]
+		case strings.HasPrefix(arg, ContextFlag+"="):
+			name = strings.TrimPrefix(arg, ContextFlag+"=")
+		default:
+			if command == "" && !strings.HasPrefix(arg, "-") {
+				command = arg
+			}
+			rest = append(rest, arg)
+		}
+	}
+	return rest, name, nil
+}
@@ -0,0 +1,52 @@
+package flags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractContext(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantRest []string
+		wantName string
+		wantErr  string
+	}{
+		{"absent", []string{"status"}, []string{"status"}, "", ""},
+		{"before the command", []string{"--context", "work", "status"}, []string{"status"}, "work", ""},
+		{"after the command", []string{"login", "--context=client-a", "--no-browser"}, []string{"login", "--no-browser"}, "client-a", ""},
+		{"after generate", []string{"generate", "--context", "work", "--diff"}, []string{"generate", "--diff"}, "work", ""},
+		{"last one wins", []string{"--context=a", "status", "--context", "b"}, []string{"status"}, "b", ""},
+		{
+			"left alone after --",
+			[]string{"which", "--context", "work", "--", "--context", "other"},
+			[]string{"which", "--", "--context", "other"}, "work", "",
+		},
+		{
+			"before exec only",
+			[]string{"--context", "work", "exec", "--account", "123456789012", "--role", "R", "kubectl", "--context", "prod"},
+			[]string{"exec", "--account", "123456789012", "--role", "R", "kubectl", "--context", "prod"}, "work", "",
+		},
+		{
+			"parsed by exec",
+			[]string{"exec", "--context", "work", "--", "tool"},
+			[]string{"exec", "--context", "work", "--", "tool"}, "", "",
+		},
+		{"missing value", []string{"status", "--context"}, nil, "", "flag needs an argument: --context"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rest, name, err := ExtractContext(tt.args)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantRest, rest)
+			assert.Equal(t, tt.wantName, name)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package generate
 
 import (
+	"context"
 	"os"
 	"testing"
 
@@ -68,16 +69,16 @@ type MockConfigGenerator struct {
 	mock.Mock
 }
 
-func (m *MockConfigGenerator) ListAccountsWithClient(ssoClient SSOClient, token *string) ([]types.AccountInfo, error) {
-	args := m.Called(ssoClient, token)
+func (m *MockConfigGenerator) ListAccountsWithClient(ctx context.Context, ssoClient SSOClient, token *string) ([]types.AccountInfo, error) {
+	args := m.Called(ctx, ssoClient, token)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]types.AccountInfo), args.Error(1)
 }
 
-func (m *MockConfigGenerator) GetAccountRolesWithClient(ssoClient SSOClient, token *string, accountID string) ([]types.RoleInfo, error) {
-	args := m.Called(ssoClient, token, accountID)
+func (m *MockConfigGenerator) GetAccountRolesWithClient(ctx context.Context, ssoClient SSOClient, token *string, accountID string) ([]types.RoleInfo, error) {
+	args := m.Called(ctx, ssoClient, token, accountID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -89,8 +90,8 @@ func (m *MockConfigGenerator) WriteSectionToConfig(configParser *configparser.Co
 	return args.Error(0)
 }
 
-func (m *MockConfigGenerator) GenerateConfigFile(ssoClient SSOClient, token *string, configFile string, showDiff bool, appCfg *appconfig.Config) error {
-	args := m.Called(ssoClient, token, configFile, showDiff, appCfg)
+func (m *MockConfigGenerator) GenerateConfigFile(ctx context.Context, ssoClient SSOClient, token *string, configFile string, showDiff bool, appCfg *appconfig.Config) error {
+	args := m.Called(ctx, ssoClient, token, configFile, showDiff, appCfg)
 	return args.Error(0)
 }
 
@@ -0,0 +1,156 @@
+package generate
+
+import (
+	"bytes"
+	"fmt"
+	"os/user"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/bigkevmcd/go-configparser"
+
+	appconfig "github.com/blairham/aws-sso-config/providers/config"
+)
+
+var (
+	// roleSessionNamePattern is what STS accepts as a role session name
+	roleSessionNamePattern = regexp.MustCompile(`^[\w+=,.@-]{2,64}$`)
+	// invalidSessionNameChars are replaced in the user name, e.g. the backslash in DOMAIN\user
+	invalidSessionNameChars = regexp.MustCompile(`[^\w+=,.@-]`)
+)
+
+// chainTemplateData is available in chain templates. AccountId is spelled as in the AWS APIs.
+type chainTemplateData struct {
+	AccountId     string
+	AccountName   string
+	SourceProfile string
+	Profile       string
+	User          string
+}
+
+// accountProfile is a profile generate wrote for an account
+type accountProfile struct {
+	name        string
+	accountID   string
+	accountName string
+}
+
+// currentUser returns the local user name, usable in a role session name
+func currentUser() string {
+	name := "aws-sso-config"
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		name = u.Username
+	}
+	return invalidSessionNameChars.ReplaceAllString(name, "-")
+}
+
+// chainProfileName returns the profile a chain writes for an account profile
+func chainProfileName(accountProfile, chainName string) string {
+	return accountProfile + "-" + chainName
+}
+
+// addChainedProfiles writes an assume-role profile per account for each chain. Chains are
+// written in source order, so that every source_profile exists when it is referenced.
+func addChainedProfiles(awsConfig *configparser.ConfigParser, profiles []accountProfile, appCfg *appconfig.Config) error {
+	chains, err := appCfg.Generate.OrderedChains(appCfg.SSORole())
+	if err != nil {
+		return err
+	}
+	if len(chains) == 0 {
+		return nil
+	}
+
+	chainNames := make(map[string]bool, len(chains))
+	for _, chain := range chains {
+		chainNames[chain.Name] = true
+	}
+
+	userName := currentUser()
+	for _, chain := range chains {
+		roleARN, err := template.New("role_arn").Parse(chain.RoleARNTemplate)
+		if err != nil {
+			return fmt.Errorf("generate chain %s: invalid role_arn_template: %w", chain.Name, err)
+		}
+		sessionName, err := template.New("role_session_name").Parse(chain.RoleSessionNameTemplate)
+		if err != nil {
+			return fmt.Errorf("generate chain %s: invalid role_session_name_template: %w", chain.Name, err)
+		}
+
+		for _, p := range profiles {
+			source := p.name
+			if chainNames[chain.SourceRole] {
+				source = chainProfileName(p.name, chain.SourceRole)
+			}
+			if !awsConfig.HasSection("profile " + source) {
+				return fmt.Errorf("generate chain %s: source profile %s does not exist", chain.Name, source)
+			}
+
+			data := chainTemplateData{
+				AccountId:     p.accountID,
+				AccountName:   p.accountName,
+				SourceProfile: source,
+				Profile:       chainProfileName(p.name, chain.Name),
+				User:          userName,
+			}
+			if err := addChainedProfile(awsConfig, data, roleARN, sessionName, chain, appCfg.DefaultRegion()); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func addChainedProfile(
+	awsConfig *configparser.ConfigParser,
+	data chainTemplateData,
+	roleARN, sessionName *template.Template,
+	chain appconfig.ChainConfig,
+	region string,
+) error {
+	arn, err := render(roleARN, data)
+	if err != nil {
+		return fmt.Errorf("generate chain %s: role_arn_template for %s: %w", chain.Name, data.AccountName, err)
+	}
+	if !strings.HasPrefix(arn, "arn:") {
+		return fmt.Errorf("generate chain %s: role_arn_template for %s rendered %q, which is not an ARN", chain.Name, data.AccountName, arn)
+	}
+
+	session, err := render(sessionName, data)
+	if err != nil {
+		return fmt.Errorf("generate chain %s: role_session_name_template for %s: %w", chain.Name, data.AccountName, err)
+	}
+	if !roleSessionNamePattern.MatchString(session) {
+		return fmt.Errorf("generate chain %s: role session name %q for %s must be 2 to 64 letters, digits or =,.@_+-", chain.Name, session, data.AccountName)
+	}
+
+	section := "profile " + data.Profile
+	if !awsConfig.HasSection(section) {
+		fmt.Printf("Adding profile %v\n", data.Profile)
+		if err := awsConfig.AddSection(section); err != nil {
+			return err
+		}
+	}
+
+	for _, option := range [][2]string{
+		{"source_profile", data.SourceProfile},
+		{"role_arn", arn},
+		{"role_session_name", session},
+		{"region", region},
+	} {
+		if err := awsConfig.Set(section, option[0], option[1]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func render(tmpl *template.Template, data chainTemplateData) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Option("missingkey=error").Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
@@ -0,0 +1,164 @@
+package generate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sso"
+	"github.com/aws/aws-sdk-go-v2/service/sso/types"
+	"github.com/bigkevmcd/go-configparser"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	appconfig "github.com/blairham/aws-sso-config/providers/config"
+)
+
+func chainTestConfig(chains ...appconfig.ChainConfig) *appconfig.Config {
+	appCfg := appconfig.Default()
+	appCfg.SSO.Role = "AdministratorAccess"
+	appCfg.AWS.DefaultRegion = "eu-west-1"
+	appCfg.Generate.Chains = chains
+	appCfg.SetDefaults()
+	return appCfg
+}
+
+func chainTestParser(t *testing.T, profiles ...string) *configparser.ConfigParser {
+	t.Helper()
+	awsConfig := configparser.New()
+	for _, profile := range profiles {
+		require.NoError(t, awsConfig.AddSection("profile "+profile))
+	}
+	return awsConfig
+}
+
+func get(t *testing.T, awsConfig *configparser.ConfigParser, section, option string) string {
+	t.Helper()
+	value, err := awsConfig.Get(section, option)
+	require.NoError(t, err)
+	return value
+}
+
+func TestAddChainedProfiles(t *testing.T) {
+	profiles := []accountProfile{
+		{name: "dev", accountID: "111111111111", accountName: "dev"},
+		{name: "prod", accountID: "222222222222", accountName: "prod"},
+	}
+	appCfg := chainTestConfig(
+		// Declared before its source to check that chains are written in source order
+		appconfig.ChainConfig{
+			Name:                    "break-glass",
+			SourceRole:              "deploy",
+			RoleARNTemplate:         "arn:aws:iam::{{.AccountId}}:role/BreakGlass",
+			RoleSessionNameTemplate: "{{.AccountName}}-break-glass",
+		},
+		appconfig.ChainConfig{
+			Name:            "deploy",
+			SourceRole:      "AdministratorAccess",
+			RoleARNTemplate: "arn:aws:iam::{{.AccountId}}:role/Deploy",
+		},
+	)
+	awsConfig := chainTestParser(t, "dev", "prod")
+
+	require.NoError(t, addChainedProfiles(awsConfig, profiles, appCfg))
+
+	assert.Equal(t, "dev", get(t, awsConfig, "profile dev-deploy", "source_profile"))
+	assert.Equal(t, "arn:aws:iam::111111111111:role/Deploy", get(t, awsConfig, "profile dev-deploy", "role_arn"))
+	assert.Equal(t, currentUser(), get(t, awsConfig, "profile dev-deploy", "role_session_name"))
+	assert.Equal(t, "eu-west-1", get(t, awsConfig, "profile dev-deploy", "region"))
+
+	assert.Equal(t, "prod-deploy", get(t, awsConfig, "profile prod-break-glass", "source_profile"))
+	assert.Equal(t, "arn:aws:iam::222222222222:role/BreakGlass", get(t, awsConfig, "profile prod-break-glass", "role_arn"))
+	assert.Equal(t, "prod-break-glass", get(t, awsConfig, "profile prod-break-glass", "role_session_name"))
+}
+
+func TestAddChainedProfilesErrors(t *testing.T) {
+	profiles := []accountProfile{{name: "dev", accountID: "111111111111", accountName: "dev"}}
+	deploy := appconfig.ChainConfig{Name: "deploy", SourceRole: "AdministratorAccess", RoleARNTemplate: "arn:aws:iam::{{.AccountId}}:role/Deploy"}
+
+	t.Run("source profile does not exist", func(t *testing.T) {
+		err := addChainedProfiles(chainTestParser(t), profiles, chainTestConfig(deploy))
+		assert.ErrorContains(t, err, "source profile dev does not exist")
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		a := appconfig.ChainConfig{Name: "a", SourceRole: "b", RoleARNTemplate: "arn:aws:iam::{{.AccountId}}:role/A"}
+		b := appconfig.ChainConfig{Name: "b", SourceRole: "a", RoleARNTemplate: "arn:aws:iam::{{.AccountId}}:role/B"}
+		err := addChainedProfiles(chainTestParser(t, "dev"), profiles, chainTestConfig(a, b))
+		assert.ErrorContains(t, err, "cycle")
+	})
+
+	t.Run("unknown template field", func(t *testing.T) {
+		chain := deploy
+		chain.RoleARNTemplate = "arn:aws:iam::{{.AccountID}}:role/Deploy"
+		err := addChainedProfiles(chainTestParser(t, "dev"), profiles, chainTestConfig(chain))
+		assert.ErrorContains(t, err, "role_arn_template for dev")
+	})
+
+	t.Run("rendered role ARN is not an ARN", func(t *testing.T) {
+		chain := deploy
+		chain.RoleARNTemplate = "Deploy"
+		err := addChainedProfiles(chainTestParser(t, "dev"), profiles, chainTestConfig(chain))
+		assert.ErrorContains(t, err, "not an ARN")
+	})
+
+	t.Run("invalid role session name", func(t *testing.T) {
+		chain := deploy
+		chain.RoleSessionNameTemplate = "{{.AccountName}} session"
+		err := addChainedProfiles(chainTestParser(t, "dev"), profiles, chainTestConfig(chain))
+		assert.ErrorContains(t, err, `role session name "dev session"`)
+	})
+}
+
+func TestRunWithChains(t *testing.T) {
+	tmpDir := t.TempDir()
+	awsConfigFile := filepath.Join(tmpDir, "aws-config")
+	require.NoError(t, os.WriteFile(awsConfigFile, []byte("[default]\nregion = us-east-1\n"), 0600))
+
+	appConfigFile := filepath.Join(tmpDir, "app-config.toml")
+	configContent := `[sso]
+start_url = "https://test.awsapps.com/start"
+region = "us-west-2"
+role = "Admin"
+
+[aws]
+default_region = "eu-west-1"
+config_file = "` + awsConfigFile + `"
+
+[[generate.chains]]
+name = "deploy"
+source_role = "Admin"
+role_arn_template = "arn:aws:iam::{{.AccountId}}:role/Deploy"
+role_session_name_template = "{{.User}}-deploy"
+`
+	require.NoError(t, os.WriteFile(appConfigFile, []byte(configContent), 0600))
+
+	mockSSOClient := &MockSSOClient{}
+	mockSSOClient.On("ListAccounts", mock.Anything, mock.Anything).Return(
+		&sso.ListAccountsOutput{
+			AccountList: []types.AccountInfo{
+				{AccountId: aws.String("123456789012"), AccountName: aws.String("Test Account")},
+			},
+		}, nil)
+
+	token := "mock-access-token"
+	c := NewWithDependencies(
+		context.Background(),
+		cli.NewMockUi(),
+		func(aws.Config) SSOClient { return mockSSOClient },
+		&MockTokenGenerator{token: &token},
+		func(context.Context) (aws.Config, error) { return aws.Config{}, nil },
+	)
+
+	require.Equal(t, 0, c.Run([]string{"--config=" + appConfigFile}))
+
+	awsConfig, err := configparser.NewConfigParserFromFile(awsConfigFile)
+	require.NoError(t, err)
+	assert.Equal(t, "Test Account", get(t, awsConfig, "profile Test Account-deploy", "source_profile"))
+	assert.Equal(t, "arn:aws:iam::123456789012:role/Deploy", get(t, awsConfig, "profile Test Account-deploy", "role_arn"))
+	assert.Equal(t, currentUser()+"-deploy", get(t, awsConfig, "profile Test Account-deploy", "role_session_name"))
+}
@@ -5,11 +5,12 @@ const help = `
 Usage: aws-sso-config generate [options]
 
   This command will auto-generate an AWS config file
-  with all accounts you have access to.
+  with all accounts you have access to. A token cached by
+  'aws-sso-config login' is reused while it is still valid.
 
 Examples:
 
-  # Generate using environment variables and defaults
+  # Generate using ~/.awsssoconfig and the other configuration layers
   aws-sso-config generate
 
   # Generate using a custom config file
@@ -17,4 +18,7 @@ Examples:
 
   # Show diff before writing changes
   aws-sso-config generate --diff --config=my-config.yaml
+
+  # Log in over SSH or in a container by entering a code on another device
+  aws-sso-config generate --no-browser --qr
 `
@@ -12,7 +12,7 @@ func NewConfigFlag() *ConfigFlag {
 			Name:        "config",
 			ShortFlag:   "c",
 			Description: "Path to configuration file",
-			Usage:       "Path to configuration file. If not specified, uses environment variables and defaults.",
+			Usage:       "Path to configuration file. If not specified, uses ~/.awsssoconfig.",
 		},
 	}
 }
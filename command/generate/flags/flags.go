@@ -47,6 +47,8 @@ func NewFlagRegistry() *FlagRegistry {
 		flags: []Flag{
 			NewDiffFlag(),
 			NewConfigFlag(),
+			NewNoBrowserFlag(),
+			NewQRCodeFlag(),
 		},
 	}
 }
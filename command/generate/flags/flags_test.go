@@ -170,3 +170,35 @@ func contains(s, substr string) bool {
 	}
 	return false
 }
+
+func TestNewNoBrowserFlag(t *testing.T) {
+	flag := NewNoBrowserFlag()
+
+	if flag.GetFlagName() != "no-browser" {
+		t.Errorf("GetFlagName() = %q, expected %q", flag.GetFlagName(), "no-browser")
+	}
+
+	if flag.GetShortFlag() != "" {
+		t.Errorf("GetShortFlag() = %q, expected no short flag", flag.GetShortFlag())
+	}
+
+	if !containsIgnoreCase(flag.GetDescription(), "browser") {
+		t.Errorf("GetDescription() = %q, expected to contain %q", flag.GetDescription(), "browser")
+	}
+}
+
+func TestNewQRCodeFlag(t *testing.T) {
+	flag := NewQRCodeFlag()
+
+	if flag.GetFlagName() != "qr" {
+		t.Errorf("GetFlagName() = %q, expected %q", flag.GetFlagName(), "qr")
+	}
+
+	if !containsIgnoreCase(flag.GetDescription(), "qr code") {
+		t.Errorf("GetDescription() = %q, expected to contain %q", flag.GetDescription(), "qr code")
+	}
+
+	if NewFlagRegistry().GetFlagByName("qr") == nil {
+		t.Error("qr flag is not registered")
+	}
+}
@@ -0,0 +1,18 @@
+package flags
+
+// NoBrowserFlag represents the no-browser flag configuration
+type NoBrowserFlag struct {
+	BaseFlag
+}
+
+// NewNoBrowserFlag creates a new no-browser flag configuration
+func NewNoBrowserFlag() *NoBrowserFlag {
+	return &NoBrowserFlag{
+		BaseFlag: BaseFlag{
+			Name:        "no-browser",
+			ShortFlag:   "",
+			Description: "Print the login code instead of opening a browser (auto-detected when unset)",
+			Usage:       "Print the verification URL and user code instead of opening a browser. Defaults to on over SSH, without a display, or when not on a terminal.",
+		},
+	}
+}
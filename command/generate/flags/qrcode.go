@@ -0,0 +1,18 @@
+package flags
+
+// QRCodeFlag represents the qr flag configuration
+type QRCodeFlag struct {
+	BaseFlag
+}
+
+// NewQRCodeFlag creates a new qr flag configuration
+func NewQRCodeFlag() *QRCodeFlag {
+	return &QRCodeFlag{
+		BaseFlag: BaseFlag{
+			Name:        "qr",
+			ShortFlag:   "",
+			Description: "Also show the login URL as a terminal QR code",
+			Usage:       "Render the verification URL as a QR code so it can be scanned with a phone",
+		},
+	}
+}
@@ -3,7 +3,6 @@ package generate
 import (
 	"context"
 	"fmt"
-	"log"
 	"os"
 	"os/exec"
 	"time"
@@ -21,32 +20,40 @@ import (
 
 // TokenGenerator interface for mocking
 type TokenGenerator interface {
-	GenerateTokenWithConfig(cfg aws.Config, appCfg *appconfig.Config) *string
+	GenerateTokenWithConfig(ctx context.Context, cfg aws.Config, appCfg *appconfig.Config, opts awsprovider.LoginOptions) (awsprovider.Token, error)
 }
 
 // DefaultTokenGenerator implements TokenGenerator using the real AWS functions
 type DefaultTokenGenerator struct{}
 
-func (g *DefaultTokenGenerator) GenerateTokenWithConfig(cfg aws.Config, appCfg *appconfig.Config) *string {
-	return awsprovider.GenerateTokenWithConfig(cfg, appCfg)
+func (g *DefaultTokenGenerator) GenerateTokenWithConfig(
+	ctx context.Context,
+	cfg aws.Config,
+	appCfg *appconfig.Config,
+	opts awsprovider.LoginOptions,
+) (awsprovider.Token, error) {
+	return awsprovider.GetTokenWithOptions(ctx, cfg, appCfg, opts)
 }
 
 type cmd struct {
 	UI    cli.Ui
+	ctx   context.Context
 	flags *pflag.FlagSet
 	help  string
 
 	diff       bool
 	configFile string
+	noBrowser  bool
+	qrCode     bool
 
 	// Dependencies for testing
 	ssoClientFactory func(aws.Config) SSOClient
 	tokenGenerator   TokenGenerator
-	configLoader     func() aws.Config
+	configLoader     func(context.Context) (aws.Config, error)
 }
 
-func New(ui cli.Ui) *cmd {
-	c := &cmd{UI: ui}
+func New(ctx context.Context, ui cli.Ui) *cmd {
+	c := &cmd{UI: ui, ctx: ctx}
 	c.Init()
 	// Set default dependencies
 	c.ssoClientFactory = func(cfg aws.Config) SSOClient {
@@ -58,8 +65,14 @@ func New(ui cli.Ui) *cmd {
 }
 
 // NewWithDependencies creates a new command with injected dependencies for testing
-func NewWithDependencies(ui cli.Ui, ssoClientFactory func(aws.Config) SSOClient, tokenGenerator TokenGenerator, configLoader func() aws.Config) *cmd {
-	c := &cmd{UI: ui}
+func NewWithDependencies(
+	ctx context.Context,
+	ui cli.Ui,
+	ssoClientFactory func(aws.Config) SSOClient,
+	tokenGenerator TokenGenerator,
+	configLoader func(context.Context) (aws.Config, error),
+) *cmd {
+	c := &cmd{UI: ui, ctx: ctx}
 	c.Init()
 	c.ssoClientFactory = ssoClientFactory
 	c.tokenGenerator = tokenGenerator
@@ -74,10 +87,14 @@ func (c *cmd) Init() {
 	registry := generateflags.NewFlagRegistry()
 	diffFlag := registry.GetFlagByName("diff")
 	configFlag := registry.GetFlagByName("config")
+	noBrowserFlag := registry.GetFlagByName("no-browser")
+	qrCodeFlag := registry.GetFlagByName("qr")
 
 	// Add flags with both short and long forms
 	c.flags.BoolVarP(&c.diff, diffFlag.GetFlagName(), diffFlag.GetShortFlag(), false, diffFlag.GetDescription())
 	c.flags.StringVarP(&c.configFile, configFlag.GetFlagName(), configFlag.GetShortFlag(), "", configFlag.GetDescription())
+	c.flags.BoolVar(&c.noBrowser, noBrowserFlag.GetFlagName(), false, noBrowserFlag.GetDescription())
+	c.flags.BoolVar(&c.qrCode, qrCodeFlag.GetFlagName(), false, qrCodeFlag.GetDescription())
 
 	c.help = c.buildHelp()
 }
@@ -87,18 +104,10 @@ func (c *cmd) Run(args []string) int {
 		return 1
 	}
 
-	// Load configuration
-	var appCfg *appconfig.Config
-	var err error
-
-	if c.configFile != "" {
-		appCfg, err = appconfig.Load(c.configFile)
-		if err != nil {
-			c.UI.Error(fmt.Sprintf("Configuration error: %v", err))
-			return 1
-		}
-	} else {
-		appCfg = appconfig.Default()
+	appCfg, err := appconfig.Load(c.configFile)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Configuration error: %v", err))
+		return 1
 	}
 
 	if err := appCfg.Validate(); err != nil {
@@ -108,19 +117,42 @@ func (c *cmd) Run(args []string) int {
 
 	configFile := appCfg.ConfigFile()
 
-	cfg := c.configLoader()
-	token := c.tokenGenerator.GenerateTokenWithConfig(cfg, appCfg)
+	cfg, err := c.configLoader(c.ctx)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+	token, err := c.tokenGenerator.GenerateTokenWithConfig(c.ctx, cfg, appCfg, c.loginOptions())
+	if err != nil {
+		c.UI.Error(err.Error())
+		if hint := awsprovider.ErrorHint(err); hint != "" {
+			c.UI.Error(hint)
+		}
+		return awsprovider.ExitCode(err)
+	}
 
 	// create sso client
 	ssoClient := c.ssoClientFactory(cfg)
 
-	if generateAwsConfigFile(ssoClient, token, configFile, c.diff, appCfg) != nil {
+	if err := generateAwsConfigFile(c.ctx, ssoClient, aws.String(token.AccessToken), configFile, c.diff, appCfg); err != nil {
+		c.UI.Error(err.Error())
 		return 1
 	}
 
 	return 0
 }
 
+// loginOptions builds the login options from flags, auto-detecting headless mode
+// unless --no-browser was given explicitly
+func (c *cmd) loginOptions() awsprovider.LoginOptions {
+	opts := awsprovider.DefaultLoginOptions()
+	if c.flags.Changed("no-browser") {
+		opts.NoBrowser = c.noBrowser
+	}
+	opts.QRCode = c.qrCode
+	return opts
+}
+
 func (c *cmd) buildHelp() string {
 	helpText := help + "\n"
 	if c.flags != nil {
@@ -151,13 +183,12 @@ func showFileDiff(file1, file2 string) {
 	cmd.Run() // Ignore error as diff returns non-zero when files differ
 }
 
-func generateAwsConfigFile(ssoClient SSOClient, token *string, configFile string, diff bool, appCfg *appconfig.Config) error {
+func generateAwsConfigFile(ctx context.Context, ssoClient SSOClient, token *string, configFile string, diff bool, appCfg *appconfig.Config) error {
 	configFileNew := configFile + ".new"
 
 	awsConfig, err := configparser.NewConfigParserFromFile(configFile)
 	if err != nil {
-		log.Fatal(err)
-		return err
+		return fmt.Errorf("failed to read %s: %w", configFile, err)
 	}
 
 	fmt.Println("Fetching list of all accounts for user")
@@ -167,15 +198,16 @@ func generateAwsConfigFile(ssoClient SSOClient, token *string, configFile string
 		AccessToken: token,
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
 	accountsResult, err := ssoClient.ListAccounts(ctx, listAccountsInput)
 	if err != nil {
-		fmt.Printf("Error fetching accounts: %v\n", err)
-		return err
+		return fmt.Errorf("error fetching accounts: %w", err)
 	}
 
+	profiles := make([]accountProfile, 0, len(accountsResult.AccountList))
+
 	for _, y := range accountsResult.AccountList {
 		// Add all accounts - users can configure filtering if needed
 		accountName := aws.ToString(y.AccountName)
@@ -196,6 +228,16 @@ func generateAwsConfigFile(ssoClient SSOClient, token *string, configFile string
 		awsConfig.Set(section, "sso_region", appCfg.SSORegion())
 		awsConfig.Set(section, "sso_start_url", appCfg.SSOStartURL())
 		awsConfig.Set(section, "region", appCfg.DefaultRegion())
+		// Not read by the AWS CLI, recorded so that switch can search profiles by email
+		if email := aws.ToString(y.EmailAddress); email != "" {
+			awsConfig.Set(section, "sso_account_email", email)
+		}
+
+		profiles = append(profiles, accountProfile{name: profileName, accountID: aws.ToString(y.AccountId), accountName: accountName})
+	}
+
+	if err := addChainedProfiles(awsConfig, profiles, appCfg); err != nil {
+		return err
 	}
 
 	err = awsConfig.SaveWithDelimiter(configFileNew, "=")
@@ -1,24 +1,31 @@
 package generate
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sso"
 	"github.com/aws/aws-sdk-go-v2/service/sso/types"
 	"github.com/mitchellh/cli"
+	"github.com/mitchellh/go-homedir"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/blairham/aws-sso-config/internal/testutil"
+	awsprovider "github.com/blairham/aws-sso-config/providers/aws"
 	appconfig "github.com/blairham/aws-sso-config/providers/config"
 )
 
 func TestInit(t *testing.T) {
 	ui := cli.NewMockUi()
-	c := New(ui)
+	c := New(context.Background(), ui)
 
 	// Verify the command is properly initialized
 	assert.NotNil(t, c.flags)
@@ -56,8 +63,9 @@ config_file = "` + awsConfigFile + `"`
 		&sso.ListAccountsOutput{
 			AccountList: []types.AccountInfo{
 				{
-					AccountId:   aws.String("123456789012"),
-					AccountName: aws.String("Production Account"),
+					AccountId:    aws.String("123456789012"),
+					AccountName:  aws.String("Production Account"),
+					EmailAddress: aws.String("production@example.com"),
 				},
 				{
 					AccountId:   aws.String("987654321098"),
@@ -75,11 +83,11 @@ config_file = "` + awsConfigFile + `"`
 		token: &token,
 	}
 
-	mockConfigLoader := func() aws.Config {
-		return aws.Config{}
+	mockConfigLoader := func(context.Context) (aws.Config, error) {
+		return aws.Config{}, nil
 	}
 
-	c := NewWithDependencies(ui, mockSSOClientFactory, mockTokenGenerator, mockConfigLoader)
+	c := NewWithDependencies(context.Background(), ui, mockSSOClientFactory, mockTokenGenerator, mockConfigLoader)
 
 	// Test successful generation
 	exitCode := c.Run([]string{"--config=" + appConfigFile})
@@ -97,11 +105,12 @@ config_file = "` + awsConfigFile + `"`
 	assert.Contains(t, content, "Development Account", "Should contain development account profile")
 	assert.Contains(t, content, "123456789012", "Should contain production account ID")
 	assert.Contains(t, content, "987654321098", "Should contain development account ID")
+	assert.Contains(t, content, "sso_account_email = production@example.com", "Should record the account email")
 }
 
 func TestGenerateFlagParsing(t *testing.T) {
 	ui := cli.NewMockUi()
-	c := New(ui)
+	c := New(context.Background(), ui)
 
 	// Test just the flag parsing
 	tempDir := t.TempDir()
@@ -126,6 +135,33 @@ config_file: "/tmp/test-aws-config"
 	assert.True(t, c.diff)
 }
 
+func TestGenerateLoginOptions(t *testing.T) {
+	t.Run("no-browser flag overrides detection", func(t *testing.T) {
+		c := New(context.Background(), cli.NewMockUi())
+		require.NoError(t, c.flags.Parse([]string{"--no-browser", "--qr"}))
+
+		opts := c.loginOptions()
+		assert.True(t, opts.NoBrowser)
+		assert.True(t, opts.QRCode)
+	})
+
+	t.Run("explicitly disabled no-browser forces the browser", func(t *testing.T) {
+		c := New(context.Background(), cli.NewMockUi())
+		require.NoError(t, c.flags.Parse([]string{"--no-browser=false"}))
+
+		opts := c.loginOptions()
+		assert.False(t, opts.NoBrowser)
+		assert.False(t, opts.QRCode)
+	})
+
+	t.Run("unset flag falls back to headless detection", func(t *testing.T) {
+		c := New(context.Background(), cli.NewMockUi())
+		require.NoError(t, c.flags.Parse([]string{}))
+
+		assert.Equal(t, awsprovider.IsHeadless(), c.loginOptions().NoBrowser)
+	})
+}
+
 func TestGenerateWithoutConfigFile(t *testing.T) {
 	t.Skip("Skipping test that requires AWS SSO integration")
 }
@@ -144,7 +180,7 @@ func TestGenerateInvalidFlags(t *testing.T) {
 
 func TestGenerateHelpOutput(t *testing.T) {
 	ui := cli.NewMockUi()
-	c := New(ui)
+	c := New(context.Background(), ui)
 
 	help := c.Help()
 	assert.Contains(t, help, "Usage: aws-sso-config generate")
@@ -157,7 +193,7 @@ func TestGenerateHelpOutput(t *testing.T) {
 
 func TestGenerateSynopsis(t *testing.T) {
 	ui := cli.NewMockUi()
-	c := New(ui)
+	c := New(context.Background(), ui)
 
 	assert.Equal(t, synopsis, c.Synopsis())
 }
@@ -210,7 +246,7 @@ func TestGenerateFlagParsingTable(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ui := cli.NewMockUi()
-			c := New(ui)
+			c := New(context.Background(), ui)
 
 			// We expect these to fail due to authentication, but flags should parse
 			c.Run(tt.args)
@@ -236,14 +272,14 @@ func TestRunError(t *testing.T) {
 	}
 
 	mockTokenGenerator := &MockTokenGenerator{
-		shouldFail: true, // This will cause the token generation to fail
+		err: errors.New("token generation failed"),
 	}
 
-	mockConfigLoader := func() aws.Config {
-		return aws.Config{}
+	mockConfigLoader := func(context.Context) (aws.Config, error) {
+		return aws.Config{}, nil
 	}
 
-	c := NewWithDependencies(ui, mockSSOClientFactory, mockTokenGenerator, mockConfigLoader)
+	c := NewWithDependencies(context.Background(), ui, mockSSOClientFactory, mockTokenGenerator, mockConfigLoader)
 
 	// Create a temporary directory and files
 	tmpDir := t.TempDir()
@@ -261,7 +297,7 @@ func TestRunError(t *testing.T) {
 // TestRunConfigFileError tests Run with config file error
 func TestRunConfigFileError(t *testing.T) {
 	ui := cli.NewMockUi()
-	c := New(ui)
+	c := New(context.Background(), ui)
 
 	// Create a temporary invalid YAML file
 	tmpDir := t.TempDir()
@@ -279,7 +315,7 @@ func TestRunDefaultConfig(t *testing.T) {
 	t.Skip("Skipping test that requires AWS SSO authentication")
 
 	ui := cli.NewMockUi()
-	c := New(ui)
+	c := New(context.Background(), ui)
 
 	// This test will likely fail due to authentication requirements, but it exercises the default config path
 	exitCode := c.Run([]string{})
@@ -290,7 +326,7 @@ func TestRunDefaultConfig(t *testing.T) {
 // TestRunParseError tests Run with parse error
 func TestRunParseError(t *testing.T) {
 	ui := cli.NewMockUi()
-	c := New(ui)
+	c := New(context.Background(), ui)
 
 	// Test with invalid flag
 	exitCode := c.Run([]string{"-invalid-flag"})
@@ -343,11 +379,11 @@ config_file = "` + awsConfigFile + `"`
 		token: &token,
 	}
 
-	mockConfigLoader := func() aws.Config {
-		return aws.Config{}
+	mockConfigLoader := func(context.Context) (aws.Config, error) {
+		return aws.Config{}, nil
 	}
 
-	c := NewWithDependencies(ui, mockSSOClientFactory, mockTokenGenerator, mockConfigLoader)
+	c := NewWithDependencies(context.Background(), ui, mockSSOClientFactory, mockTokenGenerator, mockConfigLoader)
 
 	// Test with valid config
 	exitCode := c.Run([]string{"--config=" + appConfigFile})
@@ -359,19 +395,63 @@ config_file = "` + awsConfigFile + `"`
 
 // MockTokenGenerator implements TokenGenerator for testing
 type MockTokenGenerator struct {
-	shouldFail bool
-	token      *string
+	err   error
+	token *string
+	// appCfg is the configuration the last call logged in with
+	appCfg *appconfig.Config
 }
 
-func (m *MockTokenGenerator) GenerateTokenWithConfig(cfg aws.Config, appCfg *appconfig.Config) *string {
-	if m.shouldFail {
-		return nil
+func (m *MockTokenGenerator) GenerateTokenWithConfig(
+	_ context.Context,
+	cfg aws.Config,
+	appCfg *appconfig.Config,
+	opts awsprovider.LoginOptions,
+) (awsprovider.Token, error) {
+	m.appCfg = appCfg
+	if m.err != nil {
+		return awsprovider.Token{}, m.err
 	}
 	if m.token != nil {
-		return m.token
+		return awsprovider.Token{AccessToken: *m.token, Source: awsprovider.TokenSourceCache}, nil
+	}
+	return awsprovider.Token{AccessToken: "mock-token", Source: awsprovider.TokenSourceInteractive}, nil
+}
+
+// TestGenerateTokenErrors tests that token errors stop generate before any SSO call is made
+func TestGenerateTokenErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		exitCode int
+		hint     string
+	}{
+		{"denied", fmt.Errorf("SSO login failed: %w", awsprovider.ErrAuthorizationDenied), awsprovider.ExitCodeAuthorizationDenied, "approve it"},
+		{"expired", awsprovider.ErrAuthorizationExpired, awsprovider.ExitCodeAuthorizationExpired, "new code"},
+		{"registration", awsprovider.ErrRegistrationFailed, awsprovider.ExitCodeRegistrationFailed, "sso.start_url"},
+		{"cancelled", fmt.Errorf("%w: %w", awsprovider.ErrLoginCancelled, context.Canceled), awsprovider.ExitCodeCancelled, ""},
+		{"other", errors.New("boom"), 1, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ui := cli.NewMockUi()
+			appConfigFile := filepath.Join(t.TempDir(), "app-config.toml")
+			require.NoError(t, os.WriteFile(appConfigFile, []byte("[sso]\nstart_url = \"https://test.awsapps.com/start\"\n"), 0600))
+
+			ssoClient := &MockSSOClient{}
+			c := NewWithDependencies(context.Background(), ui,
+				func(aws.Config) SSOClient { return ssoClient },
+				&MockTokenGenerator{err: tt.err},
+				func(context.Context) (aws.Config, error) { return aws.Config{}, nil })
+
+			assert.Equal(t, tt.exitCode, c.Run([]string{"--config=" + appConfigFile}))
+			assert.Contains(t, ui.ErrorWriter.String(), tt.err.Error())
+			if tt.hint != "" {
+				assert.Contains(t, ui.ErrorWriter.String(), tt.hint)
+			}
+			ssoClient.AssertNotCalled(t, "ListAccounts", mock.Anything, mock.Anything)
+		})
 	}
-	token := "mock-token"
-	return &token
 }
 
 // TestFlagFormatDocumentation documents correct and incorrect flag usage patterns
@@ -417,7 +497,7 @@ func TestFlagFormatDocumentation(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := New(ui)
+			c := New(context.Background(), ui)
 			err := c.flags.Parse(tt.args)
 
 			if tt.expectError {
@@ -430,3 +510,172 @@ func TestFlagFormatDocumentation(t *testing.T) {
 		})
 	}
 }
+
+// clearEnv unsets the variables that override the configuration files
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, k := range appconfig.Keys() {
+		t.Setenv(k.Env, "")
+	}
+	t.Setenv(appconfig.ContextEnv, "")
+}
+
+// runInHome runs generate without --config in a home directory made by newHome. It
+// returns the exit code, the token generator and the AWS config file.
+func runInHome(t *testing.T, userConfig string, args ...string) (int, *MockTokenGenerator, string) {
+	t.Helper()
+	_, awsConfigFile := newHome(t, userConfig)
+	exitCode, tokenGenerator := runGenerate(args...)
+	return exitCode, tokenGenerator, awsConfigFile
+}
+
+// newHome makes a temporary home and working directory holding userConfig as
+// ~/.awsssoconfig, {{AWS_CONFIG}} replaced by an AWS config file there. It returns
+// the home directory and the AWS config file.
+func newHome(t *testing.T, userConfig string) (string, string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	homedir.DisableCache = true
+	t.Cleanup(func() { homedir.DisableCache = false })
+	t.Chdir(home)
+
+	awsConfigFile := filepath.Join(home, "aws-config")
+	require.NoError(t, os.WriteFile(awsConfigFile, []byte("[default]\nregion = us-east-1\n"), 0600))
+	userConfig = strings.ReplaceAll(userConfig, "{{AWS_CONFIG}}", awsConfigFile)
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".awsssoconfig"), []byte(userConfig), 0600))
+	return home, awsConfigFile
+}
+
+// runGenerate runs generate with args against an SSO client listing one account
+func runGenerate(args ...string) (int, *MockTokenGenerator) {
+	mockSSOClient := &MockSSOClient{}
+	mockSSOClient.On("ListAccounts", mock.Anything, mock.Anything).Return(
+		&sso.ListAccountsOutput{
+			AccountList: []types.AccountInfo{
+				{AccountId: aws.String("123456789012"), AccountName: aws.String("dev")},
+			},
+		}, nil).Maybe()
+	tokenGenerator := &MockTokenGenerator{}
+	c := NewWithDependencies(context.Background(), cli.NewMockUi(),
+		func(aws.Config) SSOClient { return mockSSOClient }, tokenGenerator, testutil.ConfigLoader)
+	return c.Run(args), tokenGenerator
+}
+
+// TestGenerateReadsUserFile checks that generate reads ~/.awsssoconfig without --config
+func TestGenerateReadsUserFile(t *testing.T) {
+	clearEnv(t)
+	t.Run("chains reach the generator", func(t *testing.T) {
+		exitCode, tokenGenerator, awsConfigFile := runInHome(t, `[sso]
+start_url = "https://team.awsapps.com/start"
+role = "AdministratorAccess"
+
+[aws]
+config_file = "{{AWS_CONFIG}}"
+
+[[generate.chains]]
+name = "deploy"
+source_role = "AdministratorAccess"
+role_arn_template = "arn:aws:iam::{{.AccountId}}:role/Deploy"
+`)
+		require.Equal(t, 0, exitCode)
+
+		assert.Equal(t, "https://team.awsapps.com/start", tokenGenerator.appCfg.SSO.StartURL)
+		content, err := os.ReadFile(awsConfigFile)
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "role_arn = arn:aws:iam::123456789012:role/Deploy")
+	})
+
+	t.Run("invalid chains stop generate", func(t *testing.T) {
+		exitCode, tokenGenerator, _ := runInHome(t, `[sso]
+start_url = "https://team.awsapps.com/start"
+role = "AdministratorAccess"
+
+[[generate.chains]]
+name = "a"
+source_role = "b"
+role_arn_template = "arn:aws:iam::{{.AccountId}}:role/A"
+
+[[generate.chains]]
+name = "b"
+source_role = "a"
+role_arn_template = "arn:aws:iam::{{.AccountId}}:role/B"
+`)
+		assert.Equal(t, 1, exitCode)
+		assert.Nil(t, tokenGenerator.appCfg, "no login with an invalid configuration")
+	})
+}
+
+// TestGenerateUsesContext checks that the context selected with --context, through
+// AWS_SSO_CONFIG_CONTEXT, is the one generate logs in to
+func TestGenerateUsesContext(t *testing.T) {
+	clearEnv(t)
+	t.Setenv(appconfig.ContextEnv, "client")
+
+	exitCode, tokenGenerator, _ := runInHome(t, `[sso]
+start_url = "https://team.awsapps.com/start"
+role = "AdministratorAccess"
+
+[aws]
+config_file = "{{AWS_CONFIG}}"
+
+[contexts.client.sso]
+start_url = "https://client.awsapps.com/start"
+region = "eu-west-1"
+`)
+	require.Equal(t, 0, exitCode)
+	assert.Equal(t, "https://client.awsapps.com/start", tokenGenerator.appCfg.SSO.StartURL)
+	assert.Equal(t, "eu-west-1", tokenGenerator.appCfg.SSO.Region)
+	assert.Equal(t, "client", tokenGenerator.appCfg.Context)
+}
+
+// TestGenerateUsesProjectFile checks that the project file in the working directory
+// applies to generate, without its trusted keys
+func TestGenerateUsesProjectFile(t *testing.T) {
+	clearEnv(t)
+	home, _ := newHome(t, `[sso]
+start_url = "https://team.awsapps.com/start"
+role = "AdministratorAccess"
+
+[aws]
+config_file = "{{AWS_CONFIG}}"
+`)
+	require.NoError(t, os.WriteFile(filepath.Join(home, appconfig.ProjectConfigFileName), []byte(`[sso]
+start_url = "https://elsewhere.awsapps.com/start"
+role = "ProjectRole"
+`), 0600))
+
+	exitCode, tokenGenerator := runGenerate()
+	require.Equal(t, 0, exitCode)
+	assert.Equal(t, "ProjectRole", tokenGenerator.appCfg.SSO.Role)
+	assert.Equal(t, "https://team.awsapps.com/start", tokenGenerator.appCfg.SSO.StartURL, "project files cannot set the start URL")
+}
+
+// TestGenerateUsesEnv checks that AWS_SSO_CONFIG_* variables override the user file in
+// generate, and that invalid values stop it before logging in
+func TestGenerateUsesEnv(t *testing.T) {
+	const userConfig = `[sso]
+start_url = "https://team.awsapps.com/start"
+role = "AdministratorAccess"
+
+[aws]
+config_file = "{{AWS_CONFIG}}"
+`
+	t.Run("overrides", func(t *testing.T) {
+		clearEnv(t)
+		t.Setenv("AWS_SSO_CONFIG_SSO_START_URL", "https://env.awsapps.com/start")
+
+		exitCode, tokenGenerator, _ := runInHome(t, userConfig)
+		require.Equal(t, 0, exitCode)
+		assert.Equal(t, "https://env.awsapps.com/start", tokenGenerator.appCfg.SSO.StartURL)
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		clearEnv(t)
+		t.Setenv("AWS_SSO_CONFIG_SSO_REGION", "narnia")
+
+		exitCode, tokenGenerator, _ := runInHome(t, userConfig)
+		assert.Equal(t, 1, exitCode)
+		assert.Nil(t, tokenGenerator.appCfg)
+	})
+}
@@ -23,9 +23,9 @@ type SSOClient interface {
 
 // ConfigGeneratorIface defines the interface for config generator operations
 type ConfigGeneratorIface interface {
-	GenerateConfigFile(ssoClient SSOClient, token *string, configFile string, showDiff bool, appCfg *appconfig.Config) error
-	ListAccountsWithClient(ssoClient SSOClient, token *string) ([]types.AccountInfo, error)
-	GetAccountRolesWithClient(ssoClient SSOClient, token *string, accountID string) ([]types.RoleInfo, error)
+	GenerateConfigFile(ctx context.Context, ssoClient SSOClient, token *string, configFile string, showDiff bool, appCfg *appconfig.Config) error
+	ListAccountsWithClient(ctx context.Context, ssoClient SSOClient, token *string) ([]types.AccountInfo, error)
+	GetAccountRolesWithClient(ctx context.Context, ssoClient SSOClient, token *string, accountID string) ([]types.RoleInfo, error)
 	WriteSectionToConfig(configParser *configparser.ConfigParser, sectionName string, values map[string]string) error
 }
 
@@ -46,13 +46,13 @@ func NewConfigGenerator(ssoStartURL, ssoRegion, defaultRegion string) *ConfigGen
 }
 
 // ListAccountsWithClient lists AWS accounts with the provided SSO client
-func (g *ConfigGenerator) ListAccountsWithClient(ssoClient SSOClient, token *string) ([]types.AccountInfo, error) {
+func (g *ConfigGenerator) ListAccountsWithClient(ctx context.Context, ssoClient SSOClient, token *string) ([]types.AccountInfo, error) {
 	if token == nil {
 		return nil, errors.New("no SSO token provided")
 	}
 
 	// List accounts
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
 	accountsOutput, err := ssoClient.ListAccounts(ctx, &sso.ListAccountsInput{
@@ -66,13 +66,13 @@ func (g *ConfigGenerator) ListAccountsWithClient(ssoClient SSOClient, token *str
 }
 
 // GetAccountRolesWithClient gets AWS account roles using the provided SSO client
-func (g *ConfigGenerator) GetAccountRolesWithClient(ssoClient SSOClient, token *string, accountID string) ([]types.RoleInfo, error) {
+func (g *ConfigGenerator) GetAccountRolesWithClient(ctx context.Context, ssoClient SSOClient, token *string, accountID string) ([]types.RoleInfo, error) {
 	if token == nil {
 		return nil, errors.New("no SSO token provided")
 	}
 
 	// List roles for this account
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
 	rolesOutput, err := ssoClient.ListAccountRoles(ctx, &sso.ListAccountRolesInput{
@@ -79,7 +79,7 @@ func TestListAccountsWithClient(t *testing.T) {
 
 	// Create generator and call the method
 	generator := NewConfigGenerator("https://test.com", "us-west-2", "us-east-1")
-	accounts, err := generator.ListAccountsWithClient(mockClient, &token)
+	accounts, err := generator.ListAccountsWithClient(context.Background(), mockClient, &token)
 
 	// Verify results
 	require.NoError(t, err)
@@ -87,7 +87,7 @@ func TestListAccountsWithClient(t *testing.T) {
 	mockClient.AssertExpectations(t)
 
 	// Test with nil token
-	accounts, err = generator.ListAccountsWithClient(mockClient, nil)
+	accounts, err = generator.ListAccountsWithClient(context.Background(), mockClient, nil)
 	assert.Error(t, err)
 	assert.Nil(t, accounts)
 
@@ -95,7 +95,7 @@ func TestListAccountsWithClient(t *testing.T) {
 	mockClient.ExpectedCalls = nil
 	mockClient.On("ListAccounts", mock.Anything, mock.Anything).Return(nil, errors.New("API error"))
 
-	accounts, err = generator.ListAccountsWithClient(mockClient, &token)
+	accounts, err = generator.ListAccountsWithClient(context.Background(), mockClient, &token)
 	assert.Error(t, err)
 	assert.Nil(t, accounts)
 	mockClient.AssertExpectations(t)
@@ -129,7 +129,7 @@ func TestGetAccountRolesWithClient(t *testing.T) {
 
 	// Create generator and call the method
 	generator := NewConfigGenerator("https://test.com", "us-west-2", "us-east-1")
-	roles, err := generator.GetAccountRolesWithClient(mockClient, &token, accountID)
+	roles, err := generator.GetAccountRolesWithClient(context.Background(), mockClient, &token, accountID)
 
 	// Verify results
 	require.NoError(t, err)
@@ -137,7 +137,7 @@ func TestGetAccountRolesWithClient(t *testing.T) {
 	mockClient.AssertExpectations(t)
 
 	// Test with nil token
-	roles, err = generator.GetAccountRolesWithClient(mockClient, nil, accountID)
+	roles, err = generator.GetAccountRolesWithClient(context.Background(), mockClient, nil, accountID)
 	assert.Error(t, err)
 	assert.Nil(t, roles)
 
@@ -145,7 +145,7 @@ func TestGetAccountRolesWithClient(t *testing.T) {
 	mockClient.ExpectedCalls = nil
 	mockClient.On("ListAccountRoles", mock.Anything, mock.Anything).Return(nil, errors.New("API error"))
 
-	roles, err = generator.GetAccountRolesWithClient(mockClient, &token, accountID)
+	roles, err = generator.GetAccountRolesWithClient(context.Background(), mockClient, &token, accountID)
 	assert.Error(t, err)
 	assert.Nil(t, roles)
 	mockClient.AssertExpectations(t)
@@ -0,0 +1,35 @@
+package initconfig
+
+const synopsis = "Create the configuration file interactively"
+const help = `
+Usage: aws-sso-config init [options]
+
+  Create the configuration file, ~/.awsssoconfig by default, by asking
+  for the SSO start URL, the SSO region, the default region of the
+  generated profiles and the role to use in each account. Press enter
+  to keep the value shown in brackets, taken from the system or project
+  file, the environment or the defaults.
+
+  Each answer is checked with the same validators as config set. An
+  answer given as a flag is not asked for, so init can run unattended,
+  e.g. when building an image.
+
+  With --force an existing file is updated with the answers; its
+  contexts, [[repos]] mappings, [[generate.chains]] and the keys not
+  asked about are kept.
+
+  Other commands never create the file; without one they use the
+  defaults and the system and project files.
+
+Examples:
+
+  # Answer the questions
+  aws-sso-config init
+
+  # Create the file without asking
+  aws-sso-config init --start-url https://acme.awsapps.com/start --sso-region eu-west-1 \
+    --region eu-west-1 --role Developer
+
+  # Answer the questions again for an existing file
+  aws-sso-config init --force
+`
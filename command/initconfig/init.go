@@ -0,0 +1,176 @@
+package initconfig
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/mitchellh/cli"
+	"github.com/spf13/pflag"
+
+	appconfig "github.com/blairham/aws-sso-config/providers/config"
+)
+
+// question asks for the value of a registered key, unless its flag is given
+type question struct {
+	key    string
+	flag   string
+	prompt string
+}
+
+var questions = []question{
+	{key: "sso.start_url", flag: "start-url", prompt: "SSO start URL"},
+	{key: "sso.region", flag: "sso-region", prompt: "SSO region"},
+	{key: "aws.default_region", flag: "region", prompt: "Default region for generated profiles"},
+	{key: "sso.role", flag: "role", prompt: "Role to use in each account"},
+}
+
+type cmd struct {
+	UI    cli.Ui
+	flags *pflag.FlagSet
+	help  string
+
+	configFile string
+	force      bool
+	answers    map[string]*string
+}
+
+func New(ui cli.Ui) *cmd {
+	c := &cmd{UI: ui}
+	c.Init()
+	return c
+}
+
+func (c *cmd) Init() {
+	c.flags = pflag.NewFlagSet("init", pflag.ContinueOnError)
+	c.flags.StringVarP(&c.configFile, "config", "c", "", "Path to configuration file to create")
+	c.flags.BoolVar(&c.force, "force", false, "Update an existing configuration file with the answers")
+	c.answers = map[string]*string{}
+	for _, q := range questions {
+		c.answers[q.key] = c.flags.String(q.flag, "", q.prompt)
+	}
+
+	c.help = help + "\n" + c.flags.FlagUsages()
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+	if c.flags.NArg() != 0 {
+		c.UI.Error("Usage: aws-sso-config init [options]")
+		return 1
+	}
+
+	cm := appconfig.NewConfigManager(c.configFile)
+	_, err := os.Stat(cm.Path())
+	exists := err == nil
+	if exists && !c.force {
+		c.UI.Error(fmt.Sprintf("%s already exists, use --force to answer the questions again or config set to change a value", cm.Path()))
+		return 1
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	// The current values are offered as defaults
+	current, err := cm.Load()
+	if err != nil {
+		c.UI.Warn(fmt.Sprintf("Ignoring the current configuration: %v", err))
+		current = appconfig.Default()
+	}
+
+	// Answers given as flags are checked before anything is asked
+	config := &appconfig.Config{}
+	for _, q := range questions {
+		k, _ := appconfig.LookupKey(q.key)
+		if !c.flags.Changed(q.flag) {
+			continue
+		}
+		if err := k.Set(config, *c.answers[q.key]); err != nil {
+			c.UI.Error(err.Error())
+			return 1
+		}
+	}
+	for _, q := range questions {
+		k, _ := appconfig.LookupKey(q.key)
+		if c.flags.Changed(q.flag) {
+			continue
+		}
+		if err := c.ask(q, k, suggestion(k, current, config), config); err != nil {
+			c.UI.Error(fmt.Sprintf("Error reading answer: %v", err))
+			return 1
+		}
+	}
+
+	// An existing file keeps its contexts, mappings, chains and the keys not asked about
+	if exists {
+		names := make([]string, 0, len(questions))
+		for _, q := range questions {
+			names = append(names, q.key)
+		}
+		if err := cm.SaveKeys(config, names...); err != nil {
+			c.UI.Error(fmt.Sprintf("Error updating %s: %v", cm.Path(), err))
+			return 1
+		}
+		c.UI.Output(fmt.Sprintf("Updated %s", cm.Path()))
+	} else {
+		if err := cm.Create(config); err != nil {
+			c.UI.Error(fmt.Sprintf("Error writing %s: %v", cm.Path(), err))
+			return 1
+		}
+		c.UI.Output(fmt.Sprintf("Created %s", cm.Path()))
+	}
+	c.UI.Output("Run aws-sso-config generate to log in and write your AWS profiles.")
+	return 0
+}
+
+// ask asks for k until a valid value is given, then stores it in config
+func (c *cmd) ask(q question, k appconfig.Key, suggested string, config *appconfig.Config) error {
+	query := q.prompt + ": "
+	if suggested != "" {
+		query = fmt.Sprintf("%s [%s]: ", q.prompt, suggested)
+	}
+	for {
+		answer, err := c.UI.Ask(query)
+		if err != nil {
+			return err
+		}
+		answer = strings.TrimSpace(answer)
+		if answer == "" {
+			answer = suggested
+		}
+		if answer == "" {
+			c.UI.Error("A value is required")
+			continue
+		}
+		if err := k.Set(config, answer); err != nil {
+			c.UI.Error(err.Error())
+			continue
+		}
+		return nil
+	}
+}
+
+// suggestion returns the value offered for k: its current value, except for the
+// placeholder start URL, and the SSO region for a default region that was never set
+func suggestion(k appconfig.Key, current, answered *appconfig.Config) string {
+	set := current.Origins[k.Name].Layer != appconfig.LayerDefault
+	switch {
+	case k.Name == "sso.start_url" && !set:
+		return ""
+	case k.Name == "aws.default_region" && !set:
+		return answered.SSO.Region
+	}
+	return k.Get(current)
+}
+
+func (c *cmd) Help() string {
+	return c.help
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
@@ -0,0 +1,188 @@
+package initconfig
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/mitchellh/cli"
+	"github.com/mitchellh/go-homedir"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blairham/aws-sso-config/command/generate"
+	"github.com/blairham/aws-sso-config/internal/testutil"
+	awsprovider "github.com/blairham/aws-sso-config/providers/aws"
+	appconfig "github.com/blairham/aws-sso-config/providers/config"
+)
+
+// newUI answers the questions with lines; MockUi buffers its input on every Ask, so
+// it is read one byte at a time
+func newUI(lines ...string) *cli.MockUi {
+	ui := cli.NewMockUi()
+	ui.InputReader = iotest.OneByteReader(strings.NewReader(strings.Join(lines, "\n") + "\n"))
+	return ui
+}
+
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, k := range appconfig.Keys() {
+		t.Setenv(k.Env, "")
+	}
+	t.Setenv(appconfig.ContextEnv, "")
+}
+
+func TestInitWizard(t *testing.T) {
+	clearEnv(t)
+	configFile := filepath.Join(t.TempDir(), "config.toml")
+	ui := newUI("acme", "https://acme.awsapps.com/start", "eu-west-1", "", "Developer")
+
+	code := New(ui).Run([]string{"--config", configFile})
+	require.Equal(t, 0, code, ui.ErrorWriter.String())
+	assert.Contains(t, ui.ErrorWriter.String(), `"acme" is not a URL, did you mean https://acme.awsapps.com/start?`)
+	assert.Contains(t, ui.OutputWriter.String(), "SSO region [us-east-1]: ")
+	assert.Contains(t, ui.OutputWriter.String(), "Default region for generated profiles [eu-west-1]: ", "the SSO region is suggested")
+	assert.Contains(t, ui.OutputWriter.String(), "Created "+configFile)
+
+	config, err := appconfig.Load(configFile)
+	require.NoError(t, err)
+	assert.Equal(t, "https://acme.awsapps.com/start", config.SSO.StartURL)
+	assert.Equal(t, "eu-west-1", config.SSO.Region)
+	assert.Equal(t, "eu-west-1", config.AWS.DefaultRegion)
+	assert.Equal(t, "Developer", config.SSO.Role)
+}
+
+func TestInitFlags(t *testing.T) {
+	clearEnv(t)
+	configFile := filepath.Join(t.TempDir(), "config.toml")
+	args := []string{"--config", configFile, "--start-url", "https://acme.awsapps.com/start", "--sso-region", "eu-west-1",
+		"--region", "us-west-2", "--role", "Developer"}
+
+	ui := cli.NewMockUi()
+	require.Equal(t, 0, New(ui).Run(args), ui.ErrorWriter.String())
+	assert.NotContains(t, ui.OutputWriter.String(), "[", "nothing is asked")
+
+	config, err := appconfig.Load(configFile)
+	require.NoError(t, err)
+	assert.Equal(t, "us-west-2", config.AWS.DefaultRegion)
+
+	ui = cli.NewMockUi()
+	assert.Equal(t, 1, New(ui).Run(args))
+	assert.Contains(t, ui.ErrorWriter.String(), configFile+" already exists, use --force")
+
+	ui = cli.NewMockUi()
+	require.Equal(t, 0, New(ui).Run(append(args, "--force", "--role", "Admin")), ui.ErrorWriter.String())
+	config, err = appconfig.Load(configFile)
+	require.NoError(t, err)
+	assert.Equal(t, "Admin", config.SSO.Role)
+}
+
+func TestInitForceKeepsOtherSettings(t *testing.T) {
+	clearEnv(t)
+	configFile := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(configFile, []byte(`version = 1
+
+[sso]
+start_url = "https://old.awsapps.com/start"
+role = "Old"
+token_store = "keyring"
+credential_refresh_minutes = 10
+
+[contexts.client.sso]
+start_url = "https://client.awsapps.com/start"
+
+[[repos]]
+path = "~/src/payments/*"
+profile = "payments"
+
+[[generate.chains]]
+name = "deploy"
+source_role = "Developer"
+role_arn_template = "arn:aws:iam::{{.AccountId}}:role/Deploy"
+`), 0600))
+
+	ui := newUI("https://acme.awsapps.com/start", "", "", "Developer")
+	require.Equal(t, 0, New(ui).Run([]string{"--config", configFile, "--force"}), ui.ErrorWriter.String())
+	assert.Contains(t, ui.OutputWriter.String(), "Updated "+configFile)
+
+	config, err := appconfig.Load(configFile)
+	require.NoError(t, err)
+	assert.Equal(t, "https://acme.awsapps.com/start", config.SSO.StartURL)
+	assert.Equal(t, "Developer", config.SSO.Role)
+	assert.Equal(t, "keyring", config.SSO.TokenStore)
+	assert.Equal(t, 10, config.SSO.CredentialRefreshMinutes)
+	require.Len(t, config.Repos, 1)
+	require.Len(t, config.Generate.Chains, 1)
+	names, err := appconfig.NewConfigManager(configFile).Contexts()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"client"}, names)
+}
+
+func TestInitErrors(t *testing.T) {
+	clearEnv(t)
+	configFile := filepath.Join(t.TempDir(), "config.toml")
+
+	ui := cli.NewMockUi()
+	assert.Equal(t, 1, New(ui).Run([]string{"--config", configFile, "--sso-region", "narnia"}))
+	assert.Contains(t, ui.ErrorWriter.String(), `invalid value for sso.region: "narnia" is not an AWS region`)
+
+	ui = newUI("")
+	assert.Equal(t, 1, New(ui).Run([]string{"--config", configFile}))
+	assert.Contains(t, ui.ErrorWriter.String(), "A value is required")
+	assert.Contains(t, ui.ErrorWriter.String(), "Error reading answer: EOF")
+	assert.NoFileExists(t, configFile)
+}
+
+func TestInitOverExistingLayers(t *testing.T) {
+	clearEnv(t)
+	configFile := filepath.Join(t.TempDir(), "config.toml")
+	t.Setenv("AWS_SSO_CONFIG_SSO_START_URL", "https://env.awsapps.com/start")
+	ui := newUI("", "", "", "")
+
+	require.Equal(t, 0, New(ui).Run([]string{"--config", configFile}), ui.ErrorWriter.String())
+	assert.Contains(t, ui.OutputWriter.String(), "SSO start URL [https://env.awsapps.com/start]: ")
+	assert.Contains(t, ui.OutputWriter.String(), "Default region for generated profiles [us-east-1]: ")
+
+	data, err := os.ReadFile(configFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `start_url = "https://env.awsapps.com/start"`)
+}
+
+// loginRecorder records the configuration generate logs in with and stops it there
+type loginRecorder struct {
+	appCfg *appconfig.Config
+}
+
+func (r *loginRecorder) GenerateTokenWithConfig(_ context.Context, _ aws.Config, appCfg *appconfig.Config, _ awsprovider.LoginOptions) (awsprovider.Token, error) {
+	r.appCfg = appCfg
+	return awsprovider.Token{}, errors.New("stop after login")
+}
+
+// TestInitThenGenerate follows the instructions init prints: generate must log in with
+// the answers init wrote to ~/.awsssoconfig
+func TestInitThenGenerate(t *testing.T) {
+	clearEnv(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	homedir.DisableCache = true
+	t.Cleanup(func() { homedir.DisableCache = false })
+	t.Chdir(home)
+
+	ui := newUI("https://acme.awsapps.com/start", "eu-west-1", "", "Developer")
+	require.Equal(t, 0, New(ui).Run(nil), ui.ErrorWriter.String())
+	assert.Contains(t, ui.OutputWriter.String(), "Created "+filepath.Join(home, ".awsssoconfig"))
+	assert.Contains(t, ui.OutputWriter.String(), "Run aws-sso-config generate")
+
+	recorder := &loginRecorder{}
+	ui = cli.NewMockUi()
+	generate.NewWithDependencies(context.Background(), ui, nil, recorder, testutil.ConfigLoader).Run(nil)
+	require.NotNil(t, recorder.appCfg, ui.ErrorWriter.String())
+	assert.Equal(t, "https://acme.awsapps.com/start", recorder.appCfg.SSO.StartURL)
+	assert.Equal(t, "eu-west-1", recorder.appCfg.SSO.Region)
+	assert.Equal(t, "Developer", recorder.appCfg.SSO.Role)
+}
@@ -0,0 +1,21 @@
+package login
+
+const synopsis = "Log in to AWS SSO and cache the token"
+const help = `
+Usage: aws-sso-config login [options]
+
+  Log in to AWS SSO using the device authorization flow and cache the
+  access token in ~/.aws/sso/cache, where generate and the AWS CLI can
+  reuse it until it expires.
+
+Examples:
+
+  # Log in using the default configuration file
+  aws-sso-config login
+
+  # Log in using a custom config file
+  aws-sso-config login --config=my-config.toml
+
+  # Log in over SSH or in a container by entering a code on another device
+  aws-sso-config login --no-browser --qr
+`
@@ -0,0 +1,117 @@
+package login
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/mitchellh/cli"
+	"github.com/spf13/pflag"
+
+	awsprovider "github.com/blairham/aws-sso-config/providers/aws"
+	appconfig "github.com/blairham/aws-sso-config/providers/config"
+)
+
+// Authenticator interface for mocking
+type Authenticator interface {
+	Login(ctx context.Context, cfg aws.Config, appCfg *appconfig.Config, opts awsprovider.LoginOptions) (*awsprovider.SSOCacheEntry, error)
+}
+
+// DefaultAuthenticator implements Authenticator using the real AWS functions
+type DefaultAuthenticator struct{}
+
+func (a *DefaultAuthenticator) Login(
+	ctx context.Context,
+	cfg aws.Config,
+	appCfg *appconfig.Config,
+	opts awsprovider.LoginOptions,
+) (*awsprovider.SSOCacheEntry, error) {
+	return awsprovider.NewAWSProvider(cfg, opts).Login(ctx, appCfg)
+}
+
+type cmd struct {
+	UI    cli.Ui
+	ctx   context.Context
+	flags *pflag.FlagSet
+	help  string
+
+	configFile string
+	noBrowser  bool
+	qrCode     bool
+
+	// Dependencies for testing
+	authenticator Authenticator
+	configLoader  func(context.Context) (aws.Config, error)
+}
+
+func New(ctx context.Context, ui cli.Ui) *cmd {
+	return NewWithDependencies(ctx, ui, &DefaultAuthenticator{}, awsprovider.LoadDefaultConfig)
+}
+
+// NewWithDependencies creates a new command with injected dependencies for testing
+func NewWithDependencies(ctx context.Context, ui cli.Ui, authenticator Authenticator, configLoader func(context.Context) (aws.Config, error)) *cmd {
+	c := &cmd{UI: ui, ctx: ctx}
+	c.Init()
+	c.authenticator = authenticator
+	c.configLoader = configLoader
+	return c
+}
+
+func (c *cmd) Init() {
+	c.flags = pflag.NewFlagSet("login", pflag.ContinueOnError)
+	c.flags.StringVarP(&c.configFile, "config", "c", "", "Path to configuration file")
+	c.flags.BoolVar(&c.noBrowser, "no-browser", false, "Print the login code instead of opening a browser (auto-detected when unset)")
+	c.flags.BoolVar(&c.qrCode, "qr", false, "Also show the login URL as a terminal QR code")
+
+	c.help = help + "\n" + c.flags.FlagUsages()
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	appCfg, err := appconfig.Load(c.configFile)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Configuration error: %v", err))
+		return 1
+	}
+
+	if err := appCfg.Validate(); err != nil {
+		c.UI.Error(fmt.Sprintf("Configuration error: %v", err))
+		return 1
+	}
+
+	opts := awsprovider.DefaultLoginOptions()
+	if c.flags.Changed("no-browser") {
+		opts.NoBrowser = c.noBrowser
+	}
+	opts.QRCode = c.qrCode
+
+	cfg, err := c.configLoader(c.ctx)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	entry, err := c.authenticator.Login(c.ctx, cfg, appCfg, opts)
+	if err != nil {
+		c.UI.Error(err.Error())
+		if hint := awsprovider.ErrorHint(err); hint != "" {
+			c.UI.Error(hint)
+		}
+		return awsprovider.ExitCode(err)
+	}
+
+	c.UI.Output(fmt.Sprintf("Logged in to %s, token valid until %s", entry.StartURL, entry.ExpiresAt.Local().Format(time.RFC1123)))
+	return 0
+}
+
+func (c *cmd) Help() string {
+	return c.help
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
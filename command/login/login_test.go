@@ -0,0 +1,96 @@
+package login
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	awsprovider "github.com/blairham/aws-sso-config/providers/aws"
+	appconfig "github.com/blairham/aws-sso-config/providers/config"
+)
+
+// MockAuthenticator implements Authenticator for testing
+type MockAuthenticator struct {
+	err    error
+	opts   awsprovider.LoginOptions
+	appCfg *appconfig.Config
+}
+
+func (m *MockAuthenticator) Login(_ context.Context, cfg aws.Config, appCfg *appconfig.Config, opts awsprovider.LoginOptions) (*awsprovider.SSOCacheEntry, error) {
+	m.opts = opts
+	m.appCfg = appCfg
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &awsprovider.SSOCacheEntry{
+		StartURL:    appCfg.SSOStartURL(),
+		AccessToken: "mock-token",
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}, nil
+}
+
+func writeAppConfig(t *testing.T) string {
+	t.Helper()
+	configFile := filepath.Join(t.TempDir(), "app-config.toml")
+	content := `[sso]
+start_url = "https://test.awsapps.com/start"
+region = "us-west-2"
+`
+	require.NoError(t, os.WriteFile(configFile, []byte(content), 0600))
+	return configFile
+}
+
+func mockConfigLoader(context.Context) (aws.Config, error) {
+	return aws.Config{}, nil
+}
+
+func TestLoginSuccess(t *testing.T) {
+	ui := cli.NewMockUi()
+	auth := &MockAuthenticator{}
+	c := NewWithDependencies(context.Background(), ui, auth, mockConfigLoader)
+
+	exitCode := c.Run([]string{"--config=" + writeAppConfig(t), "--no-browser", "--qr"})
+
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, ui.OutputWriter.String(), "Logged in to https://test.awsapps.com/start")
+	assert.Equal(t, "us-west-2", auth.appCfg.SSORegion())
+	assert.True(t, auth.opts.NoBrowser)
+	assert.True(t, auth.opts.QRCode)
+}
+
+func TestLoginFailure(t *testing.T) {
+	ui := cli.NewMockUi()
+	c := NewWithDependencies(context.Background(), ui, &MockAuthenticator{err: errors.New("authorization denied")}, mockConfigLoader)
+
+	exitCode := c.Run([]string{"--config=" + writeAppConfig(t)})
+
+	assert.Equal(t, 1, exitCode)
+	assert.Contains(t, ui.ErrorWriter.String(), "authorization denied")
+}
+
+func TestLoginInvalidConfig(t *testing.T) {
+	ui := cli.NewMockUi()
+	configFile := filepath.Join(t.TempDir(), "invalid.toml")
+	require.NoError(t, os.WriteFile(configFile, []byte("invalid toml content: ["), 0600))
+
+	c := NewWithDependencies(context.Background(), ui, &MockAuthenticator{}, mockConfigLoader)
+
+	assert.Equal(t, 1, c.Run([]string{"--config=" + configFile}))
+	assert.Contains(t, ui.ErrorWriter.String(), "Configuration error")
+}
+
+func TestLoginHelp(t *testing.T) {
+	c := New(context.Background(), cli.NewMockUi())
+
+	assert.Contains(t, c.Help(), "Usage: aws-sso-config login")
+	assert.Contains(t, c.Help(), "--no-browser")
+	assert.Equal(t, synopsis, c.Synopsis())
+}
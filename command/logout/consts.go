@@ -0,0 +1,18 @@
+package logout
+
+const synopsis = "Log out of AWS SSO and remove the cached token"
+const help = `
+Usage: aws-sso-config logout [options]
+
+  Sign out of the AWS SSO portal, which invalidates the access token
+  and the role credentials issued with it, then remove the cached token
+  written by login.
+
+Examples:
+
+  # Log out of the start URL in the default configuration file
+  aws-sso-config logout
+
+  # Log out of the start URL in a custom config file
+  aws-sso-config logout --config=my-config.toml
+`
@@ -0,0 +1,145 @@
+package logout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sso"
+	"github.com/mitchellh/cli"
+	"github.com/spf13/pflag"
+
+	awsprovider "github.com/blairham/aws-sso-config/providers/aws"
+	appconfig "github.com/blairham/aws-sso-config/providers/config"
+)
+
+// Interface for the SSO logout operation to allow mocking in tests
+type LogoutClient interface {
+	Logout(ctx context.Context, params *sso.LogoutInput, optFns ...func(*sso.Options)) (*sso.LogoutOutput, error)
+}
+
+type cmd struct {
+	UI    cli.Ui
+	ctx   context.Context
+	flags *pflag.FlagSet
+	help  string
+
+	configFile string
+
+	// Dependencies for testing
+	clientFactory func(aws.Config) LogoutClient
+	configLoader  func(context.Context) (aws.Config, error)
+}
+
+func New(ctx context.Context, ui cli.Ui) *cmd {
+	clientFactory := func(cfg aws.Config) LogoutClient {
+		return sso.NewFromConfig(cfg)
+	}
+	return NewWithDependencies(ctx, ui, clientFactory, awsprovider.LoadDefaultConfig)
+}
+
+// NewWithDependencies creates a new command with injected dependencies for testing
+func NewWithDependencies(
+	ctx context.Context,
+	ui cli.Ui,
+	clientFactory func(aws.Config) LogoutClient,
+	configLoader func(context.Context) (aws.Config, error),
+) *cmd {
+	c := &cmd{UI: ui, ctx: ctx}
+	c.Init()
+	c.clientFactory = clientFactory
+	c.configLoader = configLoader
+	return c
+}
+
+func (c *cmd) Init() {
+	c.flags = pflag.NewFlagSet("logout", pflag.ContinueOnError)
+	c.flags.StringVarP(&c.configFile, "config", "c", "", "Path to configuration file")
+
+	c.help = help + "\n" + c.flags.FlagUsages()
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	appCfg, err := appconfig.Load(c.configFile)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Configuration error: %v", err))
+		return 1
+	}
+
+	store, err := awsprovider.NewTokenStore(appCfg.SSOTokenStore())
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Configuration error: %v", err))
+		return 1
+	}
+
+	startURL := appCfg.SSOStartURL()
+	c.clearRoleCredentials(startURL)
+
+	entry, err := store.Load(startURL)
+	if errors.Is(err, os.ErrNotExist) {
+		c.UI.Output(fmt.Sprintf("Not logged in to %s", startURL))
+		return 0
+	}
+	if err != nil {
+		c.UI.Warn(fmt.Sprintf("Ignoring unreadable token cache: %v", err))
+	}
+
+	// Only a token that is still valid can be signed out server side
+	if entry != nil && entry.Valid(time.Now()) {
+		if err := c.logout(appCfg, entry.AccessToken); err != nil {
+			c.UI.Warn(fmt.Sprintf("Failed to sign out of the SSO portal: %v", err))
+		}
+	}
+
+	if err := store.Delete(startURL); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	c.UI.Output(fmt.Sprintf("Logged out of %s", startURL))
+	return 0
+}
+
+// clearRoleCredentials removes role credentials cached through the start URL, which
+// would otherwise keep working until they expire
+func (c *cmd) clearRoleCredentials(startURL string) {
+	cache, err := awsprovider.NewRoleCredentialCache()
+	if err == nil {
+		err = cache.Clear(startURL)
+	}
+	if err != nil {
+		c.UI.Warn(fmt.Sprintf("Failed to remove cached role credentials: %v", err))
+	}
+}
+
+// logout invalidates the access token with the SSO portal in the configured SSO region
+func (c *cmd) logout(appCfg *appconfig.Config, accessToken string) error {
+	cfg, err := c.configLoader(c.ctx)
+	if err != nil {
+		return err
+	}
+	cfg.Region = appCfg.SSORegion()
+
+	ctx, cancel := context.WithTimeout(c.ctx, 30*time.Second)
+	defer cancel()
+
+	_, err = c.clientFactory(cfg).Logout(ctx, &sso.LogoutInput{
+		AccessToken: aws.String(accessToken),
+	})
+	return err
+}
+
+func (c *cmd) Help() string {
+	return c.help
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
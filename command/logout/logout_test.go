@@ -0,0 +1,135 @@
+package logout
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sso"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	awsprovider "github.com/blairham/aws-sso-config/providers/aws"
+)
+
+const testStartURL = "https://test.awsapps.com/start"
+
+// Mock implementation of LogoutClient interface for testing
+type MockLogoutClient struct {
+	mock.Mock
+}
+
+func (m *MockLogoutClient) Logout(ctx context.Context, params *sso.LogoutInput, optFns ...func(*sso.Options)) (*sso.LogoutOutput, error) {
+	args := m.Called(ctx, params)
+	result := args.Get(0)
+	if result == nil {
+		return nil, args.Error(1)
+	}
+	return result.(*sso.LogoutOutput), args.Error(1)
+}
+
+func setup(t *testing.T, client LogoutClient) (*cli.MockUi, *cmd, string) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	configFile := filepath.Join(t.TempDir(), "app-config.toml")
+	content := "[sso]\nstart_url = \"" + testStartURL + "\"\nregion = \"eu-west-1\"\n"
+	require.NoError(t, os.WriteFile(configFile, []byte(content), 0600))
+
+	ui := cli.NewMockUi()
+	c := NewWithDependencies(context.Background(), ui, func(cfg aws.Config) LogoutClient {
+		assert.Equal(t, "eu-west-1", cfg.Region, "logout should use the SSO region")
+		return client
+	}, func(context.Context) (aws.Config, error) { return aws.Config{Region: "us-east-1"}, nil })
+
+	return ui, c, configFile
+}
+
+func TestLogoutSignsOutAndRemovesCache(t *testing.T) {
+	client := new(MockLogoutClient)
+	client.On("Logout", mock.Anything, mock.MatchedBy(func(input *sso.LogoutInput) bool {
+		return aws.ToString(input.AccessToken) == "cached-token"
+	})).Return(&sso.LogoutOutput{}, nil)
+
+	ui, c, configFile := setup(t, client)
+	require.NoError(t, awsprovider.SaveCacheEntry(&awsprovider.SSOCacheEntry{
+		StartURL:    testStartURL,
+		AccessToken: "cached-token",
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}))
+
+	assert.Equal(t, 0, c.Run([]string{"--config=" + configFile}))
+	assert.Contains(t, ui.OutputWriter.String(), "Logged out of "+testStartURL)
+
+	_, err := awsprovider.LoadCacheEntry(testStartURL)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	client.AssertExpectations(t)
+}
+
+func TestLogoutRemovesCacheWhenSignOutFails(t *testing.T) {
+	client := new(MockLogoutClient)
+	client.On("Logout", mock.Anything, mock.Anything).Return(nil, errors.New("network down"))
+
+	ui, c, configFile := setup(t, client)
+	require.NoError(t, awsprovider.SaveCacheEntry(&awsprovider.SSOCacheEntry{
+		StartURL:    testStartURL,
+		AccessToken: "cached-token",
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}))
+
+	assert.Equal(t, 0, c.Run([]string{"--config=" + configFile}))
+	assert.Contains(t, ui.ErrorWriter.String(), "network down")
+
+	_, err := awsprovider.LoadCacheEntry(testStartURL)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestLogoutSkipsSignOutForExpiredToken(t *testing.T) {
+	client := new(MockLogoutClient)
+
+	_, c, configFile := setup(t, client)
+	require.NoError(t, awsprovider.SaveCacheEntry(&awsprovider.SSOCacheEntry{
+		StartURL:    testStartURL,
+		AccessToken: "expired-token",
+		ExpiresAt:   time.Now().Add(-time.Hour),
+	}))
+
+	assert.Equal(t, 0, c.Run([]string{"--config=" + configFile}))
+	client.AssertNotCalled(t, "Logout", mock.Anything, mock.Anything)
+}
+
+func TestLogoutWhenNotLoggedIn(t *testing.T) {
+	client := new(MockLogoutClient)
+	ui, c, configFile := setup(t, client)
+
+	assert.Equal(t, 0, c.Run([]string{"--config=" + configFile}))
+	assert.Contains(t, ui.OutputWriter.String(), "Not logged in")
+	client.AssertNotCalled(t, "Logout", mock.Anything, mock.Anything)
+}
+
+func TestLogoutHelp(t *testing.T) {
+	c := New(context.Background(), cli.NewMockUi())
+
+	assert.Contains(t, c.Help(), "Usage: aws-sso-config logout")
+	assert.Equal(t, synopsis, c.Synopsis())
+}
+
+func TestLogoutClearsRoleCredentials(t *testing.T) {
+	ui, c, configFile := setup(t, new(MockLogoutClient))
+	cache, err := awsprovider.NewRoleCredentialCache()
+	require.NoError(t, err)
+	target := &awsprovider.RoleTarget{StartURL: testStartURL, AccountID: "123456789012", RoleName: "Developer"}
+	require.NoError(t, cache.Save(target, &awsprovider.RoleCredentials{AccessKeyID: "AKIAEXAMPLE", Expiration: time.Now().Add(time.Hour)}))
+
+	assert.Equal(t, 0, c.Run([]string{"--config=" + configFile}))
+	assert.Contains(t, ui.OutputWriter.String(), "Not logged in")
+
+	_, err = cache.Load(target, 0)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -1,13 +1,26 @@
 package command
 
 import (
+	"context"
 	"fmt"
 
 	mcli "github.com/mitchellh/cli"
 
 	"github.com/blairham/aws-sso-config/command/cli"
 	"github.com/blairham/aws-sso-config/command/config"
+	"github.com/blairham/aws-sso-config/command/configcontext"
+	"github.com/blairham/aws-sso-config/command/console"
+	"github.com/blairham/aws-sso-config/command/credentials"
+	"github.com/blairham/aws-sso-config/command/exec"
 	"github.com/blairham/aws-sso-config/command/generate"
+	"github.com/blairham/aws-sso-config/command/initconfig"
+	"github.com/blairham/aws-sso-config/command/login"
+	"github.com/blairham/aws-sso-config/command/logout"
+	"github.com/blairham/aws-sso-config/command/serve"
+	"github.com/blairham/aws-sso-config/command/shellinit"
+	"github.com/blairham/aws-sso-config/command/status"
+	"github.com/blairham/aws-sso-config/command/switchprofile"
+	"github.com/blairham/aws-sso-config/command/which"
 )
 
 // factory is a function that returns a new instance of a CLI-sub command.
@@ -19,12 +32,26 @@ type entry struct {
 	fn   factory
 }
 
-func RegisteredCommands(ui cli.UI) map[string]mcli.CommandFactory {
+// RegisteredCommands returns the command factories; ctx is cancelled when the process is interrupted
+func RegisteredCommands(ctx context.Context, ui cli.UI) map[string]mcli.CommandFactory {
 	registry := map[string]mcli.CommandFactory{}
 	registerCommands(ui, registry,
 		// Add new commands here
-		entry{"config", func(ui cli.UI) (cli.Command, error) { return config.New(ui), nil }},
-		entry{"generate", func(ui cli.UI) (cli.Command, error) { return generate.New(ui), nil }},
+		entry{"config", func(ui cli.UI) (cli.Command, error) { return config.NewWithContext(ctx, ui), nil }},
+		entry{"console", func(ui cli.UI) (cli.Command, error) { return console.New(ctx, ui), nil }},
+		entry{"context", func(ui cli.UI) (cli.Command, error) { return configcontext.New(ui), nil }},
+		entry{"credentials", func(ui cli.UI) (cli.Command, error) { return credentials.New(ctx, ui), nil }},
+		entry{"exec", func(ui cli.UI) (cli.Command, error) { return exec.New(ctx, ui), nil }},
+		entry{"generate", func(ui cli.UI) (cli.Command, error) { return generate.New(ctx, ui), nil }},
+		entry{"init", func(ui cli.UI) (cli.Command, error) { return initconfig.New(ui), nil }},
+		entry{"login", func(ui cli.UI) (cli.Command, error) { return login.New(ctx, ui), nil }},
+		entry{"logout", func(ui cli.UI) (cli.Command, error) { return logout.New(ctx, ui), nil }},
+		entry{"serve", func(ui cli.UI) (cli.Command, error) { return serve.New(ctx, ui), nil }},
+		// shell-init generates completions from the registry itself
+		entry{"shell-init", func(ui cli.UI) (cli.Command, error) { return shellinit.New(ui, registry), nil }},
+		entry{"status", func(ui cli.UI) (cli.Command, error) { return status.New(ui), nil }},
+		entry{"switch", func(ui cli.UI) (cli.Command, error) { return switchprofile.New(ui), nil }},
+		entry{"which", func(ui cli.UI) (cli.Command, error) { return which.New(ui), nil }},
 	)
 
 	return registry
@@ -2,6 +2,7 @@ package command
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"testing"
 
@@ -85,12 +86,24 @@ func (m *mockCommand) Synopsis() string {
 
 func TestRegisteredCommands(t *testing.T) {
 	ui := newMockUI()
-	commands := RegisteredCommands(ui)
+	commands := RegisteredCommands(context.Background(), ui)
 
 	// Test that all expected commands are registered
 	expectedCommands := []string{
 		"config",
+		"console",
+		"context",
+		"credentials",
+		"exec",
 		"generate",
+		"init",
+		"login",
+		"logout",
+		"serve",
+		"shell-init",
+		"status",
+		"switch",
+		"which",
 	}
 
 	for _, expectedCmd := range expectedCommands {
@@ -107,7 +120,7 @@ func TestRegisteredCommands(t *testing.T) {
 
 func TestCommandFactories(t *testing.T) {
 	ui := newMockUI()
-	commands := RegisteredCommands(ui)
+	commands := RegisteredCommands(context.Background(), ui)
 
 	// Test each command can be created successfully
 	for cmdName, factory := range commands {
@@ -125,7 +138,7 @@ func TestCommandFactories(t *testing.T) {
 
 func TestCommandUniqueness(t *testing.T) {
 	ui := newMockUI()
-	commands := RegisteredCommands(ui)
+	commands := RegisteredCommands(context.Background(), ui)
 
 	// Test that we don't have duplicate command names
 	seen := make(map[string]bool)
@@ -140,7 +153,7 @@ func TestRegisterCommandsDuplicatePanic(t *testing.T) {
 	// to make it testable for duplicate detection. For now, we test
 	// that the RegisteredCommands function returns unique commands.
 	ui := newMockUI()
-	commands := RegisteredCommands(ui)
+	commands := RegisteredCommands(context.Background(), ui)
 
 	// Verify no duplicate command names
 	seen := make(map[string]bool)
@@ -170,7 +183,7 @@ func TestRegistryEntryType(t *testing.T) {
 
 func TestCommandHelp(t *testing.T) {
 	ui := newMockUI()
-	commands := RegisteredCommands(ui)
+	commands := RegisteredCommands(context.Background(), ui)
 
 	// Test that help contains useful information
 	for cmdName, factory := range commands {
@@ -193,7 +206,7 @@ func TestCommandHelp(t *testing.T) {
 
 func TestSpecificCommands(t *testing.T) {
 	ui := newMockUI()
-	commands := RegisteredCommands(ui)
+	commands := RegisteredCommands(context.Background(), ui)
 
 	tests := []struct {
 		name         string
@@ -223,3 +236,16 @@ func TestSpecificCommands(t *testing.T) {
 		})
 	}
 }
+
+func TestShellInitCompletesRegisteredCommands(t *testing.T) {
+	ui := newMockUI()
+	commands := RegisteredCommands(context.Background(), ui)
+
+	cmd, err := commands["shell-init"]()
+	require.NoError(t, err)
+	require.Equal(t, 0, cmd.Run([]string{"fish"}))
+
+	for cmdName := range commands {
+		assert.Contains(t, ui.stdout.String(), "-a '"+cmdName+"'", "Completions should offer %s", cmdName)
+	}
+}
@@ -0,0 +1,37 @@
+package serve
+
+const synopsis = "Serve role credentials to local containers and services"
+const help = `
+Usage: aws-sso-config serve [options]
+
+  Serve short-lived credentials for an SSO role over HTTP, for Docker
+  containers and long-running services that cannot log in themselves. The
+  role is read from an SSO profile in the AWS config file with --profile, or
+  given directly with --account and --role.
+
+  Credentials are served with the container credentials provider protocol.
+  Point the AWS SDKs and CLI at the server with the two variables printed
+  on startup:
+
+    AWS_CONTAINER_CREDENTIALS_FULL_URI   the credentials URL
+    AWS_CONTAINER_AUTHORIZATION_TOKEN    the token every request must send
+
+  With --imds the server also emulates the EC2 instance metadata service
+  (IMDSv2), for tools that only read credentials from IMDS. Set
+  AWS_EC2_METADATA_SERVICE_ENDPOINT to the server's URL to use it. IMDS has
+  no authorization token: any local process that can reach the server can
+  read the credentials, so --imds needs a loopback --listen address.
+
+  Credentials are refreshed in the background before they expire. The
+  server stops on interrupt.
+
+Examples:
+
+  # Serve a profile on a fixed port
+  aws-sso-config serve --profile my-account --listen 127.0.0.1:9911
+
+  # Share the credentials with a container on the host network
+  docker run --network host \
+    -e AWS_CONTAINER_CREDENTIALS_FULL_URI -e AWS_CONTAINER_AUTHORIZATION_TOKEN \
+    amazon/aws-cli sts get-caller-identity
+`
@@ -0,0 +1,222 @@
+package serve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/mitchellh/cli"
+	"github.com/spf13/pflag"
+
+	awsprovider "github.com/blairham/aws-sso-config/providers/aws"
+	appconfig "github.com/blairham/aws-sso-config/providers/config"
+)
+
+// shutdownTimeout bounds how long in-flight requests may take once the server is stopped
+const shutdownTimeout = 5 * time.Second
+
+type cmd struct {
+	UI    cli.Ui
+	ctx   context.Context
+	flags *pflag.FlagSet
+	help  string
+
+	configFile string
+	profile    string
+	account    string
+	role       string
+	region     string
+	listen     string
+	authToken  string
+	imds       bool
+	noBrowser  bool
+	qrCode     bool
+
+	// Dependencies for testing
+	newFetcher   func(aws.Config, awsprovider.LoginOptions) *awsprovider.CredentialFetcher
+	configLoader func(context.Context) (aws.Config, error)
+	// ready is called with the listener's address once the server accepts connections
+	ready func(addr string)
+}
+
+func New(ctx context.Context, ui cli.Ui) *cmd {
+	return NewWithDependencies(ctx, ui, awsprovider.NewCredentialFetcher, awsprovider.LoadDefaultConfig)
+}
+
+// NewWithDependencies creates a new command with injected dependencies for testing
+func NewWithDependencies(
+	ctx context.Context,
+	ui cli.Ui,
+	newFetcher func(aws.Config, awsprovider.LoginOptions) *awsprovider.CredentialFetcher,
+	configLoader func(context.Context) (aws.Config, error),
+) *cmd {
+	c := &cmd{UI: ui, ctx: ctx}
+	c.Init()
+	c.newFetcher = newFetcher
+	c.configLoader = configLoader
+	c.ready = func(string) {}
+	return c
+}
+
+func (c *cmd) Init() {
+	c.flags = pflag.NewFlagSet("serve", pflag.ContinueOnError)
+	c.flags.StringVarP(&c.configFile, "config", "c", "", "Path to configuration file")
+	c.flags.StringVarP(&c.profile, "profile", "p", "", "SSO profile in the AWS config file")
+	c.flags.StringVar(&c.account, "account", "", "Account ID, instead of --profile")
+	c.flags.StringVar(&c.role, "role", "", "Role name, used with --account (defaults to sso.role)")
+	c.flags.StringVar(&c.region, "region", "", "Region reported by IMDS, overrides the profile's region")
+	c.flags.StringVarP(&c.listen, "listen", "l", "127.0.0.1:0", "Address to listen on, port 0 picks a free port")
+	c.flags.StringVar(&c.authToken, "auth-token", "", "Authorization token clients must send (random when unset)")
+	c.flags.BoolVar(&c.imds, "imds", false, "Also emulate the EC2 instance metadata service (IMDSv2)")
+	c.flags.BoolVar(&c.noBrowser, "no-browser", false, "Print the login code instead of opening a browser (auto-detected when unset)")
+	c.flags.BoolVar(&c.qrCode, "qr", false, "Also show the login URL as a terminal QR code")
+
+	c.help = help + "\n" + c.flags.FlagUsages()
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	appCfg, err := appconfig.Load(c.configFile)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Configuration error: %v", err))
+		return 1
+	}
+
+	target, err := awsprovider.ResolveTarget(appCfg, c.profile, c.account, c.role)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+	if c.region != "" {
+		target.Region = c.region
+	}
+
+	authToken := c.authToken
+	if authToken == "" {
+		if authToken, err = randomToken(); err != nil {
+			c.UI.Error(fmt.Sprintf("Failed to generate an authorization token: %v", err))
+			return 1
+		}
+	}
+
+	cfg, err := c.configLoader(c.ctx)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+	fetcher := c.newFetcher(cfg, c.loginOptions())
+	fetch := func(ctx context.Context) (*awsprovider.RoleCredentials, error) {
+		return fetcher.Fetch(ctx, target, appCfg)
+	}
+
+	// The first fetch may need an interactive login, do it before serving
+	creds, err := fetch(c.ctx)
+	if err != nil {
+		c.UI.Error(err.Error())
+		if hint := awsprovider.ErrorHint(err); hint != "" {
+			c.UI.Error(hint)
+		}
+		return awsprovider.ExitCode(err)
+	}
+
+	server := newCredentialServer(authToken, target, c.imds)
+	server.set(creds, nil)
+
+	return c.serve(server, fetch, time.Duration(appCfg.SSOCredentialRefreshMinutes())*time.Minute)
+}
+
+// serve runs the HTTP server and the background refresh until the command's context is done
+func (c *cmd) serve(server *credentialServer, fetch func(context.Context) (*awsprovider.RoleCredentials, error), window time.Duration) int {
+	listener, err := net.Listen("tcp", c.listen)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Failed to listen on %s: %v", c.listen, err))
+		return 1
+	}
+
+	addr := listener.Addr().(*net.TCPAddr)
+	// IMDS clients cannot send the authorization token, so IMDS is only served locally
+	if server.imds && !addr.IP.IsLoopback() {
+		_ = listener.Close()
+		c.UI.Error(fmt.Sprintf("--imds needs a loopback address to listen on, %s is reachable from other hosts", addr))
+		return 1
+	}
+	if !addr.IP.IsLoopback() {
+		c.UI.Warn(fmt.Sprintf("Warning: %s is reachable from other hosts, anyone with the authorization token can read the credentials", addr))
+	}
+
+	baseURL := "http://" + addr.String()
+	c.UI.Info(fmt.Sprintf("Serving credentials for %s on %s", c.targetName(), baseURL))
+	c.UI.Output(fmt.Sprintf("export AWS_CONTAINER_CREDENTIALS_FULL_URI=%s%s", baseURL, credentialsPath))
+	c.UI.Output(fmt.Sprintf("export AWS_CONTAINER_AUTHORIZATION_TOKEN=%s", server.authToken))
+	if server.imds {
+		c.UI.Output(fmt.Sprintf("export AWS_EC2_METADATA_SERVICE_ENDPOINT=%s/", baseURL))
+	}
+
+	ctx, cancel := context.WithCancel(c.ctx)
+	defer cancel()
+
+	refreshDone := make(chan struct{})
+	go func() {
+		defer close(refreshDone)
+		server.refresh(ctx, fetch, window, time.After, func(err error) {
+			c.UI.Warn(fmt.Sprintf("Failed to refresh credentials, retrying in %s: %v", retryInterval, err))
+		})
+	}()
+
+	httpServer := &http.Server{Handler: server.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- httpServer.Serve(listener) }()
+	c.ready(addr.String())
+
+	exitCode := 0
+	select {
+	case <-ctx.Done():
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			c.UI.Error(fmt.Sprintf("Server error: %v", err))
+			exitCode = 1
+		}
+	}
+
+	cancel()
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer shutdownCancel()
+	_ = httpServer.Shutdown(shutdownCtx)
+	<-refreshDone
+
+	return exitCode
+}
+
+func (c *cmd) targetName() string {
+	if c.profile != "" {
+		return "profile " + c.profile
+	}
+	return "account " + c.account
+}
+
+// loginOptions keeps login prompts off stdout, which carries the export lines
+func (c *cmd) loginOptions() awsprovider.LoginOptions {
+	opts := awsprovider.DefaultLoginOptions()
+	if c.flags.Changed("no-browser") {
+		opts.NoBrowser = c.noBrowser
+	}
+	opts.QRCode = c.qrCode
+	opts.Out = os.Stderr
+	return opts
+}
+
+func (c *cmd) Help() string {
+	return c.help
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
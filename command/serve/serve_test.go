@@ -0,0 +1,126 @@
+package serve
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/endpointcreds"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blairham/aws-sso-config/internal/testutil"
+	awsprovider "github.com/blairham/aws-sso-config/providers/aws"
+	appconfig "github.com/blairham/aws-sso-config/providers/config"
+)
+
+// exportValue returns the value of an export line printed by the command
+func exportValue(t *testing.T, output, name string) string {
+	t.Helper()
+	for _, line := range strings.Split(output, "\n") {
+		if value, ok := strings.CutPrefix(line, "export "+name+"="); ok {
+			return value
+		}
+	}
+	t.Fatalf("%s not found in output:\n%s", name, output)
+	return ""
+}
+
+func TestServe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ui := cli.NewMockUi()
+	c := NewWithDependencies(ctx, ui, testutil.StubFetcher, testutil.ConfigLoader)
+
+	var retrieveErr error
+	var accessKeyID string
+	c.ready = func(string) {
+		defer cancel()
+		output := ui.OutputWriter.String()
+		provider := endpointcreds.New(exportValue(t, output, "AWS_CONTAINER_CREDENTIALS_FULL_URI"), func(o *endpointcreds.Options) {
+			o.AuthorizationToken = exportValue(t, output, "AWS_CONTAINER_AUTHORIZATION_TOKEN")
+		})
+		creds, err := provider.Retrieve(context.Background())
+		retrieveErr = err
+		accessKeyID = creds.AccessKeyID
+	}
+
+	exitCode := c.Run([]string{"--config", testutil.WriteConfigs(t), "--profile", "dev", "--imds"})
+
+	assert.Equal(t, 0, exitCode, ui.ErrorWriter.String())
+	require.NoError(t, retrieveErr)
+	assert.Equal(t, "AKIAEXAMPLE", accessKeyID)
+	assert.Contains(t, ui.OutputWriter.String(), "Serving credentials for profile dev on http://127.0.0.1:")
+	assert.Contains(t, ui.OutputWriter.String(), "export AWS_EC2_METADATA_SERVICE_ENDPOINT=http://127.0.0.1:")
+	assert.NotContains(t, ui.ErrorWriter.String(), "reachable from other hosts")
+}
+
+func TestServeFixedAuthToken(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ui := cli.NewMockUi()
+	c := NewWithDependencies(ctx, ui, testutil.StubFetcher, testutil.ConfigLoader)
+	c.ready = func(string) { cancel() }
+
+	assert.Equal(t, 0, c.Run([]string{"--config", testutil.WriteConfigs(t), "--profile", "dev", "--auth-token", "fixed-token"}))
+	assert.Contains(t, ui.OutputWriter.String(), "export AWS_CONTAINER_AUTHORIZATION_TOKEN=fixed-token\n")
+	assert.NotContains(t, ui.OutputWriter.String(), "AWS_EC2_METADATA_SERVICE_ENDPOINT")
+}
+
+func TestServeErrors(t *testing.T) {
+	t.Run("requires a profile or account", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		c := NewWithDependencies(context.Background(), ui, testutil.StubFetcher, testutil.ConfigLoader)
+
+		assert.Equal(t, 1, c.Run([]string{"--config", testutil.WriteConfigs(t)}))
+		assert.Contains(t, ui.ErrorWriter.String(), "either --profile or --account is required")
+	})
+
+	t.Run("login errors map to exit codes", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		failing := func(aws.Config, awsprovider.LoginOptions) *awsprovider.CredentialFetcher {
+			return &awsprovider.CredentialFetcher{
+				Token: func(context.Context, *appconfig.Config) (awsprovider.Token, error) {
+					return awsprovider.Token{}, awsprovider.ErrAuthorizationExpired
+				},
+			}
+		}
+		c := NewWithDependencies(context.Background(), ui, failing, testutil.ConfigLoader)
+
+		assert.Equal(t, awsprovider.ExitCodeAuthorizationExpired, c.Run([]string{"--config", testutil.WriteConfigs(t), "--profile", "dev"}))
+	})
+
+	t.Run("invalid listen address", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		c := NewWithDependencies(context.Background(), ui, testutil.StubFetcher, testutil.ConfigLoader)
+
+		assert.Equal(t, 1, c.Run([]string{"--config", testutil.WriteConfigs(t), "--profile", "dev", "--listen", "not-an-address"}))
+		assert.Contains(t, ui.ErrorWriter.String(), "Failed to listen on not-an-address")
+	})
+
+	t.Run("imds on a non-loopback address", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		c := NewWithDependencies(context.Background(), ui, testutil.StubFetcher, testutil.ConfigLoader)
+		served := false
+		c.ready = func(string) { served = true }
+
+		args := []string{"--config", testutil.WriteConfigs(t), "--profile", "dev", "--imds", "--listen", "0.0.0.0:0"}
+		assert.Equal(t, 1, c.Run(args))
+		assert.False(t, served, "no credentials are served")
+		assert.NotContains(t, ui.OutputWriter.String(), "export")
+		assert.Contains(t, ui.ErrorWriter.String(), "--imds needs a loopback address to listen on")
+	})
+}
+
+func TestServeHelp(t *testing.T) {
+	c := New(context.Background(), cli.NewMockUi())
+
+	assert.Contains(t, c.Help(), "Usage: aws-sso-config serve")
+	assert.Contains(t, c.Help(), "AWS_CONTAINER_CREDENTIALS_FULL_URI")
+	assert.Contains(t, c.Help(), "--imds")
+	assert.Equal(t, synopsis, c.Synopsis())
+}
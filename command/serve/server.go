@@ -0,0 +1,308 @@
+package serve
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	awsprovider "github.com/blairham/aws-sso-config/providers/aws"
+)
+
+const (
+	// credentialsPath is where the container credentials provider protocol is served
+	credentialsPath = "/credentials"
+
+	imdsTokenPath       = "/latest/api/token"
+	imdsCredentialsPath = "/latest/meta-data/iam/security-credentials/"
+	imdsRegionPath      = "/latest/meta-data/placement/region"
+	imdsIdentityPath    = "/latest/dynamic/instance-identity/document"
+	imdsTokenHeader     = "X-aws-ec2-metadata-token"
+	imdsTokenTTLHeader  = "X-aws-ec2-metadata-token-ttl-seconds"
+	// imdsMaxTokenTTL is the longest session token lifetime IMDSv2 allows, six hours
+	imdsMaxTokenTTL = 21600
+
+	// retryInterval is how long the refresh loop waits after a failed refresh
+	retryInterval = 30 * time.Second
+)
+
+var errNoCredentials = errors.New("credentials have not been fetched yet")
+
+// credentialServer serves the current role credentials over the container credentials
+// provider protocol and, when enabled, an IMDSv2 emulation
+type credentialServer struct {
+	authToken string
+	accountID string
+	roleName  string
+	region    string
+	imds      bool
+	now       func() time.Time
+
+	mu         sync.RWMutex
+	creds      *awsprovider.RoleCredentials
+	err        error
+	imdsTokens map[string]time.Time
+}
+
+func newCredentialServer(authToken string, target *awsprovider.RoleTarget, imds bool) *credentialServer {
+	return &credentialServer{
+		authToken:  authToken,
+		accountID:  target.AccountID,
+		roleName:   target.RoleName,
+		region:     target.Region,
+		imds:       imds,
+		now:        time.Now,
+		imdsTokens: make(map[string]time.Time),
+	}
+}
+
+// Handler returns the HTTP handler for the enabled endpoints
+func (s *credentialServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+credentialsPath, s.handleContainerCredentials)
+	if s.imds {
+		mux.HandleFunc("PUT "+imdsTokenPath, s.handleIMDSToken)
+		mux.HandleFunc("GET "+imdsCredentialsPath, s.handleIMDSRoles)
+		mux.HandleFunc("GET "+imdsCredentialsPath+"{role}", s.handleIMDSCredentials)
+		mux.HandleFunc("GET "+imdsRegionPath, s.handleIMDSRegion)
+		mux.HandleFunc("GET "+imdsIdentityPath, s.handleIMDSIdentity)
+	}
+	return mux
+}
+
+// set replaces the served credentials, or records why they could not be refreshed
+func (s *credentialServer) set(creds *awsprovider.RoleCredentials, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err == nil {
+		s.creds = creds
+	}
+	s.err = err
+}
+
+// current returns credentials that have not expired yet
+func (s *credentialServer) current() (*awsprovider.RoleCredentials, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.creds != nil && s.now().Before(s.creds.Expiration) {
+		return s.creds, nil
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	return nil, errNoCredentials
+}
+
+func (s *credentialServer) handleContainerCredentials(w http.ResponseWriter, r *http.Request) {
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(s.authToken)) != 1 {
+		writeJSON(w, http.StatusUnauthorized, errorResponse{Code: "Unauthorized", Message: "invalid authorization token"})
+		return
+	}
+
+	creds, err := s.current()
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, errorResponse{Code: "CredentialsUnavailable", Message: err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, containerCredentials{
+		AccessKeyID:     creds.AccessKeyID,
+		SecretAccessKey: creds.SecretAccessKey,
+		Token:           creds.SessionToken,
+		Expiration:      creds.Expiration.UTC().Format(time.RFC3339),
+	})
+}
+
+func (s *credentialServer) handleIMDSToken(w http.ResponseWriter, r *http.Request) {
+	// IMDSv2 refuses forwarded requests, so that an open proxy cannot be used to get a token
+	if r.Header.Get("X-Forwarded-For") != "" {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	ttl, err := strconv.Atoi(r.Header.Get(imdsTokenTTLHeader))
+	if err != nil || ttl < 1 || ttl > imdsMaxTokenTTL {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	s.mu.Lock()
+	now := s.now()
+	for t, expires := range s.imdsTokens {
+		if !now.Before(expires) {
+			delete(s.imdsTokens, t)
+		}
+	}
+	s.imdsTokens[token] = now.Add(time.Duration(ttl) * time.Second)
+	s.mu.Unlock()
+
+	w.Header().Set(imdsTokenTTLHeader, strconv.Itoa(ttl))
+	_, _ = w.Write([]byte(token))
+}
+
+// validIMDSToken rejects IMDSv1 requests and expired session tokens with 401, as IMDSv2 does
+func (s *credentialServer) validIMDSToken(w http.ResponseWriter, r *http.Request) bool {
+	s.mu.RLock()
+	expires, ok := s.imdsTokens[r.Header.Get(imdsTokenHeader)]
+	s.mu.RUnlock()
+
+	if !ok || !s.now().Before(expires) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+func (s *credentialServer) handleIMDSRoles(w http.ResponseWriter, r *http.Request) {
+	if !s.validIMDSToken(w, r) {
+		return
+	}
+	_, _ = w.Write([]byte(s.roleName))
+}
+
+func (s *credentialServer) handleIMDSCredentials(w http.ResponseWriter, r *http.Request) {
+	if !s.validIMDSToken(w, r) {
+		return
+	}
+	if r.PathValue("role") != s.roleName {
+		http.NotFound(w, r)
+		return
+	}
+
+	creds, err := s.current()
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, errorResponse{Code: "CredentialsUnavailable", Message: err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, imdsCredentials{
+		Code:            "Success",
+		LastUpdated:     s.now().UTC().Format(time.RFC3339),
+		Type:            "AWS-HMAC",
+		AccessKeyID:     creds.AccessKeyID,
+		SecretAccessKey: creds.SecretAccessKey,
+		Token:           creds.SessionToken,
+		Expiration:      creds.Expiration.UTC().Format(time.RFC3339),
+	})
+}
+
+func (s *credentialServer) handleIMDSRegion(w http.ResponseWriter, r *http.Request) {
+	if !s.validIMDSToken(w, r) {
+		return
+	}
+	_, _ = w.Write([]byte(s.region))
+}
+
+// handleIMDSIdentity serves the parts of the instance identity document that describe
+// the account and region, which the SDKs read the region from
+func (s *credentialServer) handleIMDSIdentity(w http.ResponseWriter, r *http.Request) {
+	if !s.validIMDSToken(w, r) {
+		return
+	}
+	writeJSON(w, http.StatusOK, imdsIdentity{AccountID: s.accountID, Region: s.region})
+}
+
+// refresh keeps the served credentials current until ctx is done. It fetches again when the
+// credentials are within window of expiring, and every retryInterval while fetching fails.
+func (s *credentialServer) refresh(
+	ctx context.Context,
+	fetch func(context.Context) (*awsprovider.RoleCredentials, error),
+	window time.Duration,
+	after func(time.Duration) <-chan time.Time,
+	onError func(error),
+) {
+	wait := s.nextRefresh(window)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-after(wait):
+		}
+
+		creds, err := fetch(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		s.set(creds, err)
+
+		if err != nil {
+			onError(err)
+			wait = retryInterval
+		} else {
+			wait = s.nextRefresh(window)
+		}
+	}
+}
+
+// nextRefresh returns how long the current credentials can be served before they are
+// within window of expiring, at least retryInterval so that short-lived credentials do not
+// cause a busy loop
+func (s *credentialServer) nextRefresh(window time.Duration) time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.creds == nil {
+		return 0
+	}
+	return max(s.creds.Expiration.Add(-window).Sub(s.now()), retryInterval)
+}
+
+// containerCredentials is the container credentials provider response
+type containerCredentials struct {
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string `json:"SecretAccessKey"`
+	Token           string `json:"Token"`
+	Expiration      string `json:"Expiration"`
+}
+
+// imdsCredentials is the IMDS security-credentials response
+type imdsCredentials struct {
+	Code            string `json:"Code"`
+	LastUpdated     string `json:"LastUpdated"`
+	Type            string `json:"Type"`
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string `json:"SecretAccessKey"`
+	Token           string `json:"Token"`
+	Expiration      string `json:"Expiration"`
+}
+
+// imdsIdentity is the subset of the instance identity document that applies to role credentials
+type imdsIdentity struct {
+	AccountID string `json:"accountId"`
+	Region    string `json:"region"`
+}
+
+// errorResponse is the error format the container credentials provider reports to callers
+type errorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// randomToken returns 32 random bytes, hex encoded
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,236 @@
+package serve
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/ec2rolecreds"
+	"github.com/aws/aws-sdk-go-v2/credentials/endpointcreds"
+	"github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	awsprovider "github.com/blairham/aws-sso-config/providers/aws"
+)
+
+const testAuthToken = "test-auth-token"
+
+var testTarget = &awsprovider.RoleTarget{AccountID: "123456789012", RoleName: "Developer", Region: "eu-west-1"}
+
+func testCredentials(expiration time.Time) *awsprovider.RoleCredentials {
+	return &awsprovider.RoleCredentials{
+		AccessKeyID:     "AKIAEXAMPLE",
+		SecretAccessKey: "secret",
+		SessionToken:    "session",
+		Expiration:      expiration,
+	}
+}
+
+func newTestServer(t *testing.T, imdsEnabled bool) (*credentialServer, *httptest.Server) {
+	t.Helper()
+	server := newCredentialServer(testAuthToken, testTarget, imdsEnabled)
+	server.set(testCredentials(time.Now().Add(time.Hour).Truncate(time.Second)), nil)
+
+	ts := httptest.NewServer(server.Handler())
+	t.Cleanup(ts.Close)
+	return server, ts
+}
+
+func TestContainerCredentials(t *testing.T) {
+	server, ts := newTestServer(t, false)
+
+	t.Run("SDK provider reads the credentials", func(t *testing.T) {
+		provider := endpointcreds.New(ts.URL+credentialsPath, func(o *endpointcreds.Options) {
+			o.AuthorizationToken = testAuthToken
+		})
+
+		creds, err := provider.Retrieve(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "AKIAEXAMPLE", creds.AccessKeyID)
+		assert.Equal(t, "secret", creds.SecretAccessKey)
+		assert.Equal(t, "session", creds.SessionToken)
+		assert.True(t, creds.CanExpire)
+		assert.Equal(t, server.creds.Expiration.Unix(), creds.Expires.Unix())
+	})
+
+	t.Run("wrong or missing token is rejected", func(t *testing.T) {
+		for _, token := range []string{"", "wrong-token"} {
+			req, err := http.NewRequest(http.MethodGet, ts.URL+credentialsPath, nil)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", token)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		}
+	})
+
+	t.Run("IMDS is disabled by default", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPut, ts.URL+imdsTokenPath, nil)
+		require.NoError(t, err)
+		req.Header.Set(imdsTokenTTLHeader, "60")
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestCredentialsUnavailable(t *testing.T) {
+	server := newCredentialServer(testAuthToken, testTarget, false)
+	ts := httptest.NewServer(server.Handler())
+	defer ts.Close()
+
+	provider := endpointcreds.New(ts.URL+credentialsPath, func(o *endpointcreds.Options) {
+		o.AuthorizationToken = testAuthToken
+		o.Retryer = aws.NopRetryer{}
+	})
+
+	server.set(nil, errors.New("SSO token expired"))
+	_, err := provider.Retrieve(context.Background())
+	assert.ErrorContains(t, err, "SSO token expired")
+
+	// Expired credentials are not served even though no newer error was recorded
+	server.set(testCredentials(time.Now().Add(-time.Minute)), nil)
+	_, err = provider.Retrieve(context.Background())
+	assert.Error(t, err)
+}
+
+func TestIMDSCredentials(t *testing.T) {
+	_, ts := newTestServer(t, true)
+	client := imds.New(imds.Options{Endpoint: ts.URL})
+
+	t.Run("SDK provider reads the credentials", func(t *testing.T) {
+		creds, err := ec2rolecreds.New(func(o *ec2rolecreds.Options) { o.Client = client }).Retrieve(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "AKIAEXAMPLE", creds.AccessKeyID)
+		assert.Equal(t, "session", creds.SessionToken)
+	})
+
+	t.Run("region", func(t *testing.T) {
+		out, err := client.GetRegion(context.Background(), nil)
+		require.NoError(t, err)
+		assert.Equal(t, "eu-west-1", out.Region)
+	})
+
+	t.Run("IMDSv1 requests are rejected", func(t *testing.T) {
+		resp, err := http.Get(ts.URL + imdsCredentialsPath)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("token requests are validated", func(t *testing.T) {
+		for name, headers := range map[string]map[string]string{
+			"missing ttl":   {},
+			"ttl too long":  {imdsTokenTTLHeader: "21601"},
+			"forwarded":     {imdsTokenTTLHeader: "60", "X-Forwarded-For": "203.0.113.1"},
+			"ttl not a num": {imdsTokenTTLHeader: "soon"},
+		} {
+			req, err := http.NewRequest(http.MethodPut, ts.URL+imdsTokenPath, nil)
+			require.NoError(t, err)
+			for k, v := range headers {
+				req.Header.Set(k, v)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.GreaterOrEqual(t, resp.StatusCode, 400, name)
+		}
+	})
+}
+
+func TestIMDSTokenExpiry(t *testing.T) {
+	server, ts := newTestServer(t, true)
+	now := time.Now()
+	server.now = func() time.Time { return now }
+
+	req, err := http.NewRequest(http.MethodPut, ts.URL+imdsTokenPath, nil)
+	require.NoError(t, err)
+	req.Header.Set(imdsTokenTTLHeader, "60")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	token := make([]byte, 64)
+	n, _ := resp.Body.Read(token)
+	resp.Body.Close()
+
+	get := func() int {
+		req, err := http.NewRequest(http.MethodGet, ts.URL+imdsCredentialsPath, nil)
+		require.NoError(t, err)
+		req.Header.Set(imdsTokenHeader, string(token[:n]))
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusOK, get())
+	now = now.Add(time.Minute)
+	assert.Equal(t, http.StatusUnauthorized, get())
+}
+
+func TestRefresh(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	server := newCredentialServer(testAuthToken, testTarget, false)
+	server.now = func() time.Time { return now }
+	server.set(testCredentials(now.Add(time.Hour)), nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	waits := make(chan time.Duration)
+	ticks := make(chan time.Time)
+	after := func(d time.Duration) <-chan time.Time {
+		waits <- d
+		return ticks
+	}
+
+	results := []error{errors.New("throttled"), nil}
+	fetches := 0
+	fetch := func(context.Context) (*awsprovider.RoleCredentials, error) {
+		err := results[fetches]
+		fetches++
+		if err != nil {
+			return nil, err
+		}
+		creds := testCredentials(now.Add(2 * time.Hour))
+		creds.AccessKeyID = "AKIANEW"
+		return creds, nil
+	}
+
+	var refreshErrs []error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		server.refresh(ctx, fetch, 5*time.Minute, after, func(err error) { refreshErrs = append(refreshErrs, err) })
+	}()
+
+	// Waits until the window before expiry
+	assert.Equal(t, 55*time.Minute, <-waits)
+	ticks <- now
+
+	// A failed refresh keeps serving the previous credentials and retries
+	assert.Equal(t, retryInterval, <-waits)
+	creds, err := server.current()
+	require.NoError(t, err)
+	assert.Equal(t, "AKIAEXAMPLE", creds.AccessKeyID)
+	ticks <- now
+
+	assert.Equal(t, 115*time.Minute, <-waits)
+	creds, err = server.current()
+	require.NoError(t, err)
+	assert.Equal(t, "AKIANEW", creds.AccessKeyID)
+
+	cancel()
+	<-done
+	assert.Len(t, refreshErrs, 1)
+	assert.Equal(t, 2, fetches)
+}
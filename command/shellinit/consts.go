@@ -0,0 +1,29 @@
+package shellinit
+
+const synopsis = "Print shell integration for bash, zsh or fish"
+const help = `
+Usage: aws-sso-config shell-init <bash|zsh|fish>
+
+  Print a script that integrates aws-sso-config with the shell. Evaluate
+  it from the shell's startup file. The script defines:
+
+    aws-sso-config          a wrapper function that applies the profile
+                            picked with 'switch' to the current shell
+    aws_sso_config_prompt   a prompt segment showing AWS_PROFILE and the
+                            minutes until the SSO token expires
+    completions             for the commands of this version
+
+  The prompt segment is not added to the prompt automatically, see the
+  comments in the script for how to use it.
+
+Examples:
+
+  # ~/.bashrc
+  eval "$(aws-sso-config shell-init bash)"
+
+  # ~/.zshrc
+  eval "$(aws-sso-config shell-init zsh)"
+
+  # ~/.config/fish/config.fish
+  aws-sso-config shell-init fish | source
+`
@@ -0,0 +1,117 @@
+package shellinit
+
+import "text/template"
+
+// The wrapper only evaluates the export line printed by switch, any other output such as
+// help or the --envrc message is passed through
+
+var bashTemplate = template.Must(template.New("bash").Parse(`# aws-sso-config shell integration for bash
+
+aws-sso-config() {
+  if [ "$1" = "switch" ]; then
+    local output status
+    output="$(command {{.Exe}} "$@")"
+    status=$?
+    case "$output" in
+      "export AWS_PROFILE="*) eval "$output" ;;
+      ?*) printf '%s\n' "$output" ;;
+    esac
+    return $status
+  fi
+  command {{.Exe}} "$@"
+}
+
+# Prompt segment showing the active profile and the minutes until the SSO token expires:
+#   PS1='$(aws_sso_config_prompt)'"$PS1"
+aws_sso_config_prompt() {
+  [ -n "$AWS_PROFILE" ] || return 0
+  local expiry
+  expiry="$(command {{.Exe}} status --output prompt 2>/dev/null)"
+  printf '(%s%s) ' "$AWS_PROFILE" "${expiry:+ $expiry}"
+}
+
+_aws_sso_config_complete() {
+  if [ "$COMP_CWORD" -eq 1 ]; then
+    COMPREPLY=($(compgen -W "{{.Names}}" -- "${COMP_WORDS[1]}"))
+  fi
+}
+complete -F _aws_sso_config_complete aws-sso-config
+`))
+
+var zshTemplate = template.Must(template.New("zsh").Parse(`# aws-sso-config shell integration for zsh
+
+aws-sso-config() {
+  if [[ "$1" == "switch" ]]; then
+    local output exit_status
+    output="$(command {{.Exe}} "$@")"
+    exit_status=$?
+    case "$output" in
+      "export AWS_PROFILE="*) eval "$output" ;;
+      ?*) printf '%s\n' "$output" ;;
+    esac
+    return $exit_status
+  fi
+  command {{.Exe}} "$@"
+}
+
+# Prompt segment showing the active profile and the minutes until the SSO token expires:
+#   setopt prompt_subst
+#   PROMPT='$(aws_sso_config_prompt)'"$PROMPT"
+aws_sso_config_prompt() {
+  [[ -n "$AWS_PROFILE" ]] || return 0
+  local expiry
+  expiry="$(command {{.Exe}} status --output prompt 2>/dev/null)"
+  printf '(%s%s) ' "$AWS_PROFILE" "${expiry:+ $expiry}"
+}
+
+_aws_sso_config() {
+  local -a commands
+  commands=(
+{{- range .Commands}}
+    {{.Zsh}}
+{{- end}}
+  )
+  if (( CURRENT == 2 )); then
+    _describe 'command' commands
+  fi
+}
+if (( $+functions[compdef] )); then
+  compdef _aws_sso_config aws-sso-config
+fi
+`))
+
+var fishTemplate = template.Must(template.New("fish").Parse(`# aws-sso-config shell integration for fish
+
+function aws-sso-config
+    if test "$argv[1]" = switch
+        set -l output (command {{.Exe}} switch --shell fish $argv[2..-1])
+        set -l exit_status $status
+        for line in $output
+            if string match -q 'set -gx AWS_PROFILE *' -- $line
+                eval $line
+            else
+                printf '%s\n' $line
+            end
+        end
+        return $exit_status
+    end
+    command {{.Exe}} $argv
+end
+
+# Prompt segment showing the active profile and the minutes until the SSO token expires:
+#   function fish_right_prompt; aws_sso_config_prompt; end
+function aws_sso_config_prompt
+    set -q AWS_PROFILE; or return 0
+    set -l expiry (command {{.Exe}} status --output prompt 2>/dev/null)
+    if test -n "$expiry"
+        printf '(%s %s) ' $AWS_PROFILE $expiry
+    else
+        printf '(%s) ' $AWS_PROFILE
+    end
+end
+
+complete -c aws-sso-config -f
+{{- range .Commands}}
+complete -c aws-sso-config -n __fish_use_subcommand -a {{.Fish}}
+{{- end}}
+`))
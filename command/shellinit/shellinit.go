@@ -0,0 +1,142 @@
+package shellinit
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/mitchellh/cli"
+)
+
+// templates holds the integration script of each supported shell
+var templates = map[string]*template.Template{
+	"bash": bashTemplate,
+	"zsh":  zshTemplate,
+	"fish": fishTemplate,
+}
+
+// completion is a command offered by the shell completions
+type completion struct {
+	Name     string
+	Synopsis string
+}
+
+// Zsh returns the command as a quoted _describe entry
+func (c completion) Zsh() string {
+	return shellQuote(strings.ReplaceAll(c.Name, ":", `\:`) + ":" + c.Synopsis)
+}
+
+// Fish returns the arguments of a fish complete command for the command
+func (c completion) Fish() string {
+	return fishQuote(c.Name) + " -d " + fishQuote(c.Synopsis)
+}
+
+// scriptData is passed to the shell templates
+type scriptData struct {
+	// Exe is the quoted path of the running binary
+	Exe      string
+	Commands []completion
+}
+
+// Names returns the command names separated by spaces, for compgen
+func (d scriptData) Names() string {
+	names := make([]string, len(d.Commands))
+	for i, c := range d.Commands {
+		names[i] = c.Name
+	}
+	return strings.Join(names, " ")
+}
+
+type cmd struct {
+	UI       cli.Ui
+	help     string
+	commands map[string]cli.CommandFactory
+
+	// Dependencies for testing
+	executable func() (string, error)
+}
+
+// New creates the command. commands is the registry the CLI runs, which the completions
+// are generated from.
+func New(ui cli.Ui, commands map[string]cli.CommandFactory) *cmd {
+	return &cmd{
+		UI:         ui,
+		help:       help,
+		commands:   commands,
+		executable: os.Executable,
+	}
+}
+
+func (c *cmd) Run(args []string) int {
+	if len(args) != 1 {
+		c.UI.Error("Usage: aws-sso-config shell-init <bash|zsh|fish>")
+		return 1
+	}
+
+	tmpl, ok := templates[args[0]]
+	if !ok {
+		c.UI.Error(fmt.Sprintf("Unsupported shell: %s (expected bash, zsh or fish)", args[0]))
+		return 1
+	}
+
+	exe, err := c.executable()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Failed to locate the aws-sso-config binary: %v", err))
+		return 1
+	}
+	quote := shellQuote
+	if args[0] == "fish" {
+		quote = fishQuote
+	}
+
+	completions, err := c.completions()
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	var script strings.Builder
+	if err := tmpl.Execute(&script, scriptData{Exe: quote(exe), Commands: completions}); err != nil {
+		c.UI.Error(fmt.Sprintf("Failed to render the %s script: %v", args[0], err))
+		return 1
+	}
+
+	c.UI.Output(strings.TrimSuffix(script.String(), "\n"))
+	return 0
+}
+
+// completions lists the registered commands with their synopsis, sorted by name
+func (c *cmd) completions() ([]completion, error) {
+	completions := make([]completion, 0, len(c.commands))
+	for name, factory := range c.commands {
+		command, err := factory()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load command %s: %w", name, err)
+		}
+		completions = append(completions, completion{Name: name, Synopsis: command.Synopsis()})
+	}
+
+	sort.Slice(completions, func(i, j int) bool {
+		return completions[i].Name < completions[j].Name
+	})
+	return completions, nil
+}
+
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+func fishQuote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return "'" + strings.ReplaceAll(value, "'", `\'`) + "'"
+}
+
+func (c *cmd) Help() string {
+	return c.help
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
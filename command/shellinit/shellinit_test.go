@@ -0,0 +1,148 @@
+package shellinit
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCommand is a registered command with a fixed synopsis
+type fakeCommand struct {
+	synopsis string
+}
+
+func (f *fakeCommand) Help() string     { return "" }
+func (f *fakeCommand) Run([]string) int { return 0 }
+func (f *fakeCommand) Synopsis() string { return f.synopsis }
+func factory(synopsis string) cli.CommandFactory {
+	return func() (cli.Command, error) { return &fakeCommand{synopsis: synopsis}, nil }
+}
+
+var testCommands = map[string]cli.CommandFactory{
+	"switch": factory("Pick a profile"),
+	"login":  factory("Log in to the SSO portal"),
+	"status": factory("Show the user's login status"),
+}
+
+// render runs shell-init for shell with exe as the path of the binary
+func render(t *testing.T, shell, exe string) string {
+	t.Helper()
+	ui := cli.NewMockUi()
+	c := New(ui, testCommands)
+	c.executable = func() (string, error) { return exe, nil }
+
+	require.Equal(t, 0, c.Run([]string{shell}), ui.ErrorWriter.String())
+	return ui.OutputWriter.String()
+}
+
+func TestShellInitScripts(t *testing.T) {
+	const exe = "/opt/aws sso/aws-sso-config"
+
+	bash := render(t, "bash", exe)
+	assert.Contains(t, bash, `command '/opt/aws sso/aws-sso-config' "$@"`)
+	assert.Contains(t, bash, "aws_sso_config_prompt()")
+	assert.Contains(t, bash, `compgen -W "login status switch"`)
+
+	zsh := render(t, "zsh", exe)
+	assert.Contains(t, zsh, `command '/opt/aws sso/aws-sso-config' "$@"`)
+	assert.Contains(t, zsh, `'login:Log in to the SSO portal'`)
+	assert.Contains(t, zsh, `'status:Show the user'\''s login status'`)
+	assert.Contains(t, zsh, "compdef _aws_sso_config aws-sso-config")
+
+	fish := render(t, "fish", exe)
+	assert.Contains(t, fish, "command '/opt/aws sso/aws-sso-config' $argv")
+	assert.Contains(t, fish, `complete -c aws-sso-config -n __fish_use_subcommand -a 'status' -d 'Show the user\'s login status'`)
+}
+
+func TestShellInitErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "no shell", args: nil, want: "Usage: aws-sso-config shell-init"},
+		{name: "too many arguments", args: []string{"bash", "zsh"}, want: "Usage: aws-sso-config shell-init"},
+		{name: "unsupported shell", args: []string{"powershell"}, want: "Unsupported shell: powershell"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ui := cli.NewMockUi()
+			c := New(ui, testCommands)
+
+			assert.Equal(t, 1, c.Run(tt.args))
+			assert.Contains(t, ui.ErrorWriter.String(), tt.want)
+		})
+	}
+}
+
+// fakeBinary writes a stand-in for aws-sso-config that answers switch and status --output prompt
+func fakeBinary(t *testing.T) string {
+	t.Helper()
+	exe := filepath.Join(t.TempDir(), "aws-sso-config")
+	script := `#!/bin/sh
+case "$1" in
+  switch)
+    shift
+    [ "$1" = "--shell" ] && shift 2
+    case "$1" in
+      --envrc) echo "Set AWS_PROFILE=staging in .envrc" ;;
+      "") exit 130 ;;
+      *) echo "export AWS_PROFILE='$1'" ;;
+    esac ;;
+  status) echo 42m ;;
+  *) echo "ran $*" ;;
+esac
+`
+	require.NoError(t, os.WriteFile(exe, []byte(script), 0700)) // #nosec G306 - test executable
+	return exe
+}
+
+func TestShellInitBash(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash is not installed")
+	}
+
+	script := render(t, "bash", fakeBinary(t))
+	test := script + `
+aws-sso-config switch staging
+echo "profile=$AWS_PROFILE"
+aws-sso-config switch --envrc staging
+aws-sso-config switch; echo "cancelled=$?"
+aws-sso-config login --no-browser
+echo "prompt=$(aws_sso_config_prompt)"
+COMP_WORDS=(aws-sso-config s); COMP_CWORD=1; _aws_sso_config_complete
+echo "completions=${COMPREPLY[*]}"
+`
+	output, err := exec.Command(bash, "--norc", "-c", test).CombinedOutput() // #nosec G204 - test script
+	require.NoError(t, err, string(output))
+
+	assert.Equal(t, []string{
+		"profile=staging",
+		"Set AWS_PROFILE=staging in .envrc",
+		"cancelled=130",
+		"ran login --no-browser",
+		"prompt=(staging 42m) ",
+		"completions=status switch",
+	}, strings.Split(strings.TrimSuffix(string(output), "\n"), "\n"))
+}
+
+func TestShellInitSyntax(t *testing.T) {
+	for _, shell := range []string{"zsh", "fish"} {
+		t.Run(shell, func(t *testing.T) {
+			path, err := exec.LookPath(shell)
+			if err != nil {
+				t.Skipf("%s is not installed", shell)
+			}
+			output, err := exec.Command(path, "-n", "-c", render(t, shell, "/usr/local/bin/aws-sso-config")).CombinedOutput() // #nosec G204 - test script
+			assert.NoError(t, err, string(output))
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package status
+
+const synopsis = "Show the AWS SSO login status"
+const help = `
+Usage: aws-sso-config status [options]
+
+  Show the cached AWS SSO session for the configured start URL: when the
+  access token expires, how long it remains valid, when the client
+  registration expires and whether a refresh token is available.
+
+  Exits with status 0 when a valid token is cached and 1 otherwise.
+
+Examples:
+
+  # Show the login status
+  aws-sso-config status
+
+  # Show the login status as JSON for scripts
+  aws-sso-config status --output json
+
+  # Print the minutes until the token expires, for shell prompts
+  aws-sso-config status --output prompt
+`
@@ -0,0 +1,185 @@
+package status
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mitchellh/cli"
+	"github.com/spf13/pflag"
+
+	awsprovider "github.com/blairham/aws-sso-config/providers/aws"
+	appconfig "github.com/blairham/aws-sso-config/providers/config"
+)
+
+const (
+	outputText   = "text"
+	outputJSON   = "json"
+	outputPrompt = "prompt"
+)
+
+// report describes the cached SSO session for a start URL
+type report struct {
+	StartURL              string    `json:"start_url"`
+	Region                string    `json:"region,omitempty"`
+	TokenStore            string    `json:"token_store"`
+	LoggedIn              bool      `json:"logged_in"`
+	ExpiresAt             time.Time `json:"expires_at,omitzero"`
+	RemainingSeconds      int64     `json:"remaining_seconds"`
+	RegistrationExpiresAt time.Time `json:"registration_expires_at,omitzero"`
+	HasRefreshToken       bool      `json:"has_refresh_token"`
+}
+
+type cmd struct {
+	UI    cli.Ui
+	flags *pflag.FlagSet
+	help  string
+
+	configFile string
+	output     string
+
+	// Dependencies for testing
+	now func() time.Time
+}
+
+func New(ui cli.Ui) *cmd {
+	c := &cmd{UI: ui, now: time.Now}
+	c.Init()
+	return c
+}
+
+func (c *cmd) Init() {
+	c.flags = pflag.NewFlagSet("status", pflag.ContinueOnError)
+	c.flags.StringVarP(&c.configFile, "config", "c", "", "Path to configuration file")
+	c.flags.StringVarP(&c.output, "output", "o", outputText, "Output format: text, json or prompt")
+
+	c.help = help + "\n" + c.flags.FlagUsages()
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	switch c.output {
+	case outputText, outputJSON, outputPrompt:
+	default:
+		c.UI.Error(fmt.Sprintf("Unsupported output format: %s (expected text, json or prompt)", c.output))
+		return 1
+	}
+
+	appCfg, err := appconfig.Load(c.configFile)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Configuration error: %v", err))
+		return 1
+	}
+
+	r, err := c.buildReport(appCfg)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	switch c.output {
+	case outputJSON:
+		data, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error encoding status: %v", err))
+			return 1
+		}
+		c.UI.Output(string(data))
+	case outputPrompt:
+		c.outputPrompt(r)
+	default:
+		c.outputText(r)
+	}
+
+	if !r.LoggedIn {
+		return 1
+	}
+	return 0
+}
+
+// buildReport reads the cached token for the configured start URL
+func (c *cmd) buildReport(appCfg *appconfig.Config) (*report, error) {
+	r := &report{StartURL: appCfg.SSOStartURL(), Region: appCfg.SSORegion(), TokenStore: appCfg.SSOTokenStore()}
+
+	store, err := awsprovider.NewTokenStore(r.TokenStore)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := store.Load(r.StartURL)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := c.now()
+	r.LoggedIn = entry.Valid(now)
+	r.ExpiresAt = entry.ExpiresAt
+	r.RegistrationExpiresAt = entry.RegistrationExpiresAt
+	r.HasRefreshToken = entry.RefreshToken != ""
+	if r.LoggedIn {
+		r.RemainingSeconds = int64(entry.ExpiresAt.Sub(now).Seconds())
+	}
+	if entry.Region != "" {
+		r.Region = entry.Region
+	}
+
+	return r, nil
+}
+
+func (c *cmd) outputText(r *report) {
+	c.UI.Output(fmt.Sprintf("Start URL:            %s", r.StartURL))
+	c.UI.Output(fmt.Sprintf("Region:               %s", r.Region))
+	c.UI.Output(fmt.Sprintf("Token store:          %s", r.TokenStore))
+
+	switch {
+	case r.ExpiresAt.IsZero():
+		c.UI.Output("Token:                not logged in")
+	case r.LoggedIn:
+		remaining := (time.Duration(r.RemainingSeconds) * time.Second).String()
+		c.UI.Output(fmt.Sprintf("Token expires:        %s (in %s)", formatTime(r.ExpiresAt), remaining))
+	default:
+		c.UI.Output(fmt.Sprintf("Token expired:        %s", formatTime(r.ExpiresAt)))
+	}
+
+	if !r.RegistrationExpiresAt.IsZero() {
+		c.UI.Output(fmt.Sprintf("Client registration:  expires %s", formatTime(r.RegistrationExpiresAt)))
+	}
+
+	refresh := "no"
+	if r.HasRefreshToken {
+		refresh = "yes"
+	}
+	c.UI.Output(fmt.Sprintf("Refresh token:        %s", refresh))
+}
+
+// outputPrompt prints the minutes until the token expires for shell prompts, "expired"
+// once it has, and nothing without a cached token
+func (c *cmd) outputPrompt(r *report) {
+	switch {
+	case r.ExpiresAt.IsZero():
+	case r.LoggedIn:
+		c.UI.Output(fmt.Sprintf("%dm", r.RemainingSeconds/60))
+	default:
+		c.UI.Output("expired")
+	}
+}
+
+func formatTime(t time.Time) string {
+	return t.Local().Format(time.RFC1123)
+}
+
+func (c *cmd) Help() string {
+	return c.help
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
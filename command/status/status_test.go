@@ -0,0 +1,125 @@
+package status
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	awsprovider "github.com/blairham/aws-sso-config/providers/aws"
+)
+
+const testStartURL = "https://test.awsapps.com/start"
+
+func setup(t *testing.T) (*cli.MockUi, *cmd, string, time.Time) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	configFile := filepath.Join(t.TempDir(), "app-config.toml")
+	content := "[sso]\nstart_url = \"" + testStartURL + "\"\nregion = \"eu-west-1\"\n"
+	require.NoError(t, os.WriteFile(configFile, []byte(content), 0600))
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	ui := cli.NewMockUi()
+	c := New(ui)
+	c.now = func() time.Time { return now }
+
+	return ui, c, configFile, now
+}
+
+func TestStatusLoggedIn(t *testing.T) {
+	ui, c, configFile, now := setup(t)
+	require.NoError(t, awsprovider.SaveCacheEntry(&awsprovider.SSOCacheEntry{
+		StartURL:              testStartURL,
+		Region:                "eu-west-1",
+		AccessToken:           "token",
+		ExpiresAt:             now.Add(90 * time.Minute),
+		RegistrationExpiresAt: now.Add(30 * 24 * time.Hour),
+		RefreshToken:          "refresh",
+	}))
+
+	assert.Equal(t, 0, c.Run([]string{"--config=" + configFile}))
+
+	output := ui.OutputWriter.String()
+	assert.Contains(t, output, testStartURL)
+	assert.Contains(t, output, "(in 1h30m0s)")
+	assert.Contains(t, output, "Client registration:  expires")
+	assert.Contains(t, output, "Refresh token:        yes")
+	assert.Contains(t, output, "Token store:          file")
+}
+
+func TestStatusJSON(t *testing.T) {
+	ui, c, configFile, now := setup(t)
+	require.NoError(t, awsprovider.SaveCacheEntry(&awsprovider.SSOCacheEntry{
+		StartURL:    testStartURL,
+		AccessToken: "token",
+		ExpiresAt:   now.Add(time.Hour),
+	}))
+
+	assert.Equal(t, 0, c.Run([]string{"--config=" + configFile, "--output", "json"}))
+
+	var r report
+	require.NoError(t, json.Unmarshal(ui.OutputWriter.Bytes(), &r))
+	assert.Equal(t, testStartURL, r.StartURL)
+	assert.Equal(t, "eu-west-1", r.Region)
+	assert.True(t, r.LoggedIn)
+	assert.Equal(t, int64(3600), r.RemainingSeconds)
+	assert.False(t, r.HasRefreshToken)
+	assert.True(t, r.RegistrationExpiresAt.IsZero())
+	assert.NotContains(t, ui.OutputWriter.String(), "registration_expires_at")
+}
+
+func TestStatusExpired(t *testing.T) {
+	ui, c, configFile, now := setup(t)
+	require.NoError(t, awsprovider.SaveCacheEntry(&awsprovider.SSOCacheEntry{
+		StartURL:    testStartURL,
+		AccessToken: "token",
+		ExpiresAt:   now.Add(-time.Minute),
+	}))
+
+	assert.Equal(t, 1, c.Run([]string{"--config=" + configFile}))
+	assert.Contains(t, ui.OutputWriter.String(), "Token expired:")
+}
+
+func TestStatusNotLoggedIn(t *testing.T) {
+	ui, c, configFile, _ := setup(t)
+
+	assert.Equal(t, 1, c.Run([]string{"--config=" + configFile, "-o", "json"}))
+
+	var r report
+	require.NoError(t, json.Unmarshal(ui.OutputWriter.Bytes(), &r))
+	assert.False(t, r.LoggedIn)
+	assert.Equal(t, testStartURL, r.StartURL)
+}
+
+func TestStatusPrompt(t *testing.T) {
+	ui, c, configFile, now := setup(t)
+
+	assert.Equal(t, 1, c.Run([]string{"--config=" + configFile, "-o", "prompt"}))
+	assert.Empty(t, ui.OutputWriter.String())
+
+	require.NoError(t, awsprovider.SaveCacheEntry(&awsprovider.SSOCacheEntry{
+		StartURL:    testStartURL,
+		AccessToken: "token",
+		ExpiresAt:   now.Add(42*time.Minute + 30*time.Second),
+	}))
+	assert.Equal(t, 0, c.Run([]string{"--config=" + configFile, "-o", "prompt"}))
+	assert.Equal(t, "42m\n", ui.OutputWriter.String())
+
+	ui.OutputWriter.Reset()
+	c.now = func() time.Time { return now.Add(time.Hour) }
+	assert.Equal(t, 1, c.Run([]string{"--config=" + configFile, "-o", "prompt"}))
+	assert.Equal(t, "expired\n", ui.OutputWriter.String())
+}
+
+func TestStatusInvalidOutput(t *testing.T) {
+	ui, c, configFile, _ := setup(t)
+
+	assert.Equal(t, 1, c.Run([]string{"--config=" + configFile, "--output", "xml"}))
+	assert.Contains(t, ui.ErrorWriter.String(), "Unsupported output format")
+}
@@ -0,0 +1,38 @@
+package switchprofile
+
+const synopsis = "Pick a profile with a fuzzy finder and switch to it"
+const help = `
+Usage: aws-sso-config switch [options] [query]
+
+  Search the profiles in the AWS config file by name, account ID, role
+  and account email, and print the shell command that sets AWS_PROFILE.
+  Without a query, or when the query matches more than one profile, an
+  interactive picker opens on the terminal:
+
+    type            narrow down the list
+    Up/Down         move the selection (also Ctrl-P/Ctrl-N)
+    Enter           switch to the selected profile
+    Esc, Ctrl-C     cancel
+
+  A query that names a profile, or matches a single one, switches to it
+  without asking. The picker is drawn on stderr, so the output can be
+  evaluated directly. With --envrc, AWS_PROFILE is written to the direnv
+  .envrc file in the current directory instead.
+
+  The wrapper function installed by 'aws-sso-config shell-init' evaluates
+  the output for you.
+
+Examples:
+
+  # Pick a profile and switch the current shell to it
+  eval "$(aws-sso-config switch)"
+
+  # Switch to the profile of an account ID
+  eval "$(aws-sso-config switch 123456789012)"
+
+  # Fish
+  aws-sso-config switch --shell fish staging | source
+
+  # Pin the profile for a project with direnv
+  aws-sso-config switch --envrc production
+`
@@ -0,0 +1,217 @@
+package switchprofile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mitchellh/cli"
+	"github.com/spf13/pflag"
+
+	"github.com/blairham/aws-sso-config/internal/picker"
+	awsprovider "github.com/blairham/aws-sso-config/providers/aws"
+	appconfig "github.com/blairham/aws-sso-config/providers/config"
+)
+
+const (
+	shellSh   = "sh"
+	shellFish = "fish"
+
+	envrcFile = ".envrc"
+)
+
+type cmd struct {
+	UI    cli.Ui
+	flags *pflag.FlagSet
+	help  string
+
+	configFile string
+	envrc      bool
+	shell      string
+
+	// Dependencies for testing
+	pick      func(items []picker.Item, query string) (picker.Item, error)
+	envrcPath string
+}
+
+func New(ui cli.Ui) *cmd {
+	return NewWithDependencies(ui, pickOnTerminal)
+}
+
+// NewWithDependencies creates a new command with injected dependencies for testing
+func NewWithDependencies(ui cli.Ui, pick func(items []picker.Item, query string) (picker.Item, error)) *cmd {
+	c := &cmd{UI: ui}
+	c.Init()
+	c.pick = pick
+	c.envrcPath = envrcFile
+	return c
+}
+
+func (c *cmd) Init() {
+	c.flags = pflag.NewFlagSet("switch", pflag.ContinueOnError)
+	c.flags.StringVarP(&c.configFile, "config", "c", "", "Path to configuration file")
+	c.flags.BoolVar(&c.envrc, "envrc", false, "Write AWS_PROFILE to .envrc in the current directory")
+	c.flags.StringVar(&c.shell, "shell", shellSh, "Shell syntax of the output: sh (bash, zsh) or fish")
+
+	c.help = help + "\n" + c.flags.FlagUsages()
+}
+
+// pickOnTerminal runs the picker on the terminal, keeping stdout free for the shell snippet
+func pickOnTerminal(items []picker.Item, query string) (picker.Item, error) {
+	return picker.New(os.Stdin, os.Stderr).Run(items, query)
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	switch c.shell {
+	case shellSh, "bash", "zsh", shellFish:
+	default:
+		c.UI.Error(fmt.Sprintf("Unsupported shell: %s (expected sh, bash, zsh or fish)", c.shell))
+		return 1
+	}
+
+	appCfg, err := appconfig.Load(c.configFile)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Configuration error: %v", err))
+		return 1
+	}
+
+	profiles, err := awsprovider.ListProfiles(appCfg.ConfigFile())
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+	if len(profiles) == 0 {
+		c.UI.Error(fmt.Sprintf("No profiles found in %s, run 'aws-sso-config generate' first", appCfg.ConfigFile()))
+		return 1
+	}
+
+	profile, err := c.choose(profiles, strings.Join(c.flags.Args(), " "))
+	switch {
+	case errors.Is(err, picker.ErrCancelled):
+		return awsprovider.ExitCodeCancelled
+	case errors.Is(err, picker.ErrNotTerminal):
+		c.UI.Error("No terminal to pick a profile on, pass a query that matches a single profile")
+		return 1
+	case err != nil:
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if c.envrc {
+		if err := writeEnvrc(c.envrcPath, profile); err != nil {
+			c.UI.Error(err.Error())
+			return 1
+		}
+		c.UI.Output(fmt.Sprintf("Set AWS_PROFILE=%s in %s, run 'direnv allow' to load it", profile, c.envrcPath))
+		return 0
+	}
+
+	c.UI.Output(exportLine(c.shell, profile))
+	return 0
+}
+
+// choose returns the profile named by the query or the only one matching it, and otherwise
+// lets the user pick
+func (c *cmd) choose(profiles []awsprovider.ProfileSummary, query string) (string, error) {
+	items := profileItems(profiles)
+	for _, item := range items {
+		if item.Value == query {
+			return item.Value, nil
+		}
+	}
+
+	if query != "" {
+		switch matches := picker.Filter(items, query); len(matches) {
+		case 0:
+			return "", fmt.Errorf("no profile matches %q", query)
+		case 1:
+			return matches[0].Value, nil
+		}
+	}
+
+	item, err := c.pick(items, query)
+	if err != nil {
+		return "", err
+	}
+	return item.Value, nil
+}
+
+// profileItems lays the profiles out in aligned columns of name, account ID, role and email
+func profileItems(profiles []awsprovider.ProfileSummary) []picker.Item {
+	nameWidth, roleWidth := 0, 0
+	for _, profile := range profiles {
+		nameWidth = max(nameWidth, len(profile.Name))
+		roleWidth = max(roleWidth, len(profile.RoleName))
+	}
+
+	items := make([]picker.Item, len(profiles))
+	for i, profile := range profiles {
+		label := fmt.Sprintf("%-*s  %-12s  %-*s  %s", nameWidth, profile.Name, profile.AccountID, roleWidth, profile.RoleName, profile.Email)
+		items[i] = picker.Item{
+			Label: strings.TrimRight(label, " "),
+			Value: profile.Name,
+		}
+	}
+	return items
+}
+
+// exportLine returns the command that sets AWS_PROFILE in the given shell
+func exportLine(shell, profile string) string {
+	if shell == shellFish {
+		return "set -gx AWS_PROFILE " + fishQuote(profile)
+	}
+	return "export AWS_PROFILE=" + shellQuote(profile)
+}
+
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+func fishQuote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return "'" + strings.ReplaceAll(value, "'", `\'`) + "'"
+}
+
+// writeEnvrc sets AWS_PROFILE in a direnv .envrc, replacing an earlier export and keeping
+// the rest of the file
+func writeEnvrc(path, profile string) error {
+	line := exportLine(shellSh, profile)
+
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var lines []string
+	replaced := false
+	if len(content) > 0 {
+		lines = strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	}
+	for i, existing := range lines {
+		if strings.HasPrefix(strings.TrimSpace(existing), "export AWS_PROFILE=") {
+			lines[i] = line
+			replaced = true
+		}
+	}
+	if !replaced {
+		lines = append(lines, line)
+	}
+
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+func (c *cmd) Help() string {
+	return c.help
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
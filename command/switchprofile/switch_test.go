@@ -0,0 +1,180 @@
+package switchprofile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/blairham/aws-sso-config/internal/picker"
+	awsprovider "github.com/blairham/aws-sso-config/providers/aws"
+)
+
+// fakePicker records what the picker was offered and picks a fixed profile
+type fakePicker struct {
+	items  []picker.Item
+	query  string
+	choice string
+	err    error
+}
+
+func (f *fakePicker) pick(items []picker.Item, query string) (picker.Item, error) {
+	f.items = items
+	f.query = query
+	if f.err != nil {
+		return picker.Item{}, f.err
+	}
+	return picker.Item{Value: f.choice}, nil
+}
+
+func writeConfigs(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	awsConfig := filepath.Join(dir, "aws-config")
+	require.NoError(t, os.WriteFile(awsConfig, []byte(`[profile production]
+sso_account_id = 111111111111
+sso_role_name = AdministratorAccess
+sso_account_email = ops@example.com
+
+[profile production-deploy]
+source_profile = production
+role_arn = arn:aws:iam::111111111111:role/Deploy
+
+[profile staging]
+sso_account_id = 222222222222
+sso_role_name = ReadOnly
+sso_account_email = dev@example.com
+`), 0600))
+
+	appConfig := filepath.Join(dir, "app-config.toml")
+	require.NoError(t, os.WriteFile(appConfig, []byte(`[aws]
+config_file = "`+awsConfig+`"
+`), 0600))
+	return appConfig
+}
+
+func TestSwitch(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		choice     string
+		wantPicked bool
+		want       string
+	}{
+		{name: "single match", args: []string{"2222"}, want: "export AWS_PROFILE='staging'\n"},
+		{name: "email", args: []string{"ops@"}, choice: "production", wantPicked: true, want: "export AWS_PROFILE='production'\n"},
+		{name: "exact name among several matches", args: []string{"production"}, want: "export AWS_PROFILE='production'\n"},
+		{name: "multi term query", args: []string{"prod", "deploy"}, want: "export AWS_PROFILE='production-deploy'\n"},
+		{name: "no query opens the picker", choice: "staging", wantPicked: true, want: "export AWS_PROFILE='staging'\n"},
+		{name: "fish", args: []string{"--shell", "fish", "staging"}, want: "set -gx AWS_PROFILE 'staging'\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ui := cli.NewMockUi()
+			fake := &fakePicker{choice: tt.choice}
+			c := NewWithDependencies(ui, fake.pick)
+
+			exitCode := c.Run(append([]string{"--config=" + writeConfigs(t)}, tt.args...))
+
+			require.Equal(t, 0, exitCode, ui.ErrorWriter.String())
+			assert.Equal(t, tt.want, ui.OutputWriter.String())
+			assert.Equal(t, tt.wantPicked, fake.items != nil)
+		})
+	}
+}
+
+func TestSwitchPickerItems(t *testing.T) {
+	fake := &fakePicker{choice: "staging"}
+	c := NewWithDependencies(cli.NewMockUi(), fake.pick)
+
+	require.Equal(t, 0, c.Run([]string{"--config=" + writeConfigs(t), "prod"}))
+
+	assert.Equal(t, "prod", fake.query)
+	require.Len(t, fake.items, 3)
+	assert.Equal(t, "production         111111111111  AdministratorAccess  ops@example.com", fake.items[0].Label)
+	assert.Equal(t, "production-deploy  111111111111  Deploy               ops@example.com", fake.items[1].Label)
+	assert.Equal(t, "staging            222222222222  ReadOnly             dev@example.com", fake.items[2].Label)
+}
+
+func TestSwitchErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		err      error
+		exitCode int
+		want     string
+	}{
+		{name: "no match", args: []string{"qa"}, exitCode: 1, want: `no profile matches "qa"`},
+		{name: "cancelled", err: picker.ErrCancelled, exitCode: awsprovider.ExitCodeCancelled},
+		{name: "not a terminal", err: picker.ErrNotTerminal, exitCode: 1, want: "pass a query that matches a single profile"},
+		{name: "unsupported shell", args: []string{"--shell", "powershell"}, exitCode: 1, want: "Unsupported shell"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ui := cli.NewMockUi()
+			c := NewWithDependencies(ui, (&fakePicker{err: tt.err}).pick)
+
+			assert.Equal(t, tt.exitCode, c.Run(append([]string{"--config=" + writeConfigs(t)}, tt.args...)))
+			assert.Contains(t, ui.ErrorWriter.String(), tt.want)
+			assert.Empty(t, ui.OutputWriter.String())
+		})
+	}
+}
+
+func TestSwitchNoProfiles(t *testing.T) {
+	dir := t.TempDir()
+	awsConfig := filepath.Join(dir, "aws-config")
+	require.NoError(t, os.WriteFile(awsConfig, []byte("[default]\nregion = us-east-1\n"), 0600))
+	appConfig := filepath.Join(dir, "app-config.toml")
+	require.NoError(t, os.WriteFile(appConfig, []byte("[aws]\nconfig_file = \""+awsConfig+"\"\n"), 0600))
+
+	ui := cli.NewMockUi()
+	c := NewWithDependencies(ui, (&fakePicker{}).pick)
+
+	assert.Equal(t, 1, c.Run([]string{"--config=" + appConfig}))
+	assert.Contains(t, ui.ErrorWriter.String(), "run 'aws-sso-config generate' first")
+}
+
+func TestSwitchEnvrc(t *testing.T) {
+	envrc := filepath.Join(t.TempDir(), ".envrc")
+	require.NoError(t, os.WriteFile(envrc, []byte("export AWS_PROFILE='staging'\nuse nix\n"), 0600))
+
+	ui := cli.NewMockUi()
+	c := NewWithDependencies(ui, (&fakePicker{}).pick)
+	c.envrcPath = envrc
+
+	require.Equal(t, 0, c.Run([]string{"--config=" + writeConfigs(t), "--envrc", "production"}))
+
+	content, err := os.ReadFile(envrc)
+	require.NoError(t, err)
+	assert.Equal(t, "export AWS_PROFILE='production'\nuse nix\n", string(content))
+	assert.Contains(t, ui.OutputWriter.String(), "direnv allow")
+}
+
+func TestWriteEnvrcNewFile(t *testing.T) {
+	envrc := filepath.Join(t.TempDir(), ".envrc")
+
+	require.NoError(t, writeEnvrc(envrc, "it's"))
+
+	content, err := os.ReadFile(envrc)
+	require.NoError(t, err)
+	assert.Equal(t, "export AWS_PROFILE='it'\\''s'\n", string(content))
+}
+
+func TestExportLine(t *testing.T) {
+	assert.Equal(t, `export AWS_PROFILE='a'\''b'`, exportLine(shellSh, "a'b"))
+	assert.Equal(t, `set -gx AWS_PROFILE 'a\'b\\c'`, exportLine(shellFish, `a'b\c`))
+}
+
+func TestSwitchHelp(t *testing.T) {
+	c := New(cli.NewMockUi())
+
+	assert.Contains(t, c.Help(), "Usage: aws-sso-config switch")
+	assert.Contains(t, c.Help(), "--envrc")
+	assert.Equal(t, synopsis, c.Synopsis())
+}
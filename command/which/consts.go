@@ -0,0 +1,28 @@
+package which
+
+const synopsis = "Show which profile applies to a directory and why"
+const help = `
+Usage: aws-sso-config which [options] [directory]
+
+  Show the AWS profile chosen for a directory, the current one by
+  default, and explain how it was chosen:
+
+    1. AWS_PROFILE, when it is already set (e.g. by direnv)
+    2. the first [[repos]] entry in the configuration file whose path
+       glob matches the git repository's root directory, or whose remote
+       glob matches its origin URL
+    3. a profile named after the repository, when its account matches
+       the account_id in the repository's terragrunt.hcl
+    4. the default profile
+
+  A [[repos]] entry mapping to a profile that does not exist, or to an
+  account other than the account_id in terragrunt.hcl, is an error.
+
+Examples:
+
+  # Explain the profile for the current directory
+  aws-sso-config which
+
+  # Print only the profile name
+  aws-sso-config which --quiet ~/src/payments-api
+`
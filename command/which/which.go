@@ -0,0 +1,116 @@
+package which
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mitchellh/cli"
+	"github.com/spf13/pflag"
+
+	awsprovider "github.com/blairham/aws-sso-config/providers/aws"
+	appconfig "github.com/blairham/aws-sso-config/providers/config"
+)
+
+type cmd struct {
+	UI    cli.Ui
+	flags *pflag.FlagSet
+	help  string
+
+	configFile string
+	quiet      bool
+}
+
+func New(ui cli.Ui) *cmd {
+	c := &cmd{UI: ui}
+	c.Init()
+	return c
+}
+
+func (c *cmd) Init() {
+	c.flags = pflag.NewFlagSet("which", pflag.ContinueOnError)
+	c.flags.StringVarP(&c.configFile, "config", "c", "", "Path to configuration file")
+	c.flags.BoolVarP(&c.quiet, "quiet", "q", false, "Print only the profile name")
+
+	c.help = help + "\n" + c.flags.FlagUsages()
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+	if c.flags.NArg() > 1 {
+		c.UI.Error("Usage: aws-sso-config which [options] [directory]")
+		return 1
+	}
+
+	dir := c.flags.Arg(0)
+	if dir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			c.UI.Error(err.Error())
+			return 1
+		}
+		dir = cwd
+	}
+
+	appCfg, err := appconfig.Load(c.configFile)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Configuration error: %v", err))
+		return 1
+	}
+	if err := appCfg.Repos.Validate(); err != nil {
+		c.UI.Error(fmt.Sprintf("Configuration error: %v", err))
+		return 1
+	}
+
+	r, err := awsprovider.ResolveRepoProfile(appCfg, dir)
+	if err != nil {
+		if r != nil && !c.quiet {
+			c.outputSteps(r)
+		}
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if c.quiet {
+		c.UI.Output(r.Profile)
+		return 0
+	}
+
+	c.UI.Output(fmt.Sprintf("Profile:  %s", r.Profile))
+	c.UI.Output(fmt.Sprintf("Source:   %s", source(r)))
+	if r.AccountID != "" {
+		c.UI.Output(fmt.Sprintf("Account:  %s", r.AccountID))
+	}
+	c.UI.Output("")
+	c.outputSteps(r)
+	return 0
+}
+
+// outputSteps prints how the profile was chosen
+func (c *cmd) outputSteps(r *awsprovider.ProfileResolution) {
+	c.UI.Output("How it was chosen:")
+	for i, step := range r.Steps {
+		c.UI.Output(fmt.Sprintf("  %d. %s", i+1, step))
+	}
+}
+
+// source names where the profile came from
+func source(r *awsprovider.ProfileResolution) string {
+	switch r.Source {
+	case awsprovider.ProfileSourceEnvironment:
+		return awsprovider.AwsProfile
+	case awsprovider.ProfileSourceRepos:
+		return r.Rule.String()
+	default:
+		return string(r.Source)
+	}
+}
+
+func (c *cmd) Help() string {
+	return c.help
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
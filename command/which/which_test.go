@@ -0,0 +1,90 @@
+package which
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setup creates an AWS config, an app config mapping repositories under base/src and a
+// repository base/src/payments, and returns the app config and the repository
+func setup(t *testing.T, profile string) (string, string) {
+	t.Helper()
+	t.Setenv("AWS_PROFILE", "")
+	base := t.TempDir()
+
+	awsConfig := filepath.Join(base, "aws-config")
+	require.NoError(t, os.WriteFile(awsConfig, []byte("[profile payments]\nsso_account_id = 111111111111\n"), 0600))
+
+	appConfig := filepath.Join(base, "app-config.toml")
+	content := "[aws]\nconfig_file = \"" + awsConfig + "\"\n\n" +
+		"[[repos]]\nremote = \"github.com/acme/*\"\nprofile = \"" + profile + "\"\n"
+	require.NoError(t, os.WriteFile(appConfig, []byte(content), 0600))
+
+	repo := filepath.Join(base, "src", "payments")
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, ".git"), 0750))
+	gitConfig := "[remote \"origin\"]\n\turl = git@github.com:acme/payments.git\n"
+	require.NoError(t, os.WriteFile(filepath.Join(repo, ".git", "config"), []byte(gitConfig), 0600))
+
+	return appConfig, repo
+}
+
+func TestWhich(t *testing.T) {
+	appConfig, repo := setup(t, "payments")
+	ui := cli.NewMockUi()
+
+	require.Equal(t, 0, New(ui).Run([]string{"--config=" + appConfig, repo}), ui.ErrorWriter.String())
+
+	output := ui.OutputWriter.String()
+	assert.Contains(t, output, "Profile:  payments")
+	assert.Contains(t, output, `Source:   [[repos]] remote = "github.com/acme/*"`)
+	assert.Contains(t, output, "Account:  111111111111")
+	assert.Contains(t, output, "1. found git repository "+repo+" with origin git@github.com:acme/payments.git")
+}
+
+func TestWhichQuiet(t *testing.T) {
+	appConfig, repo := setup(t, "payments")
+	ui := cli.NewMockUi()
+
+	require.Equal(t, 0, New(ui).Run([]string{"--config=" + appConfig, "-q", filepath.Join(repo, ".git")}))
+	assert.Equal(t, "payments\n", ui.OutputWriter.String())
+}
+
+func TestWhichDefault(t *testing.T) {
+	appConfig, _ := setup(t, "payments")
+	ui := cli.NewMockUi()
+
+	require.Equal(t, 0, New(ui).Run([]string{"--config=" + appConfig, t.TempDir()}))
+	assert.Contains(t, ui.OutputWriter.String(), "Profile:  default")
+	assert.Contains(t, ui.OutputWriter.String(), "is not inside a git repository, using the default profile")
+}
+
+func TestWhichMappingToMissingProfile(t *testing.T) {
+	appConfig, repo := setup(t, "billing")
+	ui := cli.NewMockUi()
+
+	assert.Equal(t, 1, New(ui).Run([]string{"--config=" + appConfig, repo}))
+	assert.Contains(t, ui.OutputWriter.String(), "maps it to profile billing")
+	assert.Contains(t, ui.ErrorWriter.String(), "could not find profile for billing")
+}
+
+func TestWhichInvalidRepos(t *testing.T) {
+	appConfig := filepath.Join(t.TempDir(), "app-config.toml")
+	require.NoError(t, os.WriteFile(appConfig, []byte("[[repos]]\nprofile = \"payments\"\n"), 0600))
+	ui := cli.NewMockUi()
+
+	assert.Equal(t, 1, New(ui).Run([]string{"--config=" + appConfig}))
+	assert.Contains(t, ui.ErrorWriter.String(), "set either path or remote")
+}
+
+func TestWhichHelp(t *testing.T) {
+	c := New(cli.NewMockUi())
+
+	assert.Contains(t, c.Help(), "Usage: aws-sso-config which")
+	assert.Contains(t, c.Help(), "--quiet")
+	assert.Equal(t, synopsis, c.Synopsis())
+}
@@ -0,0 +1,95 @@
+package picker
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Filter returns the items matching query, best matches first. Every space separated term of
+// the query has to match the item's label or keywords, either as a substring or as a fuzzy
+// subsequence. Matching is case-insensitive, and an empty query matches all items.
+func Filter(items []Item, query string) []Item {
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return items
+	}
+
+	type scored struct {
+		item  Item
+		score int
+	}
+	matches := make([]scored, 0, len(items))
+	for _, item := range items {
+		text := item.text()
+		total := 0
+		matched := true
+		for _, term := range terms {
+			s, ok := score(text, term)
+			if !ok {
+				matched = false
+				break
+			}
+			total += s
+		}
+		if matched {
+			matches = append(matches, scored{item: item, score: total})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	result := make([]Item, len(matches))
+	for i, m := range matches {
+		result[i] = m.item
+	}
+	return result
+}
+
+// score rates how well term matches the lowercased text. Substrings rate above subsequences,
+// matches at the start of a word above matches inside one, and tight subsequences above
+// scattered ones.
+func score(text, term string) (int, bool) {
+	if i := strings.Index(text, term); i >= 0 {
+		s := 1000 - min(i, 100)
+		if wordStart(text, i) {
+			s += 500
+		}
+		return s, true
+	}
+
+	// Greedy subsequence match; the span between the first and the last matched rune
+	// tells how scattered the match is
+	first, last := -1, -1
+	pos := 0
+	for _, r := range term {
+		i := strings.IndexRune(text[pos:], r)
+		if i < 0 {
+			return 0, false
+		}
+		if first < 0 {
+			first = pos + i
+		}
+		last = pos + i
+		pos += i + utf8.RuneLen(r)
+	}
+
+	gaps := utf8.RuneCountInString(text[first:last]) + 1 - utf8.RuneCountInString(term)
+	s := 500 - min(gaps*10, 400)
+	if wordStart(text, first) {
+		s += 50
+	}
+	return s, true
+}
+
+// wordStart reports whether the byte at i starts a word
+func wordStart(text string, i int) bool {
+	if i == 0 {
+		return true
+	}
+	r, _ := utf8.DecodeLastRuneInString(text[:i])
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
@@ -0,0 +1,66 @@
+package picker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func labels(items []Item) []string {
+	result := make([]string, len(items))
+	for i, item := range items {
+		result[i] = item.Label
+	}
+	return result
+}
+
+func TestFilter(t *testing.T) {
+	items := []Item{
+		{Label: "production", Keywords: []string{"111111111111", "AdministratorAccess", "ops@example.com"}},
+		{Label: "staging", Keywords: []string{"222222222222", "ReadOnly", "dev@example.com"}},
+		{Label: "sandbox-production-copy", Keywords: []string{"333333333333", "AdministratorAccess"}},
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{name: "empty query keeps the order", query: "", want: []string{"production", "staging", "sandbox-production-copy"}},
+		{name: "prefix match ranks first", query: "prod", want: []string{"production", "sandbox-production-copy"}},
+		{name: "case insensitive", query: "PROD", want: []string{"production", "sandbox-production-copy"}},
+		{name: "account ID", query: "2222", want: []string{"staging"}},
+		{name: "email", query: "dev@", want: []string{"staging"}},
+		{name: "role keyword", query: "readonly", want: []string{"staging"}},
+		{name: "fuzzy subsequence", query: "stg", want: []string{"staging"}},
+		{name: "every term has to match", query: "admin sand", want: []string{"sandbox-production-copy"}},
+		{name: "no match", query: "qa", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, labels(Filter(items, tt.query)))
+		})
+	}
+}
+
+func TestScore(t *testing.T) {
+	substring, ok := score("production", "duct")
+	assert.True(t, ok)
+	prefix, _ := score("production", "prod")
+	assert.Greater(t, prefix, substring)
+
+	tight, ok := score("staging", "stg")
+	assert.True(t, ok)
+	scattered, _ := score("s-t-a-g-i-n-g-x", "sx")
+	assert.Greater(t, tight, scattered)
+	assert.Greater(t, substring, tight)
+
+	_, ok = score("staging", "gs")
+	assert.False(t, ok)
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "short", truncate("short", 10))
+	assert.Equal(t, "a long…", truncate("a long line", 8))
+}
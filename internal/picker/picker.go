@@ -0,0 +1,232 @@
+package picker
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+var (
+	// ErrCancelled is returned when the user leaves the picker with Esc or Ctrl-C
+	ErrCancelled = errors.New("selection cancelled")
+	// ErrNoItems is returned when there is nothing to pick from
+	ErrNoItems = errors.New("nothing to pick from")
+	// ErrNotTerminal is returned when the input is not a terminal
+	ErrNotTerminal = errors.New("input is not a terminal")
+)
+
+// Key codes read in raw mode
+const (
+	keyCtrlC     = 0x03
+	keyCtrlK     = 0x0b
+	keyCtrlN     = 0x0e
+	keyCtrlP     = 0x10
+	keyCtrlU     = 0x15
+	keyBackspace = 0x08
+	keyDelete    = 0x7f
+	keyEscape    = 0x1b
+)
+
+// Item is an entry the user can pick
+type Item struct {
+	// Label is the line shown in the list
+	Label string
+	// Keywords are matched along with the label but not shown
+	Keywords []string
+	// Value identifies the item for the caller
+	Value string
+}
+
+// text returns the lowercased text queries are matched against
+func (i Item) text() string {
+	return strings.ToLower(strings.Join(append([]string{i.Label}, i.Keywords...), " "))
+}
+
+// Picker is an interactive fuzzy finder drawn below the cursor. The list narrows down as
+// the user types; arrow keys, Ctrl-P/Ctrl-N or Ctrl-K move the selection, Enter picks the
+// selected item and Esc or Ctrl-C cancels.
+type Picker struct {
+	in  *os.File
+	out io.Writer
+
+	// Prompt is shown before the query
+	Prompt string
+	// Height is the maximum number of items shown at once
+	Height int
+}
+
+// New creates a picker that reads keys from the terminal in and draws on out, usually
+// the same terminal
+func New(in *os.File, out io.Writer) *Picker {
+	return &Picker{
+		in:     in,
+		out:    out,
+		Prompt: "> ",
+		Height: 10,
+	}
+}
+
+// state is the query and selection while the picker runs
+type state struct {
+	items    []Item
+	query    []rune
+	matches  []Item
+	selected int
+	offset   int
+}
+
+// Run lets the user pick one of items, starting with query as the filter
+func (p *Picker) Run(items []Item, query string) (Item, error) {
+	if len(items) == 0 {
+		return Item{}, ErrNoItems
+	}
+
+	fd := int(p.in.Fd())
+	if !term.IsTerminal(fd) {
+		return Item{}, ErrNotTerminal
+	}
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return Item{}, fmt.Errorf("failed to set up the terminal: %w", err)
+	}
+	defer term.Restore(fd, oldState)
+
+	width := 80
+	if w, _, err := term.GetSize(fd); err == nil && w > 0 {
+		width = w
+	}
+
+	s := &state{items: items, query: []rune(query)}
+	s.filter()
+
+	reader := bufio.NewReader(p.in)
+	for {
+		p.render(s, width)
+
+		r, _, err := reader.ReadRune()
+		if err != nil {
+			p.clear()
+			return Item{}, fmt.Errorf("failed to read from the terminal: %w", err)
+		}
+
+		switch r {
+		case '\r', '\n':
+			if len(s.matches) > 0 {
+				p.clear()
+				return s.matches[s.selected], nil
+			}
+		case keyCtrlC:
+			p.clear()
+			return Item{}, ErrCancelled
+		case keyEscape:
+			// A lone Esc cancels, otherwise it starts an escape sequence such as an arrow key
+			if reader.Buffered() == 0 {
+				p.clear()
+				return Item{}, ErrCancelled
+			}
+			s.escapeSequence(reader)
+		case keyCtrlP, keyCtrlK:
+			s.move(-1)
+		case keyCtrlN:
+			s.move(1)
+		case keyBackspace, keyDelete:
+			if len(s.query) > 0 {
+				s.query = s.query[:len(s.query)-1]
+				s.filter()
+			}
+		case keyCtrlU:
+			s.query = nil
+			s.filter()
+		default:
+			if unicode.IsPrint(r) {
+				s.query = append(s.query, r)
+				s.filter()
+			}
+		}
+	}
+}
+
+// escapeSequence handles the arrow keys, in both the normal and the application cursor mode
+func (s *state) escapeSequence(reader *bufio.Reader) {
+	prefix, err := reader.ReadByte()
+	if err != nil || (prefix != '[' && prefix != 'O') {
+		return
+	}
+	key, err := reader.ReadByte()
+	if err != nil {
+		return
+	}
+	switch key {
+	case 'A':
+		s.move(-1)
+	case 'B':
+		s.move(1)
+	}
+}
+
+// filter applies the query and selects the best match
+func (s *state) filter() {
+	s.matches = Filter(s.items, string(s.query))
+	s.selected = 0
+	s.offset = 0
+}
+
+// move moves the selection by delta, stopping at the first and last match
+func (s *state) move(delta int) {
+	s.selected = max(0, min(s.selected+delta, len(s.matches)-1))
+}
+
+// render draws the prompt, the visible matches and a match counter, then puts the cursor
+// back at the end of the query
+func (p *Picker) render(s *state, width int) {
+	height := max(p.Height, 1)
+	if s.selected < s.offset {
+		s.offset = s.selected
+	}
+	if s.selected >= s.offset+height {
+		s.offset = s.selected - height + 1
+	}
+	end := min(s.offset+height, len(s.matches))
+
+	var b strings.Builder
+	b.WriteString("\r\x1b[J")
+	b.WriteString(truncate(p.Prompt+string(s.query), width))
+	lines := 0
+	for i := s.offset; i < end; i++ {
+		marker := "  "
+		if i == s.selected {
+			marker = "> "
+		}
+		b.WriteString("\r\n" + truncate(marker+s.matches[i].Label, width))
+		lines++
+	}
+	fmt.Fprintf(&b, "\r\n  %d/%d", len(s.matches), len(s.items))
+	lines++
+
+	fmt.Fprintf(&b, "\x1b[%dA\r", lines)
+	if column := utf8.RuneCountInString(p.Prompt) + len(s.query); column > 0 {
+		fmt.Fprintf(&b, "\x1b[%dC", min(column, width-1))
+	}
+	io.WriteString(p.out, b.String())
+}
+
+// clear erases the picker
+func (p *Picker) clear() {
+	io.WriteString(p.out, "\r\x1b[J")
+}
+
+// truncate shortens line to fit in width columns, so that lines never wrap
+func truncate(line string, width int) string {
+	if utf8.RuneCountInString(line) < width {
+		return line
+	}
+	runes := []rune(line)
+	return string(runes[:max(width-2, 0)]) + "…"
+}
@@ -0,0 +1,176 @@
+package picker
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/creack/pty"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// terminal is a pseudo-terminal the picker runs on, with the controlling side used to type
+// keys and capture what is drawn
+type terminal struct {
+	ptmx *os.File
+	tty  *os.File
+
+	mu     sync.Mutex
+	output bytes.Buffer
+	done   chan struct{}
+}
+
+func newTerminal(t *testing.T) *terminal {
+	t.Helper()
+	ptmx, tty, err := pty.Open()
+	if err != nil {
+		t.Skipf("pseudo-terminals are not available: %v", err)
+	}
+	require.NoError(t, pty.Setsize(ptmx, &pty.Winsize{Rows: 24, Cols: 40}))
+
+	pt := &terminal{ptmx: ptmx, tty: tty, done: make(chan struct{})}
+	// Drain the output so that the picker never blocks on a full terminal buffer
+	go func() {
+		defer close(pt.done)
+		buf := make([]byte, 1024)
+		for {
+			n, err := ptmx.Read(buf)
+			pt.mu.Lock()
+			pt.output.Write(buf[:n])
+			pt.mu.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}()
+	t.Cleanup(func() {
+		tty.Close()
+		ptmx.Close()
+		<-pt.done
+	})
+	return pt
+}
+
+// run starts the picker and returns a channel with its result
+func (pt *terminal) run(items []Item, query string) <-chan result {
+	results := make(chan result, 1)
+	go func() {
+		item, err := New(pt.tty, pt.tty).Run(items, query)
+		results <- result{item: item, err: err}
+	}()
+	return results
+}
+
+// waitFor waits until the picker has drawn text
+func (pt *terminal) waitFor(t *testing.T, text string) {
+	t.Helper()
+	assert.Eventually(t, func() bool {
+		pt.mu.Lock()
+		defer pt.mu.Unlock()
+		return bytes.Contains(pt.output.Bytes(), []byte(text))
+	}, 5*time.Second, 10*time.Millisecond, "picker did not draw %q", text)
+}
+
+// typeKeys sends keys as separate key presses
+func (pt *terminal) typeKeys(t *testing.T, keys ...string) {
+	t.Helper()
+	for _, key := range keys {
+		_, err := io.WriteString(pt.ptmx, key)
+		require.NoError(t, err)
+		// Give the picker time to read each key separately
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+type result struct {
+	item Item
+	err  error
+}
+
+func wait(t *testing.T, results <-chan result) result {
+	t.Helper()
+	select {
+	case r := <-results:
+		return r
+	case <-time.After(5 * time.Second):
+		t.Fatal("picker did not return")
+		return result{}
+	}
+}
+
+var testItems = []Item{
+	{Label: "production", Keywords: []string{"111111111111"}, Value: "prod"},
+	{Label: "staging", Keywords: []string{"222222222222"}, Value: "stage"},
+	{Label: "sandbox", Keywords: []string{"333333333333"}, Value: "sandbox"},
+}
+
+func TestPickerSelectsFirstMatch(t *testing.T) {
+	pt := newTerminal(t)
+	results := pt.run(testItems, "")
+	pt.waitFor(t, "3/3")
+
+	pt.typeKeys(t, "2222")
+	pt.waitFor(t, "1/3")
+	pt.typeKeys(t, "\r")
+
+	r := wait(t, results)
+	require.NoError(t, r.err)
+	assert.Equal(t, "stage", r.item.Value)
+}
+
+func TestPickerArrowKeys(t *testing.T) {
+	pt := newTerminal(t)
+	results := pt.run(testItems, "")
+	pt.waitFor(t, "3/3")
+
+	pt.typeKeys(t, "\x1b[B", "\x1b[B", "\x1b[B", "\x1b[A", "\r")
+
+	r := wait(t, results)
+	require.NoError(t, r.err)
+	assert.Equal(t, "stage", r.item.Value)
+}
+
+func TestPickerInitialQueryAndBackspace(t *testing.T) {
+	pt := newTerminal(t)
+	results := pt.run(testItems, "sz")
+	pt.waitFor(t, "0/3")
+
+	// Enter does nothing without matches
+	pt.typeKeys(t, "\r", "\x7f")
+	pt.waitFor(t, "2/3")
+	pt.typeKeys(t, "\x0e", "\r")
+
+	r := wait(t, results)
+	require.NoError(t, r.err)
+	assert.Equal(t, "sandbox", r.item.Value)
+}
+
+func TestPickerCancel(t *testing.T) {
+	for name, key := range map[string]string{"escape": "\x1b", "ctrl-c": "\x03"} {
+		t.Run(name, func(t *testing.T) {
+			pt := newTerminal(t)
+			results := pt.run(testItems, "")
+			pt.waitFor(t, "3/3")
+
+			pt.typeKeys(t, key)
+
+			r := wait(t, results)
+			assert.ErrorIs(t, r.err, ErrCancelled)
+		})
+	}
+}
+
+func TestPickerErrors(t *testing.T) {
+	_, err := New(os.Stdin, io.Discard).Run(nil, "")
+	assert.ErrorIs(t, err, ErrNoItems)
+
+	in, err := os.Open(os.DevNull)
+	require.NoError(t, err)
+	defer in.Close()
+	_, err = New(in, io.Discard).Run(testItems, "")
+	assert.ErrorIs(t, err, ErrNotTerminal)
+}
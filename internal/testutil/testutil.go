@@ -0,0 +1,75 @@
+// Package testutil holds fixtures shared by the tests of the commands that fetch role
+// credentials
+package testutil
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sso"
+	"github.com/aws/aws-sdk-go-v2/service/sso/types"
+	"github.com/stretchr/testify/require"
+
+	awsprovider "github.com/blairham/aws-sso-config/providers/aws"
+	appconfig "github.com/blairham/aws-sso-config/providers/config"
+)
+
+// StubRoleCredentialsClient returns fixed role credentials, valid for an hour
+type StubRoleCredentialsClient struct{}
+
+func (StubRoleCredentialsClient) GetRoleCredentials(context.Context, *sso.GetRoleCredentialsInput, ...func(*sso.Options)) (*sso.GetRoleCredentialsOutput, error) {
+	return &sso.GetRoleCredentialsOutput{
+		RoleCredentials: &types.RoleCredentials{
+			AccessKeyId:     aws.String("AKIAEXAMPLE"),
+			SecretAccessKey: aws.String("secret"),
+			SessionToken:    aws.String("session"),
+			Expiration:      time.Now().Add(time.Hour).UnixMilli(),
+		},
+	}, nil
+}
+
+// StubFetcher returns a fetcher with a fixed SSO token and StubRoleCredentialsClient
+func StubFetcher(aws.Config, awsprovider.LoginOptions) *awsprovider.CredentialFetcher {
+	return &awsprovider.CredentialFetcher{
+		Token: func(context.Context, *appconfig.Config) (awsprovider.Token, error) {
+			return awsprovider.Token{AccessToken: "sso-token"}, nil
+		},
+		Client: func(string) awsprovider.RoleCredentialsClient { return StubRoleCredentialsClient{} },
+	}
+}
+
+// ConfigLoader returns an empty AWS configuration
+func ConfigLoader(context.Context) (aws.Config, error) {
+	return aws.Config{}, nil
+}
+
+// WriteConfigs writes an AWS config file with an SSO profile named dev, for account
+// 123456789012 and role Developer in eu-west-1, and a configuration file pointing to
+// it. It returns the configuration file.
+func WriteConfigs(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	awsConfig := filepath.Join(dir, "aws-config")
+	require.NoError(t, os.WriteFile(awsConfig, []byte(`[profile dev]
+sso_start_url = https://dev.awsapps.com/start
+sso_region = us-west-2
+sso_account_id = 123456789012
+sso_role_name = Developer
+region = eu-west-1
+`), 0600))
+
+	appConfig := filepath.Join(dir, "app-config.toml")
+	require.NoError(t, os.WriteFile(appConfig, []byte(`[sso]
+start_url = "https://test.awsapps.com/start"
+region = "us-east-1"
+role = "AdministratorAccess"
+
+[aws]
+config_file = "`+awsConfig+`"
+`), 0600))
+	return appConfig
+}
@@ -1,13 +1,18 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	mcli "github.com/mitchellh/cli"
 
 	"github.com/blairham/aws-sso-config/command"
 	"github.com/blairham/aws-sso-config/command/cli"
+	"github.com/blairham/aws-sso-config/command/flags"
+	appconfig "github.com/blairham/aws-sso-config/providers/config"
 )
 
 // Version information - set by build flags
@@ -25,8 +30,8 @@ func main() {
 }
 
 // For testing purposes
-var createCLI = func(ui *cli.BasicUI, args []string) *mcli.CLI {
-	cmds := command.RegisteredCommands(ui)
+var createCLI = func(ctx context.Context, ui *cli.BasicUI, args []string) *mcli.CLI {
+	cmds := command.RegisteredCommands(ctx, ui)
 	var names []string
 	for c := range cmds {
 		names = append(names, c)
@@ -45,6 +50,10 @@ var createCLI = func(ui *cli.BasicUI, args []string) *mcli.CLI {
 }
 
 func Run(args []string) int {
+	// Cancel in-flight requests and logins on Ctrl-C or termination
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	ui := &cli.BasicUI{
 		BasicUi: mcli.BasicUi{
 			Reader:      os.Stdin,
@@ -53,7 +62,22 @@ func Run(args []string) int {
 		},
 	}
 
-	cliInstance := createCLI(ui, args)
+	// --context is accepted by every command, so it is taken out before the
+	// command's own flags are parsed and handed to the config loader through the
+	// environment, which also carries it to nested invocations
+	args, contextName, err := flags.ExtractContext(args)
+	if err != nil {
+		ui.Error(err.Error())
+		return 1
+	}
+	if contextName != "" {
+		if err := os.Setenv(appconfig.ContextEnv, contextName); err != nil {
+			ui.Error(fmt.Sprintf("Error selecting context: %v", err))
+			return 1
+		}
+	}
+
+	cliInstance := createCLI(ctx, ui, args)
 
 	exitCode, err := cliInstance.Run()
 	if err != nil {
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"os"
 	"testing"
 
@@ -35,6 +36,11 @@ func TestRun(t *testing.T) {
 			args:     []string{"invalid-command"},
 			expected: 127, // CLI returns 127 for invalid commands
 		},
+		{
+			name:     "context flag without a name",
+			args:     []string{"status", "--context"},
+			expected: 1,
+		},
 	}
 
 	for _, tt := range tests {
@@ -50,7 +56,7 @@ func TestCreateCLI(t *testing.T) {
 	ui := &cli.BasicUI{}
 
 	// Test CLI creation
-	cliInstance := createCLI(ui, []string{})
+	cliInstance := createCLI(context.Background(), ui, []string{})
 	assert.NotNil(t, cliInstance)
 
 	// Test that commands are registered
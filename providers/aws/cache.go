@@ -0,0 +1,104 @@
+package aws
+
+import (
+	"crypto/sha1" // #nosec G505 - sha1 is what the AWS CLI uses to name cache files, not for security
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SSOCacheEntry mirrors the token files the AWS CLI keeps in ~/.aws/sso/cache
+type SSOCacheEntry struct {
+	StartURL              string    `json:"startUrl,omitempty"`
+	Region                string    `json:"region,omitempty"`
+	AccessToken           string    `json:"accessToken"`
+	ExpiresAt             time.Time `json:"expiresAt"`
+	ClientID              string    `json:"clientId,omitempty"`
+	ClientSecret          string    `json:"clientSecret,omitempty"`
+	RegistrationExpiresAt time.Time `json:"registrationExpiresAt,omitzero"`
+	RefreshToken          string    `json:"refreshToken,omitempty"`
+}
+
+// Valid reports whether the entry holds an access token that has not expired yet
+func (e *SSOCacheEntry) Valid(now time.Time) bool {
+	return e.AccessToken != "" && !e.ExpiresAt.IsZero() && now.Before(e.ExpiresAt)
+}
+
+// CacheDir returns the SSO token cache directory shared with the AWS CLI
+func CacheDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	return filepath.Join(home, ".aws", "sso", "cache"), nil
+}
+
+// CacheFile returns the cache file for a start URL, named the same way as the AWS CLI names it
+func CacheFile(startURL string) (string, error) {
+	dir, err := CacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha1.Sum([]byte(startURL)) // #nosec G401 - file naming only
+	return filepath.Join(dir, hex.EncodeToString(sum[:])+".json"), nil
+}
+
+// LoadCacheEntry reads the cached token for a start URL. It returns an error
+// wrapping os.ErrNotExist when the user has not logged in yet.
+func LoadCacheEntry(startURL string) (*SSOCacheEntry, error) {
+	filename, err := CacheFile(startURL)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SSO cache: %w", err)
+	}
+
+	var entry SSOCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("failed to parse SSO cache %s: %w", filename, err)
+	}
+
+	return &entry, nil
+}
+
+// SaveCacheEntry writes the token for the entry's start URL, readable only by the user
+func SaveCacheEntry(entry *SSOCacheEntry) error {
+	filename, err := CacheFile(entry.StartURL)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0700); err != nil {
+		return fmt.Errorf("failed to create SSO cache directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode SSO cache: %w", err)
+	}
+
+	return os.WriteFile(filename, data, 0600)
+}
+
+// DeleteCacheEntry removes the cached token for a start URL. Removing an entry that does not exist is not an error.
+func DeleteCacheEntry(startURL string) error {
+	filename, err := CacheFile(startURL)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(filename); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove SSO cache: %w", err)
+	}
+
+	return nil
+}
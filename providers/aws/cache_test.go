@@ -0,0 +1,67 @@
+package aws
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	filename, err := CacheFile("https://test.awsapps.com/start")
+	require.NoError(t, err)
+
+	// Same naming scheme as the AWS CLI: sha1 of the start URL
+	assert.Equal(t, filepath.Join(home, ".aws", "sso", "cache", "bfe9e37c85cc299e34d8c03b631672483f78cd01.json"), filename)
+}
+
+func TestCacheEntryRoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	startURL := "https://test.awsapps.com/start"
+
+	_, err := LoadCacheEntry(startURL)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	entry := &SSOCacheEntry{
+		StartURL:              startURL,
+		Region:                "us-west-2",
+		AccessToken:           "access-token",
+		ExpiresAt:             time.Now().Add(time.Hour).UTC().Truncate(time.Second),
+		ClientID:              "client-id",
+		ClientSecret:          "client-secret",
+		RegistrationExpiresAt: time.Now().Add(90 * 24 * time.Hour).UTC().Truncate(time.Second),
+		RefreshToken:          "refresh-token",
+	}
+	require.NoError(t, SaveCacheEntry(entry))
+
+	filename, err := CacheFile(startURL)
+	require.NoError(t, err)
+	info, err := os.Stat(filename)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	loaded, err := LoadCacheEntry(startURL)
+	require.NoError(t, err)
+	assert.Equal(t, entry, loaded)
+
+	require.NoError(t, DeleteCacheEntry(startURL))
+	assert.NoFileExists(t, filename)
+
+	// Deleting again is not an error
+	assert.NoError(t, DeleteCacheEntry(startURL))
+}
+
+func TestSSOCacheEntryValid(t *testing.T) {
+	now := time.Now()
+
+	assert.True(t, (&SSOCacheEntry{AccessToken: "token", ExpiresAt: now.Add(time.Minute)}).Valid(now))
+	assert.False(t, (&SSOCacheEntry{AccessToken: "token", ExpiresAt: now.Add(-time.Minute)}).Valid(now))
+	assert.False(t, (&SSOCacheEntry{AccessToken: "token"}).Valid(now))
+	assert.False(t, (&SSOCacheEntry{ExpiresAt: now.Add(time.Minute)}).Valid(now))
+}
@@ -1,13 +1,13 @@
 package aws
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -17,7 +17,8 @@ import (
 // TestToString and TestConfigFile already exist in profiles_test.go
 
 func TestLoadDefaultConfig(t *testing.T) {
-	cfg := LoadDefaultConfig()
+	cfg, err := LoadDefaultConfig(context.Background())
+	require.NoError(t, err)
 	// Should have a non-empty region
 	assert.NotEmpty(t, cfg.Region)
 }
@@ -70,15 +71,16 @@ default_region: "eu-west-1"
 
 	// We won't actually test the token generation which requires real AWS SSO
 	// Just ensure the function doesn't panic or crash
-	cfg := LoadDefaultConfig()
+	cfg, err := LoadDefaultConfig(context.Background())
+	require.NoError(t, err)
 
 	// Either this should return nil (common) or potentially a token if SSO cache exists
-	token := GenerateTokenWithConfig(cfg, appCfg)
+	token, err := GenerateTokenWithConfig(context.Background(), cfg, appCfg)
 
 	// The real test is that we don't panic or crash
-	if token != nil {
+	if err == nil {
 		// If a token was returned, verify it's not empty
-		assert.NotEmpty(t, aws.ToString(token))
+		assert.NotEmpty(t, token.AccessToken)
 	}
 }
 
@@ -230,15 +232,14 @@ func TestGetTokenWithCache(t *testing.T) {
 	require.NoError(t, err)
 
 	// Load default config
-	cfg := LoadDefaultConfig()
+	cfg, err := LoadDefaultConfig(context.Background())
+	require.NoError(t, err)
 
 	// Test GetToken - should return cached token and not open browser
-	token := GetToken(cfg)
-	if token == nil {
-		t.Skip("GetToken returned nil - may be due to environment differences or AWS SSO not being available")
-	}
-	assert.NotNil(t, token, "Should return cached token")
-	if token != nil {
-		assert.Equal(t, "cached-token-123", *token, "Should return correct cached token")
+	token, err := GetToken(context.Background(), cfg)
+	if err != nil {
+		t.Skip("GetToken failed - may be due to environment differences or AWS SSO not being available")
 	}
+	assert.Equal(t, "cached-token-123", token.AccessToken, "Should return correct cached token")
+	assert.Equal(t, TokenSourceCache, token.Source)
 }
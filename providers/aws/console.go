@@ -0,0 +1,122 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// consoleIssuer identifies this tool on the federation login page
+	consoleIssuer = "aws-sso-config"
+	// federationTimeout bounds the getSigninToken request
+	federationTimeout = 30 * time.Second
+)
+
+// consolePartition holds the federation endpoint and console host of an AWS partition
+type consolePartition struct {
+	federationURL string
+	consoleHost   string
+}
+
+// partitionForRegion returns the partition a region belongs to
+func partitionForRegion(region string) consolePartition {
+	switch {
+	case strings.HasPrefix(region, "us-gov-"):
+		return consolePartition{federationURL: "https://signin.amazonaws-us-gov.com/federation", consoleHost: "console.amazonaws-us-gov.com"}
+	case strings.HasPrefix(region, "cn-"):
+		return consolePartition{federationURL: "https://signin.amazonaws.cn/federation", consoleHost: "console.amazonaws.cn"}
+	default:
+		return consolePartition{federationURL: "https://signin.aws.amazon.com/federation", consoleHost: "console.aws.amazon.com"}
+	}
+}
+
+// ConsoleSignin exchanges role credentials for an AWS console sign-in URL
+type ConsoleSignin struct {
+	Client *http.Client
+	// FederationURL overrides the partition's federation endpoint, for tests
+	FederationURL string
+}
+
+// NewConsoleSignin returns a ConsoleSignin that uses the default HTTP client
+func NewConsoleSignin() *ConsoleSignin {
+	return &ConsoleSignin{Client: http.DefaultClient}
+}
+
+// URL returns a sign-in URL that opens the console page of a service in a region,
+// or the console home page when service is empty. The URL is valid for 15 minutes.
+func (s *ConsoleSignin) URL(ctx context.Context, creds *RoleCredentials, service, region string) (string, error) {
+	partition := partitionForRegion(region)
+	federationURL := partition.federationURL
+	if s.FederationURL != "" {
+		federationURL = s.FederationURL
+	}
+
+	token, err := s.signinToken(ctx, federationURL, creds)
+	if err != nil {
+		return "", err
+	}
+
+	if service == "" {
+		service = "console"
+	}
+	destination := fmt.Sprintf("https://%s/%s/home?region=%s", partition.consoleHost, url.PathEscape(service), url.QueryEscape(region))
+
+	query := url.Values{
+		"Action":      {"login"},
+		"Issuer":      {consoleIssuer},
+		"Destination": {destination},
+		"SigninToken": {token},
+	}
+	return federationURL + "?" + query.Encode(), nil
+}
+
+// signinToken calls the federation endpoint's getSigninToken action. SessionDuration is not
+// sent, the federation endpoint rejects it for role credentials.
+func (s *ConsoleSignin) signinToken(ctx context.Context, federationURL string, creds *RoleCredentials) (string, error) {
+	session, err := json.Marshal(map[string]string{
+		"sessionId":    creds.AccessKeyID,
+		"sessionKey":   creds.SecretAccessKey,
+		"sessionToken": creds.SessionToken,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode federation session: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, federationTimeout)
+	defer cancel()
+
+	query := url.Values{"Action": {"getSigninToken"}, "Session": {string(session)}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, federationURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create federation request: %w", err)
+	}
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get console sign-in token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return "", fmt.Errorf("failed to read console sign-in token: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get console sign-in token: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var out struct {
+		SigninToken string `json:"SigninToken"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil || out.SigninToken == "" {
+		return "", fmt.Errorf("federation endpoint returned no sign-in token")
+	}
+
+	return out.SigninToken, nil
+}
@@ -0,0 +1,81 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsoleSigninURL(t *testing.T) {
+	var session map[string]string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "getSigninToken", r.URL.Query().Get("Action"))
+		assert.Empty(t, r.URL.Query().Get("SessionDuration"))
+		require.NoError(t, json.Unmarshal([]byte(r.URL.Query().Get("Session")), &session))
+		_, _ = w.Write([]byte(`{"SigninToken":"signin-token"}`))
+	}))
+	defer ts.Close()
+
+	signin := &ConsoleSignin{Client: ts.Client(), FederationURL: ts.URL}
+	creds := &RoleCredentials{AccessKeyID: "AKIAEXAMPLE", SecretAccessKey: "secret", SessionToken: "session"}
+
+	t.Run("service and region", func(t *testing.T) {
+		signinURL, err := signin.URL(context.Background(), creds, "s3", "eu-west-1")
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]string{"sessionId": "AKIAEXAMPLE", "sessionKey": "secret", "sessionToken": "session"}, session)
+
+		parsed, err := url.Parse(signinURL)
+		require.NoError(t, err)
+		assert.Equal(t, ts.URL, parsed.Scheme+"://"+parsed.Host)
+		assert.Equal(t, "login", parsed.Query().Get("Action"))
+		assert.Equal(t, "signin-token", parsed.Query().Get("SigninToken"))
+		assert.Equal(t, "aws-sso-config", parsed.Query().Get("Issuer"))
+		assert.Equal(t, "https://console.aws.amazon.com/s3/home?region=eu-west-1", parsed.Query().Get("Destination"))
+	})
+
+	t.Run("console home by default", func(t *testing.T) {
+		signinURL, err := signin.URL(context.Background(), creds, "", "us-east-1")
+		require.NoError(t, err)
+
+		parsed, err := url.Parse(signinURL)
+		require.NoError(t, err)
+		assert.Equal(t, "https://console.aws.amazon.com/console/home?region=us-east-1", parsed.Query().Get("Destination"))
+	})
+}
+
+func TestConsoleSigninErrors(t *testing.T) {
+	creds := &RoleCredentials{AccessKeyID: "AKIAEXAMPLE"}
+
+	t.Run("rejected credentials", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "Invalid session", http.StatusBadRequest)
+		}))
+		defer ts.Close()
+
+		_, err := (&ConsoleSignin{Client: ts.Client(), FederationURL: ts.URL}).URL(context.Background(), creds, "", "us-east-1")
+		assert.ErrorContains(t, err, "400 Bad Request: Invalid session")
+	})
+
+	t.Run("missing token", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{}`))
+		}))
+		defer ts.Close()
+
+		_, err := (&ConsoleSignin{Client: ts.Client(), FederationURL: ts.URL}).URL(context.Background(), creds, "", "us-east-1")
+		assert.ErrorContains(t, err, "no sign-in token")
+	})
+}
+
+func TestPartitionForRegion(t *testing.T) {
+	assert.Equal(t, "console.aws.amazon.com", partitionForRegion("eu-west-1").consoleHost)
+	assert.Equal(t, "https://signin.amazonaws-us-gov.com/federation", partitionForRegion("us-gov-west-1").federationURL)
+	assert.Equal(t, "console.amazonaws.cn", partitionForRegion("cn-north-1").consoleHost)
+}
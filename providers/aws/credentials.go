@@ -0,0 +1,244 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sso"
+	"github.com/bigkevmcd/go-configparser"
+	"github.com/mitchellh/go-homedir"
+
+	appconfig "github.com/blairham/aws-sso-config/providers/config"
+)
+
+// Interface for the SSO role credentials operation to allow mocking in tests
+type RoleCredentialsClient interface {
+	GetRoleCredentials(ctx context.Context, params *sso.GetRoleCredentialsInput, optFns ...func(*sso.Options)) (*sso.GetRoleCredentialsOutput, error)
+}
+
+// RoleTarget is the account and role to get credentials for, with the SSO portal that grants them
+type RoleTarget struct {
+	// Profile is the AWS config profile the target was read from, empty for --account/--role
+	Profile   string
+	AccountID string
+	RoleName  string
+	// Region is the profile's default region for API calls
+	Region    string
+	StartURL  string
+	SSORegion string
+}
+
+// RoleCredentials are the short-lived credentials returned by GetRoleCredentials
+type RoleCredentials struct {
+	AccessKeyID     string    `json:"access_key_id"`
+	SecretAccessKey string    `json:"secret_access_key"`
+	SessionToken    string    `json:"session_token"`
+	Expiration      time.Time `json:"expiration"`
+}
+
+// ResolveProfile reads the SSO settings of a profile from an AWS config file.
+// Both sso_start_url on the profile and [sso-session] sections are supported.
+func ResolveProfile(configFile, profile string) (*RoleTarget, error) {
+	configFile, err := homedir.Expand(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", configFile, err)
+	}
+
+	awsConfig, err := configparser.NewConfigParserFromFile(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", configFile, err)
+	}
+
+	section := "profile " + profile
+	if profile == "default" && !awsConfig.HasSection(section) {
+		section = "default"
+	}
+	if !awsConfig.HasSection(section) {
+		return nil, fmt.Errorf("profile %s not found in %s", profile, configFile)
+	}
+
+	get := func(section, option string) string {
+		value, _ := awsConfig.Get(section, option)
+		return value
+	}
+
+	target := &RoleTarget{
+		Profile:   profile,
+		AccountID: get(section, "sso_account_id"),
+		RoleName:  get(section, "sso_role_name"),
+		Region:    get(section, "region"),
+		StartURL:  get(section, "sso_start_url"),
+		SSORegion: get(section, "sso_region"),
+	}
+
+	if session := get(section, "sso_session"); session != "" {
+		sessionSection := "sso-session " + session
+		if !awsConfig.HasSection(sessionSection) {
+			return nil, fmt.Errorf("profile %s refers to sso-session %s, which is not defined in %s", profile, session, configFile)
+		}
+		target.StartURL = get(sessionSection, "sso_start_url")
+		target.SSORegion = get(sessionSection, "sso_region")
+	}
+
+	if target.AccountID == "" || target.RoleName == "" || target.StartURL == "" {
+		return nil, fmt.Errorf("profile %s is not an SSO profile, it needs sso_account_id, sso_role_name and sso_start_url", profile)
+	}
+
+	return target, nil
+}
+
+// NewRoleTarget builds a target for an account and role using the SSO portal from the app config
+func NewRoleTarget(appCfg *appconfig.Config, accountID, roleName string) (*RoleTarget, error) {
+	if accountID == "" {
+		return nil, errors.New("an account ID is required")
+	}
+	if roleName == "" {
+		roleName = appCfg.SSORole()
+	}
+
+	return &RoleTarget{
+		AccountID: accountID,
+		RoleName:  roleName,
+		Region:    appCfg.DefaultRegion(),
+		StartURL:  appCfg.SSOStartURL(),
+		SSORegion: appCfg.SSORegion(),
+	}, nil
+}
+
+// ResolveTarget returns the target for a profile in the AWS config file, or for an account and role
+// when no profile is given. The region falls back to the configured default region.
+func ResolveTarget(appCfg *appconfig.Config, profile, accountID, roleName string) (*RoleTarget, error) {
+	var target *RoleTarget
+	var err error
+	switch {
+	case profile != "" && accountID != "":
+		return nil, errors.New("use either --profile or --account, not both")
+	case profile != "":
+		target, err = ResolveProfile(appCfg.ConfigFile(), profile)
+	case accountID != "":
+		target, err = NewRoleTarget(appCfg, accountID, roleName)
+	default:
+		return nil, errors.New("either --profile or --account is required")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if target.Region == "" {
+		target.Region = appCfg.DefaultRegion()
+	}
+
+	return target, nil
+}
+
+// AppConfig returns a copy of appCfg that logs in to the target's SSO portal
+func (t *RoleTarget) AppConfig(appCfg *appconfig.Config) *appconfig.Config {
+	cfg := *appCfg
+	cfg.SSO.StartURL = t.StartURL
+	if t.SSORegion != "" {
+		cfg.SSO.Region = t.SSORegion
+	}
+
+	return &cfg
+}
+
+// CredentialFetcher gets role credentials for a target, getting or refreshing the SSO token as needed
+type CredentialFetcher struct {
+	// Token returns an SSO access token for the app config's start URL
+	Token func(ctx context.Context, appCfg *appconfig.Config) (Token, error)
+	// Client returns an SSO client for the given SSO region
+	Client func(region string) RoleCredentialsClient
+	// Cache, when set, is used before calling GetRoleCredentials
+	Cache *RoleCredentialCache
+}
+
+// NewCredentialFetcher returns a fetcher that uses the AWS SDK, the configured token store and
+// the role credential cache. Credentials are not cached when there is no user cache directory.
+func NewCredentialFetcher(cfg aws.Config, opts LoginOptions) *CredentialFetcher {
+	cache, _ := NewRoleCredentialCache()
+
+	return &CredentialFetcher{
+		Cache: cache,
+		Token: func(ctx context.Context, appCfg *appconfig.Config) (Token, error) {
+			return GetTokenWithOptions(ctx, cfg, appCfg, opts)
+		},
+		Client: func(region string) RoleCredentialsClient {
+			regional := cfg.Copy()
+			regional.Region = region
+			return sso.NewFromConfig(regional)
+		},
+	}
+}
+
+// Fetch returns role credentials for the target, from the cache while they are valid for longer
+// than the configured refresh window
+func (f *CredentialFetcher) Fetch(ctx context.Context, target *RoleTarget, appCfg *appconfig.Config) (*RoleCredentials, error) {
+	window := time.Duration(appCfg.SSOCredentialRefreshMinutes()) * time.Minute
+	if f.Cache != nil {
+		if creds, err := f.Cache.Load(target, window); err == nil {
+			return creds, nil
+		}
+	}
+
+	targetCfg := target.AppConfig(appCfg)
+
+	token, err := f.Token(ctx, targetCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	creds, err := GetRoleCredentials(ctx, f.Client(targetCfg.SSORegion()), token.AccessToken, target.AccountID, target.RoleName)
+	if err != nil {
+		return nil, err
+	}
+
+	// The cache only saves round trips, failing to write it does not fail the call
+	if f.Cache != nil {
+		_ = f.Cache.Save(target, creds)
+	}
+
+	return creds, nil
+}
+
+// GetRoleCredentials exchanges an SSO access token for credentials of a role in an account
+func GetRoleCredentials(ctx context.Context, client RoleCredentialsClient, accessToken, accountID, roleName string) (*RoleCredentials, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	out, err := client.GetRoleCredentials(ctx, &sso.GetRoleCredentialsInput{
+		AccessToken: aws.String(accessToken),
+		AccountId:   aws.String(accountID),
+		RoleName:    aws.String(roleName),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credentials for role %s in account %s: %w", roleName, accountID, err)
+	}
+	if out.RoleCredentials == nil {
+		return nil, fmt.Errorf("no credentials returned for role %s in account %s", roleName, accountID)
+	}
+
+	return &RoleCredentials{
+		AccessKeyID:     aws.ToString(out.RoleCredentials.AccessKeyId),
+		SecretAccessKey: aws.ToString(out.RoleCredentials.SecretAccessKey),
+		SessionToken:    aws.ToString(out.RoleCredentials.SessionToken),
+		Expiration:      time.UnixMilli(out.RoleCredentials.Expiration).UTC(),
+	}, nil
+}
+
+// Environ returns the credentials as AWS SDK environment variables, in KEY=value form
+func (c *RoleCredentials) Environ(region string) []string {
+	env := []string{
+		"AWS_ACCESS_KEY_ID=" + c.AccessKeyID,
+		"AWS_SECRET_ACCESS_KEY=" + c.SecretAccessKey,
+		"AWS_SESSION_TOKEN=" + c.SessionToken,
+		"AWS_CREDENTIAL_EXPIRATION=" + c.Expiration.Format(time.RFC3339),
+	}
+	if region != "" {
+		env = append(env, "AWS_REGION="+region, "AWS_DEFAULT_REGION="+region)
+	}
+
+	return env
+}
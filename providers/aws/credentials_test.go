@@ -0,0 +1,252 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sso"
+	"github.com/aws/aws-sdk-go-v2/service/sso/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	appconfig "github.com/blairham/aws-sso-config/providers/config"
+)
+
+// MockRoleCredentialsClient is a mock of the RoleCredentialsClient interface
+type MockRoleCredentialsClient struct {
+	mock.Mock
+}
+
+func (m *MockRoleCredentialsClient) GetRoleCredentials(
+	ctx context.Context,
+	params *sso.GetRoleCredentialsInput,
+	optFns ...func(*sso.Options),
+) (*sso.GetRoleCredentialsOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sso.GetRoleCredentialsOutput), args.Error(1)
+}
+
+const testAWSConfig = `[default]
+sso_start_url = https://default.awsapps.com/start
+sso_region = us-east-1
+sso_account_id = 111111111111
+sso_role_name = Default
+
+[profile dev]
+sso_start_url = https://dev.awsapps.com/start
+sso_region = us-west-2
+sso_account_id = 123456789012
+sso_role_name = Developer
+region = eu-west-1
+
+[profile shared]
+sso_session = corp
+sso_account_id = 210987654321
+sso_role_name = ReadOnly
+
+[sso-session corp]
+sso_start_url = https://corp.awsapps.com/start
+sso_region = eu-central-1
+
+[profile orphan]
+sso_session = missing
+sso_account_id = 210987654321
+sso_role_name = ReadOnly
+
+[profile static]
+region = us-east-1
+`
+
+func TestResolveProfile(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(configFile, []byte(testAWSConfig), 0600))
+
+	t.Run("profile with start url", func(t *testing.T) {
+		target, err := ResolveProfile(configFile, "dev")
+		require.NoError(t, err)
+		assert.Equal(t, &RoleTarget{
+			Profile:   "dev",
+			AccountID: "123456789012",
+			RoleName:  "Developer",
+			Region:    "eu-west-1",
+			StartURL:  "https://dev.awsapps.com/start",
+			SSORegion: "us-west-2",
+		}, target)
+	})
+
+	t.Run("profile with sso-session", func(t *testing.T) {
+		target, err := ResolveProfile(configFile, "shared")
+		require.NoError(t, err)
+		assert.Equal(t, "https://corp.awsapps.com/start", target.StartURL)
+		assert.Equal(t, "eu-central-1", target.SSORegion)
+		assert.Equal(t, "ReadOnly", target.RoleName)
+	})
+
+	t.Run("default section", func(t *testing.T) {
+		target, err := ResolveProfile(configFile, "default")
+		require.NoError(t, err)
+		assert.Equal(t, "111111111111", target.AccountID)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := ResolveProfile(configFile, "unknown")
+		assert.ErrorContains(t, err, "profile unknown not found")
+
+		_, err = ResolveProfile(configFile, "orphan")
+		assert.ErrorContains(t, err, "sso-session missing")
+
+		_, err = ResolveProfile(configFile, "static")
+		assert.ErrorContains(t, err, "is not an SSO profile")
+
+		_, err = ResolveProfile(filepath.Join(t.TempDir(), "missing"), "dev")
+		assert.Error(t, err)
+	})
+}
+
+func TestNewRoleTarget(t *testing.T) {
+	appCfg := &appconfig.Config{
+		SSO: appconfig.SSOConfig{StartURL: "https://test.awsapps.com/start", Region: "us-west-2", Role: "AdministratorAccess"},
+		AWS: appconfig.AWSConfig{DefaultRegion: "eu-west-1"},
+	}
+
+	target, err := NewRoleTarget(appCfg, "123456789012", "")
+	require.NoError(t, err)
+	assert.Equal(t, "AdministratorAccess", target.RoleName)
+	assert.Equal(t, "eu-west-1", target.Region)
+	assert.Equal(t, "https://test.awsapps.com/start", target.StartURL)
+
+	_, err = NewRoleTarget(appCfg, "", "ReadOnly")
+	assert.Error(t, err)
+}
+
+func TestResolveTarget(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(configFile, []byte(testAWSConfig), 0600))
+	appCfg := &appconfig.Config{
+		SSO: appconfig.SSOConfig{StartURL: "https://test.awsapps.com/start", Region: "us-west-2", Role: "AdministratorAccess"},
+		AWS: appconfig.AWSConfig{DefaultRegion: "us-east-2", ConfigFile: configFile},
+	}
+
+	t.Run("profile", func(t *testing.T) {
+		target, err := ResolveTarget(appCfg, "dev", "", "")
+		require.NoError(t, err)
+		assert.Equal(t, "123456789012", target.AccountID)
+		assert.Equal(t, "eu-west-1", target.Region)
+	})
+
+	t.Run("profile without region uses the default region", func(t *testing.T) {
+		target, err := ResolveTarget(appCfg, "shared", "", "")
+		require.NoError(t, err)
+		assert.Equal(t, "us-east-2", target.Region)
+	})
+
+	t.Run("account and role", func(t *testing.T) {
+		target, err := ResolveTarget(appCfg, "", "210987654321", "ReadOnly")
+		require.NoError(t, err)
+		assert.Equal(t, "ReadOnly", target.RoleName)
+		assert.Equal(t, "https://test.awsapps.com/start", target.StartURL)
+	})
+
+	t.Run("requires exactly one of profile and account", func(t *testing.T) {
+		_, err := ResolveTarget(appCfg, "", "", "")
+		assert.ErrorContains(t, err, "either --profile or --account is required")
+
+		_, err = ResolveTarget(appCfg, "dev", "210987654321", "")
+		assert.ErrorContains(t, err, "not both")
+	})
+}
+
+func TestCredentialFetcherFetch(t *testing.T) {
+	client := new(MockRoleCredentialsClient)
+	expiration := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	client.On("GetRoleCredentials", mock.Anything, mock.MatchedBy(func(in *sso.GetRoleCredentialsInput) bool {
+		return aws.ToString(in.AccessToken) == "sso-token" &&
+			aws.ToString(in.AccountId) == "123456789012" &&
+			aws.ToString(in.RoleName) == "Developer"
+	}), mock.Anything).Return(&sso.GetRoleCredentialsOutput{
+		RoleCredentials: &types.RoleCredentials{
+			AccessKeyId:     aws.String("AKIAEXAMPLE"),
+			SecretAccessKey: aws.String("secret"),
+			SessionToken:    aws.String("session"),
+			Expiration:      expiration.UnixMilli(),
+		},
+	}, nil)
+
+	var tokenCfg *appconfig.Config
+	var clientRegion string
+	fetcher := &CredentialFetcher{
+		Token: func(_ context.Context, appCfg *appconfig.Config) (Token, error) {
+			tokenCfg = appCfg
+			return Token{AccessToken: "sso-token"}, nil
+		},
+		Client: func(region string) RoleCredentialsClient {
+			clientRegion = region
+			return client
+		},
+	}
+	target := &RoleTarget{AccountID: "123456789012", RoleName: "Developer", StartURL: "https://dev.awsapps.com/start", SSORegion: "us-west-2"}
+	appCfg := &appconfig.Config{SSO: appconfig.SSOConfig{StartURL: "https://test.awsapps.com/start", Region: "us-east-1"}}
+
+	creds, err := fetcher.Fetch(context.Background(), target, appCfg)
+
+	require.NoError(t, err)
+	assert.Equal(t, "AKIAEXAMPLE", creds.AccessKeyID)
+	assert.Equal(t, expiration, creds.Expiration)
+	assert.Equal(t, "https://dev.awsapps.com/start", tokenCfg.SSOStartURL())
+	assert.Equal(t, "us-west-2", clientRegion)
+	assert.Equal(t, "https://test.awsapps.com/start", appCfg.SSOStartURL(), "the caller's config must not change")
+	client.AssertExpectations(t)
+}
+
+func TestCredentialFetcherErrors(t *testing.T) {
+	target := &RoleTarget{AccountID: "123456789012", RoleName: "Developer", StartURL: "https://dev.awsapps.com/start"}
+	appCfg := &appconfig.Config{}
+
+	t.Run("token error is returned unchanged", func(t *testing.T) {
+		fetcher := &CredentialFetcher{
+			Token: func(context.Context, *appconfig.Config) (Token, error) {
+				return Token{}, ErrAuthorizationDenied
+			},
+		}
+		_, err := fetcher.Fetch(context.Background(), target, appCfg)
+		assert.ErrorIs(t, err, ErrAuthorizationDenied)
+	})
+
+	t.Run("role credentials error", func(t *testing.T) {
+		client := new(MockRoleCredentialsClient)
+		client.On("GetRoleCredentials", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("forbidden"))
+		fetcher := &CredentialFetcher{
+			Token:  func(context.Context, *appconfig.Config) (Token, error) { return Token{AccessToken: "sso-token"}, nil },
+			Client: func(string) RoleCredentialsClient { return client },
+		}
+		_, err := fetcher.Fetch(context.Background(), target, appCfg)
+		assert.ErrorContains(t, err, "failed to get credentials for role Developer in account 123456789012: forbidden")
+	})
+}
+
+func TestRoleCredentialsEnviron(t *testing.T) {
+	creds := &RoleCredentials{
+		AccessKeyID:     "AKIAEXAMPLE",
+		SecretAccessKey: "secret",
+		SessionToken:    "session",
+		Expiration:      time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	assert.Equal(t, []string{
+		"AWS_ACCESS_KEY_ID=AKIAEXAMPLE",
+		"AWS_SECRET_ACCESS_KEY=secret",
+		"AWS_SESSION_TOKEN=session",
+		"AWS_CREDENTIAL_EXPIRATION=2025-01-01T12:00:00Z",
+	}, creds.Environ(""))
+	assert.Contains(t, creds.Environ("eu-west-1"), "AWS_REGION=eu-west-1")
+	assert.Contains(t, creds.Environ("eu-west-1"), "AWS_DEFAULT_REGION=eu-west-1")
+}
@@ -0,0 +1,63 @@
+package aws
+
+import (
+	"context"
+	"errors"
+)
+
+// Errors returned by token generation, check for them with errors.Is
+var (
+	// ErrRegistrationFailed means the client could not be registered with the SSO OIDC service
+	ErrRegistrationFailed = errors.New("failed to register client")
+	// ErrDeviceAuthorizationFailed means the device authorization could not be started, usually a wrong start URL or region
+	ErrDeviceAuthorizationFailed = errors.New("failed to start device authorization")
+	// ErrAuthorizationDenied means the user or the SSO portal rejected the device authorization
+	ErrAuthorizationDenied = errors.New("authorization was denied")
+	// ErrAuthorizationExpired means the device code expired before the user approved it
+	ErrAuthorizationExpired = errors.New("device authorization expired before it was approved")
+	// ErrRefreshFailed means the cached refresh token could not be exchanged for a new access token
+	ErrRefreshFailed = errors.New("failed to refresh token")
+	// ErrLoginCancelled means the login was interrupted, e.g. by Ctrl-C
+	ErrLoginCancelled = errors.New("login cancelled")
+)
+
+// Exit codes used by commands when obtaining a token fails
+const (
+	ExitCodeError                = 1
+	ExitCodeRegistrationFailed   = 3
+	ExitCodeAuthorizationDenied  = 4
+	ExitCodeAuthorizationExpired = 5
+	ExitCodeCancelled            = 130
+)
+
+// ExitCode maps a token generation error to a process exit code
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, ErrLoginCancelled), errors.Is(err, context.Canceled):
+		return ExitCodeCancelled
+	case errors.Is(err, ErrRegistrationFailed), errors.Is(err, ErrDeviceAuthorizationFailed):
+		return ExitCodeRegistrationFailed
+	case errors.Is(err, ErrAuthorizationDenied):
+		return ExitCodeAuthorizationDenied
+	case errors.Is(err, ErrAuthorizationExpired):
+		return ExitCodeAuthorizationExpired
+	default:
+		return ExitCodeError
+	}
+}
+
+// ErrorHint suggests what the user can do about a token generation error, or returns "" when there is nothing to suggest
+func ErrorHint(err error) string {
+	switch {
+	case errors.Is(err, ErrRegistrationFailed), errors.Is(err, ErrDeviceAuthorizationFailed):
+		return "Check that sso.start_url and sso.region are correct: aws-sso-config config get sso.start_url"
+	case errors.Is(err, ErrAuthorizationDenied):
+		return "The request was not approved. Run the command again and approve it in the browser."
+	case errors.Is(err, ErrAuthorizationExpired):
+		return "The code was not approved in time. Run the command again to get a new code."
+	default:
+		return ""
+	}
+}
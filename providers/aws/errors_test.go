@@ -0,0 +1,34 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCodeAndHint(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		exitCode int
+		hasHint  bool
+	}{
+		{"nil", nil, 0, false},
+		{"registration", fmt.Errorf("%w: boom", ErrRegistrationFailed), ExitCodeRegistrationFailed, true},
+		{"device authorization", fmt.Errorf("%w: boom", ErrDeviceAuthorizationFailed), ExitCodeRegistrationFailed, true},
+		{"denied", fmt.Errorf("login failed: %w", ErrAuthorizationDenied), ExitCodeAuthorizationDenied, true},
+		{"expired", ErrAuthorizationExpired, ExitCodeAuthorizationExpired, true},
+		{"cancelled", fmt.Errorf("%w: %w", ErrLoginCancelled, context.Canceled), ExitCodeCancelled, false},
+		{"other", errors.New("boom"), ExitCodeError, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.exitCode, ExitCode(tt.err))
+			assert.Equal(t, tt.hasHint, ErrorHint(tt.err) != "")
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package aws
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc"
+	"github.com/mdp/qrterminal/v3"
+	"golang.org/x/term"
+)
+
+// LoginOptions controls how the device authorization is presented to the user
+type LoginOptions struct {
+	// NoBrowser prints the verification URI and user code instead of opening a browser
+	NoBrowser bool
+	// QRCode additionally renders the verification URI as a terminal QR code
+	QRCode bool
+	// Out receives the login prompts, defaults to os.Stdout
+	Out io.Writer
+}
+
+// DefaultLoginOptions returns login options with headless mode auto-detected
+func DefaultLoginOptions() LoginOptions {
+	return LoginOptions{NoBrowser: IsHeadless()}
+}
+
+// writer returns the configured output or stdout
+func (o LoginOptions) writer() io.Writer {
+	if o.Out == nil {
+		return os.Stdout
+	}
+	return o.Out
+}
+
+// IsHeadless reports whether opening a browser is unlikely to reach the user,
+// e.g. over SSH, inside a container without a display, or when not on a terminal
+func IsHeadless() bool {
+	if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return true
+	}
+	// A browser would open on the remote machine rather than in front of the user
+	if os.Getenv("SSH_CONNECTION") != "" || os.Getenv("SSH_TTY") != "" {
+		return true
+	}
+	switch runtime.GOOS {
+	case "darwin", "windows":
+		return false
+	default:
+		return os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == ""
+	}
+}
+
+// presentDeviceAuthorization asks the user to approve the device authorization,
+// opening a browser when possible and falling back to printing the user code
+func presentDeviceAuthorization(deviceAuth *ssooidc.StartDeviceAuthorizationOutput, openBrowser func(string) error, opts LoginOptions) {
+	out := opts.writer()
+	url := aws.ToString(deviceAuth.VerificationUriComplete)
+
+	if !opts.NoBrowser && openBrowser != nil {
+		fmt.Fprintf(out, "Opening browser for AWS SSO login...\n%v\n", url)
+		if err := openBrowser(url); err == nil {
+			if opts.QRCode {
+				printQRCode(out, url)
+			}
+			fmt.Fprintln(out, "Waiting for authorization... (this may take a few moments)")
+			return
+		}
+		fmt.Fprintln(out, "Failed to open browser automatically.")
+	}
+
+	printDeviceCode(out, deviceAuth, opts.QRCode, time.Now())
+	fmt.Fprintln(out, "Waiting for authorization... (this may take a few moments)")
+}
+
+// printDeviceCode writes the verification URI, user code and expiry for headless logins
+func printDeviceCode(out io.Writer, deviceAuth *ssooidc.StartDeviceAuthorizationOutput, qrCode bool, now time.Time) {
+	fmt.Fprintln(out, "To sign in, open the following URL in a browser on any device:")
+	fmt.Fprintf(out, "\n  %s\n\n", aws.ToString(deviceAuth.VerificationUri))
+	fmt.Fprintf(out, "and enter the code: %s\n", aws.ToString(deviceAuth.UserCode))
+
+	if complete := aws.ToString(deviceAuth.VerificationUriComplete); complete != "" {
+		fmt.Fprintf(out, "\nOr open this URL, which already includes the code:\n\n  %s\n\n", complete)
+		if qrCode {
+			printQRCode(out, complete)
+		}
+	}
+
+	if deviceAuth.ExpiresIn > 0 {
+		expiresIn := time.Duration(deviceAuth.ExpiresIn) * time.Second
+		expiresAt := now.Add(expiresIn)
+		fmt.Fprintf(out, "The code expires in %s (at %s).\n", expiresIn, expiresAt.Format(time.Kitchen))
+	}
+}
+
+// printQRCode renders the URL as a QR code using half-height block characters
+func printQRCode(out io.Writer, url string) {
+	qrterminal.GenerateWithConfig(url, qrterminal.Config{
+		Level:          qrterminal.L,
+		Writer:         out,
+		HalfBlocks:     true,
+		BlackChar:      qrterminal.BLACK_BLACK,
+		WhiteBlackChar: qrterminal.WHITE_BLACK,
+		WhiteChar:      qrterminal.WHITE_WHITE,
+		BlackWhiteChar: qrterminal.BLACK_WHITE,
+		QuietZone:      1,
+	})
+	fmt.Fprintln(out)
+}
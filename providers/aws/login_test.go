@@ -0,0 +1,122 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	appconfig "github.com/blairham/aws-sso-config/providers/config"
+)
+
+func testDeviceAuth() *ssooidc.StartDeviceAuthorizationOutput {
+	return &ssooidc.StartDeviceAuthorizationOutput{
+		DeviceCode:              aws.String("test-device-code"),
+		UserCode:                aws.String("ABCD-EFGH"),
+		VerificationUri:         aws.String("https://device.sso.us-east-1.amazonaws.com/"),
+		VerificationUriComplete: aws.String("https://device.sso.us-east-1.amazonaws.com/?user_code=ABCD-EFGH"),
+		ExpiresIn:               600,
+		Interval:                1,
+	}
+}
+
+func TestPrintDeviceCode(t *testing.T) {
+	t.Run("prints uri, code and expiry", func(t *testing.T) {
+		var out bytes.Buffer
+		now := time.Date(2025, 1, 1, 15, 0, 0, 0, time.UTC)
+
+		printDeviceCode(&out, testDeviceAuth(), false, now)
+
+		assert.Contains(t, out.String(), "https://device.sso.us-east-1.amazonaws.com/\n")
+		assert.Contains(t, out.String(), "enter the code: ABCD-EFGH")
+		assert.Contains(t, out.String(), "?user_code=ABCD-EFGH")
+		assert.Contains(t, out.String(), "expires in 10m0s (at 3:10PM)")
+	})
+
+	t.Run("renders qr code when requested", func(t *testing.T) {
+		var plain, withQR bytes.Buffer
+		now := time.Now()
+
+		printDeviceCode(&plain, testDeviceAuth(), false, now)
+		printDeviceCode(&withQR, testDeviceAuth(), true, now)
+
+		assert.Greater(t, withQR.Len(), plain.Len())
+		assert.Contains(t, withQR.String(), "█")
+	})
+}
+
+func TestPresentDeviceAuthorization(t *testing.T) {
+	t.Run("headless mode never opens a browser", func(t *testing.T) {
+		var out bytes.Buffer
+		opener := func(string) error {
+			t.Fatal("browser should not be opened in headless mode")
+			return nil
+		}
+
+		presentDeviceAuthorization(testDeviceAuth(), opener, LoginOptions{NoBrowser: true, Out: &out})
+
+		assert.Contains(t, out.String(), "ABCD-EFGH")
+		assert.NotContains(t, out.String(), "Opening browser")
+	})
+
+	t.Run("falls back to the user code when the browser fails", func(t *testing.T) {
+		var out bytes.Buffer
+		opener := func(string) error { return errors.New("no browser") }
+
+		presentDeviceAuthorization(testDeviceAuth(), opener, LoginOptions{Out: &out})
+
+		assert.Contains(t, out.String(), "Failed to open browser automatically")
+		assert.Contains(t, out.String(), "enter the code: ABCD-EFGH")
+	})
+
+	t.Run("opens the browser when available", func(t *testing.T) {
+		var out bytes.Buffer
+		var opened string
+		opener := func(url string) error {
+			opened = url
+			return nil
+		}
+
+		presentDeviceAuthorization(testDeviceAuth(), opener, LoginOptions{Out: &out})
+
+		assert.Equal(t, "https://device.sso.us-east-1.amazonaws.com/?user_code=ABCD-EFGH", opened)
+		assert.NotContains(t, out.String(), "enter the code")
+	})
+}
+
+func TestAWSProviderGenerateTokenHeadless(t *testing.T) {
+	mockClient := new(MockSSOOIDCClient)
+	mockRegister := &ssooidc.RegisterClientOutput{
+		ClientId:     aws.String("test-client-id"),
+		ClientSecret: aws.String("test-client-secret"),
+	}
+	mockClient.On("RegisterClient", mock.Anything, mock.Anything, mock.Anything).Return(mockRegister, nil)
+	mockClient.On("StartDeviceAuthorization", mock.Anything, mock.Anything, mock.Anything).Return(testDeviceAuth(), nil)
+
+	var out bytes.Buffer
+	provider := &AWSProvider{
+		SSOOIDCClient: mockClient,
+		BrowserOpener: func(string) error {
+			t.Fatal("browser should not be opened in headless mode")
+			return nil
+		},
+		TokenPoller: func(_ context.Context, client SSOOIDCClient, register *ssooidc.RegisterClientOutput, deviceAuth *ssooidc.StartDeviceAuthorizationOutput) (*ssooidc.CreateTokenOutput, error) {
+			return &ssooidc.CreateTokenOutput{AccessToken: aws.String("test-access-token"), ExpiresIn: 3600}, nil
+		},
+		Options: LoginOptions{NoBrowser: true, Out: &out},
+	}
+
+	token, err := provider.GenerateToken(context.Background(), &appconfig.Config{SSO: appconfig.SSOConfig{StartURL: "https://test-sso-url.com"}})
+
+	require.NoError(t, err)
+	assert.Equal(t, "test-access-token", token.AccessToken)
+	assert.Contains(t, out.String(), "enter the code: ABCD-EFGH")
+	mockClient.AssertExpectations(t)
+}
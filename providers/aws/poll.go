@@ -0,0 +1,155 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc/types"
+)
+
+const (
+	// defaultPollInterval is the RFC 8628 default when the server does not send an interval
+	defaultPollInterval = 5 * time.Second
+	// slowDownIncrement is added to the interval on every slow_down response, as RFC 8628 section 3.5 requires
+	slowDownIncrement = 5 * time.Second
+	// defaultDeviceCodeLifetime applies when the server does not say how long the device code is valid
+	defaultDeviceCodeLifetime = 10 * time.Minute
+	// createTokenTimeout bounds each individual CreateToken request
+	createTokenTimeout = 10 * time.Second
+	// progressInterval is how often a waiting message is printed
+	progressInterval = 30 * time.Second
+
+	deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+)
+
+// Clock abstracts time so that polling can be tested without sleeping
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// systemClock is the Clock backed by the time package
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// tokenPoller waits for the user to approve a device authorization
+type tokenPoller struct {
+	client SSOOIDCClient
+	clock  Clock
+	out    io.Writer
+}
+
+func newTokenPoller(client SSOOIDCClient, clock Clock, out io.Writer) *tokenPoller {
+	return &tokenPoller{client: client, clock: clock, out: out}
+}
+
+// pollForToken polls with the system clock, reporting progress on stdout
+func pollForToken(
+	ctx context.Context,
+	ssooidcClient SSOOIDCClient,
+	register *ssooidc.RegisterClientOutput,
+	deviceAuth *ssooidc.StartDeviceAuthorizationOutput,
+) (*ssooidc.CreateTokenOutput, error) {
+	return newTokenPoller(ssooidcClient, systemClock{}, os.Stdout).poll(ctx, register, deviceAuth)
+}
+
+// poll calls CreateToken every deviceAuth.Interval seconds until the user approves the
+// device, the server rejects it, the device code expires after deviceAuth.ExpiresIn seconds
+// or ctx is cancelled
+func (tp *tokenPoller) poll(
+	ctx context.Context,
+	register *ssooidc.RegisterClientOutput,
+	deviceAuth *ssooidc.StartDeviceAuthorizationOutput,
+) (*ssooidc.CreateTokenOutput, error) {
+	interval := time.Duration(deviceAuth.Interval) * time.Second
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	lifetime := time.Duration(deviceAuth.ExpiresIn) * time.Second
+	if lifetime <= 0 {
+		lifetime = defaultDeviceCodeLifetime
+	}
+
+	start := tp.clock.Now()
+	deadline := start.Add(lifetime)
+	lastProgress := start
+
+	for {
+		// Checked first as well, select picks randomly when both channels are ready
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrLoginCancelled, err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %w", ErrLoginCancelled, ctx.Err())
+		case <-tp.clock.After(interval):
+		}
+
+		now := tp.clock.Now()
+		if !now.Before(deadline) {
+			return nil, ErrAuthorizationExpired
+		}
+
+		token, err := tp.createToken(ctx, register, deviceAuth)
+		if err == nil {
+			fmt.Fprintln(tp.out, "✓ Authorization successful!")
+			return token, nil
+		}
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("%w: %w", ErrLoginCancelled, ctx.Err())
+		}
+
+		var pending *types.AuthorizationPendingException
+		var slowDown *types.SlowDownException
+		switch {
+		case errors.As(err, &pending):
+			if now.Sub(lastProgress) >= progressInterval {
+				fmt.Fprintf(tp.out, "Still waiting for authorization... (%s remaining)\n", deadline.Sub(now).Round(time.Second))
+				lastProgress = now
+			}
+		case errors.As(err, &slowDown):
+			interval += slowDownIncrement
+		default:
+			return nil, classifyCreateTokenError(err)
+		}
+	}
+}
+
+// createToken makes a single CreateToken request with its own timeout
+func (tp *tokenPoller) createToken(
+	ctx context.Context,
+	register *ssooidc.RegisterClientOutput,
+	deviceAuth *ssooidc.StartDeviceAuthorizationOutput,
+) (*ssooidc.CreateTokenOutput, error) {
+	ctx, cancel := context.WithTimeout(ctx, createTokenTimeout)
+	defer cancel()
+
+	return tp.client.CreateToken(ctx, &ssooidc.CreateTokenInput{
+		ClientId:     register.ClientId,
+		ClientSecret: register.ClientSecret,
+		DeviceCode:   deviceAuth.DeviceCode,
+		GrantType:    aws.String(deviceCodeGrantType),
+	})
+}
+
+// classifyCreateTokenError wraps terminal CreateToken errors in the matching sentinel error
+func classifyCreateTokenError(err error) error {
+	var denied *types.AccessDeniedException
+	var expired *types.ExpiredTokenException
+	switch {
+	case errors.As(err, &denied):
+		return fmt.Errorf("%w: %w", ErrAuthorizationDenied, err)
+	case errors.As(err, &expired):
+		return fmt.Errorf("%w: %w", ErrAuthorizationExpired, err)
+	default:
+		return fmt.Errorf("authorization error: %w", err)
+	}
+}
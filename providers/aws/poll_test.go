@@ -0,0 +1,185 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a Clock that advances instantly whenever the poller waits
+type fakeClock struct {
+	mu    sync.Mutex
+	now   time.Time
+	waits []time.Duration
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	c.waits = append(c.waits, d)
+
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+func (c *fakeClock) Waits() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]time.Duration(nil), c.waits...)
+}
+
+var (
+	pollRegister = &ssooidc.RegisterClientOutput{
+		ClientId:     aws.String("client-id"),
+		ClientSecret: aws.String("client-secret"),
+	}
+	pendingErr  = &types.AuthorizationPendingException{Message: aws.String("authorization_pending")}
+	slowDownErr = &types.SlowDownException{Message: aws.String("slow_down")}
+)
+
+func pollDeviceAuth(interval, expiresIn int32) *ssooidc.StartDeviceAuthorizationOutput {
+	return &ssooidc.StartDeviceAuthorizationOutput{
+		DeviceCode: aws.String("device-code"),
+		Interval:   interval,
+		ExpiresIn:  expiresIn,
+	}
+}
+
+func TestPollUsesDeviceAuthorizationInterval(t *testing.T) {
+	client := new(MockSSOOIDCClient)
+	client.On("CreateToken", mock.Anything, mock.Anything, mock.Anything).Return(nil, pendingErr).Twice()
+	client.On("CreateToken", mock.Anything, mock.MatchedBy(func(input *ssooidc.CreateTokenInput) bool {
+		return aws.ToString(input.DeviceCode) == "device-code" && aws.ToString(input.GrantType) == deviceCodeGrantType
+	}), mock.Anything).Return(&ssooidc.CreateTokenOutput{AccessToken: aws.String("token")}, nil).Once()
+
+	clock := newFakeClock()
+	token, err := newTokenPoller(client, clock, io.Discard).poll(context.Background(), pollRegister, pollDeviceAuth(2, 600))
+
+	require.NoError(t, err)
+	assert.Equal(t, "token", aws.ToString(token.AccessToken))
+	assert.Equal(t, []time.Duration{2 * time.Second, 2 * time.Second, 2 * time.Second}, clock.Waits())
+	client.AssertNumberOfCalls(t, "CreateToken", 3)
+}
+
+func TestPollDefaultsIntervalWhenMissing(t *testing.T) {
+	client := new(MockSSOOIDCClient)
+	client.On("CreateToken", mock.Anything, mock.Anything, mock.Anything).Return(&ssooidc.CreateTokenOutput{AccessToken: aws.String("token")}, nil)
+
+	clock := newFakeClock()
+	_, err := newTokenPoller(client, clock, io.Discard).poll(context.Background(), pollRegister, pollDeviceAuth(0, 0))
+
+	require.NoError(t, err)
+	assert.Equal(t, []time.Duration{defaultPollInterval}, clock.Waits())
+}
+
+func TestPollSlowDownIncreasesInterval(t *testing.T) {
+	client := new(MockSSOOIDCClient)
+	client.On("CreateToken", mock.Anything, mock.Anything, mock.Anything).Return(nil, slowDownErr).Twice()
+	client.On("CreateToken", mock.Anything, mock.Anything, mock.Anything).Return(nil, pendingErr).Once()
+	client.On("CreateToken", mock.Anything, mock.Anything, mock.Anything).Return(&ssooidc.CreateTokenOutput{AccessToken: aws.String("token")}, nil).Once()
+
+	clock := newFakeClock()
+	_, err := newTokenPoller(client, clock, io.Discard).poll(context.Background(), pollRegister, pollDeviceAuth(1, 600))
+
+	require.NoError(t, err)
+	// Every slow_down permanently adds five seconds to the interval
+	assert.Equal(t, []time.Duration{1 * time.Second, 6 * time.Second, 11 * time.Second, 11 * time.Second}, clock.Waits())
+}
+
+func TestPollStopsWhenDeviceCodeExpires(t *testing.T) {
+	client := new(MockSSOOIDCClient)
+	client.On("CreateToken", mock.Anything, mock.Anything, mock.Anything).Return(nil, pendingErr)
+
+	clock := newFakeClock()
+	start := clock.Now()
+	token, err := newTokenPoller(client, clock, io.Discard).poll(context.Background(), pollRegister, pollDeviceAuth(5, 60))
+
+	assert.Nil(t, token)
+	assert.ErrorIs(t, err, ErrAuthorizationExpired)
+	// Polls at 5s, 10s, ... 55s and gives up at 60s without another request
+	client.AssertNumberOfCalls(t, "CreateToken", 11)
+	assert.Equal(t, start.Add(60*time.Second), clock.Now())
+}
+
+func TestPollUsesFreshContextPerRequest(t *testing.T) {
+	var contexts []context.Context
+	client := new(MockSSOOIDCClient)
+	client.On("CreateToken", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		ctx := args.Get(0).(context.Context)
+		assert.NoError(t, ctx.Err(), "context should not be expired when the request is made")
+		_, hasDeadline := ctx.Deadline()
+		assert.True(t, hasDeadline, "each request should be bounded by a timeout")
+		contexts = append(contexts, ctx)
+	}).Return(nil, pendingErr).Twice()
+	client.On("CreateToken", mock.Anything, mock.Anything, mock.Anything).Return(&ssooidc.CreateTokenOutput{AccessToken: aws.String("token")}, nil).Once()
+
+	_, err := newTokenPoller(client, newFakeClock(), io.Discard).poll(context.Background(), pollRegister, pollDeviceAuth(1, 600))
+
+	require.NoError(t, err)
+	require.Len(t, contexts, 2)
+	assert.NotSame(t, contexts[0], contexts[1])
+	assert.Error(t, contexts[0].Err(), "request context should be released after the call")
+}
+
+func TestPollStopsWhenContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	client := new(MockSSOOIDCClient)
+	client.On("CreateToken", mock.Anything, mock.Anything, mock.Anything).Run(func(mock.Arguments) {
+		cancel()
+	}).Return(nil, pendingErr).Once()
+
+	token, err := newTokenPoller(client, newFakeClock(), io.Discard).poll(ctx, pollRegister, pollDeviceAuth(1, 600))
+
+	assert.Nil(t, token)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, err, ErrLoginCancelled)
+	client.AssertNumberOfCalls(t, "CreateToken", 1)
+}
+
+func TestPollTerminalErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		contains string
+	}{
+		{"access denied", &types.AccessDeniedException{Message: aws.String("access_denied")}, "denied"},
+		{"expired token", &types.ExpiredTokenException{Message: aws.String("expired_token")}, "expired"},
+		{"other error", errors.New("boom"), "authorization error: boom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := new(MockSSOOIDCClient)
+			client.On("CreateToken", mock.Anything, mock.Anything, mock.Anything).Return(nil, tt.err).Once()
+
+			token, err := newTokenPoller(client, newFakeClock(), io.Discard).poll(context.Background(), pollRegister, pollDeviceAuth(1, 600))
+
+			assert.Nil(t, token)
+			assert.ErrorContains(t, err, tt.contains)
+			assert.ErrorIs(t, err, tt.err)
+			client.AssertNumberOfCalls(t, "CreateToken", 1)
+		})
+	}
+}
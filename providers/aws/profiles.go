@@ -2,50 +2,231 @@ package aws
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/bigkevmcd/go-configparser"
+	"github.com/mitchellh/go-homedir"
+
+	appconfig "github.com/blairham/aws-sso-config/providers/config"
 )
 
 const AwsProfile = "AWS_PROFILE"
 
 var Logger *log.Logger = log.New(os.Stderr, "", 0)
 
-func validateAccountID(accountID, rootDir string) error {
-	terragruntFile := filepath.Join(rootDir, "terragrunt.hcl")
+// ProfileSource tells how the profile for a directory was chosen
+type ProfileSource string
+
+const (
+	// ProfileSourceEnvironment means AWS_PROFILE was already set
+	ProfileSourceEnvironment ProfileSource = "environment"
+	// ProfileSourceRepos means a [[repos]] mapping matched the repository
+	ProfileSourceRepos ProfileSource = "repos"
+	// ProfileSourceRepoName means the profile is named after the repository
+	ProfileSourceRepoName ProfileSource = "repository name"
+	// ProfileSourceDefault means no profile could be determined
+	ProfileSourceDefault ProfileSource = "default"
+)
+
+// ProfileResolution is the profile chosen for a directory and how it was chosen
+type ProfileResolution struct {
+	Profile string
+	Source  ProfileSource
+	// RepoRoot is the git repository containing the directory, empty outside of one
+	RepoRoot string
+	// Remote is the URL of the repository's origin remote
+	Remote string
+	// Rule is the [[repos]] mapping that matched
+	Rule *appconfig.RepoConfig
+	// AccountID is the account of the chosen profile
+	AccountID string
+	// Fallback is why the default profile is used
+	Fallback string
+	// Steps explain the decisions in order
+	Steps []string
+}
+
+func (r *ProfileResolution) explain(format string, args ...any) {
+	r.Steps = append(r.Steps, fmt.Sprintf(format, args...))
+}
+
+// useDefault falls back to the default profile for the given reason
+func (r *ProfileResolution) useDefault(reason string) *ProfileResolution {
+	r.Profile = "default"
+	r.Source = ProfileSourceDefault
+	r.Fallback = reason
+	r.explain("%s, using the default profile", reason)
+	return r
+}
+
+// ResolveRepoProfile chooses the profile for dir. AWS_PROFILE takes precedence, then the first
+// [[repos]] mapping matching the git repository, then a profile named after the repository
+// whose account matches the account_id in its terragrunt.hcl. Otherwise the default profile
+// is used. A mapping to a profile that is missing or belongs to another account is an error.
+func ResolveRepoProfile(appCfg *appconfig.Config, dir string) (*ProfileResolution, error) {
+	r := &ProfileResolution{}
 
-	readFile, err := os.Open(terragruntFile)
+	if profile := os.Getenv(AwsProfile); profile != "" {
+		r.Profile = profile
+		r.Source = ProfileSourceEnvironment
+		r.explain("%s is set to %s, which takes precedence", AwsProfile, profile)
+		return r, nil
+	}
+
+	root, err := findRepoRoot(dir)
 	if err != nil {
-		return fmt.Errorf("could not find terragrunt.hcl at root of git repo %s", rootDir)
+		return nil, err
+	}
+	if root == "" {
+		return r.useDefault(fmt.Sprintf("%s is not inside a git repository", dir)), nil
+	}
+	r.RepoRoot = root
+	r.Remote = originURL(root)
+	if r.Remote != "" {
+		r.explain("found git repository %s with origin %s", root, r.Remote)
+	} else {
+		r.explain("found git repository %s without an origin remote", root)
+	}
+
+	if rule := appCfg.Repos.Match(root, r.Remote); rule != nil {
+		r.Profile = rule.Profile
+		r.Source = ProfileSourceRepos
+		r.Rule = rule
+		r.explain("%s maps it to profile %s", rule, rule.Profile)
+		return r, r.checkMappedProfile(appCfg.ConfigFile())
+	}
+
+	r.explain("no [[repos]] entry matches, trying the repository name")
+	profile := getProfileFromRepoName(filepath.Base(root))
+	accountID, err := validateProfile(appCfg.ConfigFile(), profile, root)
+	if err != nil {
+		return r.useDefault(err.Error()), nil
+	}
+	r.Profile = profile
+	r.Source = ProfileSourceRepoName
+	r.AccountID = accountID
+	r.explain("profile %s has account %s, matching account_id in %s", profile, accountID, TerragruntFile)
+	return r, nil
+}
+
+// checkMappedProfile checks that a mapped profile exists and, when the repository has a
+// terragrunt.hcl with an account_id, that it belongs to that account
+func (r *ProfileResolution) checkMappedProfile(configFile string) error {
+	accountID, err := profileAccountID(configFile, r.Profile)
+	if err != nil {
+		return err
+	}
+	r.AccountID = accountID
+
+	err = validateAccountID(accountID, r.RepoRoot)
+	switch {
+	case errors.Is(err, os.ErrNotExist), errors.Is(err, errNoAccountID):
+		r.explain("profile %s has account %s", r.Profile, accountID)
+		return nil
+	case err != nil:
+		return err
 	}
+	r.explain("profile %s has account %s, matching account_id in %s", r.Profile, accountID, TerragruntFile)
+	return nil
+}
 
-	defer func() {
-		if closeErr := readFile.Close(); closeErr != nil {
-			fmt.Printf("Warning: failed to close file: %v\n", closeErr)
+// findRepoRoot returns the closest directory from dir upwards that contains .git, or ""
+func findRepoRoot(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		// .git is a directory, or a file in worktrees and submodules
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir, nil
 		}
-	}()
-	fileScanner := bufio.NewScanner(readFile)
-
-	fileScanner.Split(bufio.ScanLines)
-	accountIDFromTerragrunt := ""
-	for fileScanner.Scan() {
-		line := fileScanner.Text()
-		lineFields := strings.Fields(line)
-		if len(lineFields) < 3 {
-			continue
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
 		}
-		if lineFields[0] != "account_id" {
+		dir = parent
+	}
+}
+
+// originURL returns the URL of the origin remote from the repository's git config, or ""
+func originURL(root string) string {
+	configFile, err := gitConfigFile(root)
+	if err != nil {
+		return ""
+	}
+	file, err := os.Open(configFile) // #nosec G304 - git config of the repository being inspected
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	inOrigin := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inOrigin = line == `[remote "origin"]`
 			continue
 		}
-		accountIDFromTerragrunt = strings.Trim(lineFields[2], "\"")
-		break
+		if key, value, ok := strings.Cut(line, "="); inOrigin && ok && strings.TrimSpace(key) == "url" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// gitConfigFile returns the config file of a repository, following the gitdir file of
+// worktrees to the repository they belong to
+func gitConfigFile(root string) (string, error) {
+	dotGit := filepath.Join(root, ".git")
+	info, err := os.Stat(dotGit)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return filepath.Join(dotGit, "config"), nil
+	}
+
+	content, err := os.ReadFile(dotGit) // #nosec G304 - .git file of the repository being inspected
+	if err != nil {
+		return "", err
 	}
-	if accountIDFromTerragrunt == "" {
-		return fmt.Errorf("could not determine account id from %s", terragruntFile)
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(content)), "gitdir:")
+	if !ok {
+		return "", fmt.Errorf("unexpected content in %s", dotGit)
+	}
+	gitDir = strings.TrimSpace(gitDir)
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(root, gitDir)
+	}
+	if commonDir, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil { // #nosec G304
+		common := strings.TrimSpace(string(commonDir))
+		if !filepath.IsAbs(common) {
+			common = filepath.Join(gitDir, common)
+		}
+		gitDir = common
+	}
+	return filepath.Join(gitDir, "config"), nil
+}
+
+func validateAccountID(accountID, rootDir string) error {
+	terragruntFile := filepath.Join(rootDir, TerragruntFile)
+
+	accountIDFromTerragrunt, err := terragruntAccountID(terragruntFile)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return fmt.Errorf("could not find terragrunt.hcl at root of git repo %s: %w", rootDir, err)
+	case errors.Is(err, errNoAccountID):
+		return fmt.Errorf("could not determine account id from %s: %w", terragruntFile, err)
+	case err != nil:
+		return err
 	}
 	if accountIDFromTerragrunt != accountID {
 		return fmt.Errorf("account id %s determined from profile did not match entry in terragrunt file %s", accountID, terragruntFile)
@@ -60,36 +241,48 @@ func getProfileFromRepoName(repo string) string {
 	return repo
 }
 
-// Exit if profile does not appear to be valid
-func validateProfile(awsProfile, rootDir string) error {
-	configFile, err := ConfigFile()
+// validateProfile checks that a profile exists and belongs to the account in the repository's
+// terragrunt.hcl, returning its account ID
+func validateProfile(configFile, awsProfile, rootDir string) (string, error) {
+	accountID, err := profileAccountID(configFile, awsProfile)
 	if err != nil {
-		return fmt.Errorf("failed to get config file path: %w", err)
+		return "", err
+	}
+	if err := validateAccountID(accountID, rootDir); err != nil {
+		return "", err
+	}
+	return accountID, nil
+}
+
+// profileAccountID returns the account of a profile, from sso_account_id or role_arn
+func profileAccountID(configFile, awsProfile string) (string, error) {
+	configFile, err := homedir.Expand(configFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", configFile, err)
 	}
 
 	awsConfig, err := configparser.NewConfigParserFromFile(configFile)
 	if err != nil {
-		return err
+		return "", fmt.Errorf("failed to read %s: %w", configFile, err)
 	}
 	section := fmt.Sprintf("profile %s", awsProfile)
 
 	if !awsConfig.HasSection(section) {
-		return fmt.Errorf("could not find profile for %s", awsProfile)
+		return "", fmt.Errorf("could not find profile for %s in %s", awsProfile, configFile)
 	}
-	accountID, err := awsConfig.Get(section, "sso_account_id")
-	if err != nil {
-		return fmt.Errorf("error parsing aws config %s: %s", awsProfile, err)
+	if accountID, _ := awsConfig.Get(section, "sso_account_id"); accountID != "" {
+		return accountID, nil
 	}
-	err = validateAccountID(accountID, rootDir)
-	if err != nil {
-		return err
+	roleARN, _ := awsConfig.Get(section, "role_arn")
+	if accountID, _, ok := parseRoleARN(roleARN); ok {
+		return accountID, nil
 	}
-
-	Logger.Printf("Using profile %s (%s)", awsProfile, accountID)
-	return nil
+	return "", fmt.Errorf("profile %s has neither sso_account_id nor role_arn", awsProfile)
 }
 
-func GetProfile() (string, error) {
+// GetProfile returns the profile for the current directory as chosen by ResolveRepoProfile,
+// or "" when AWS_PROFILE is already set
+func GetProfile(appCfg *appconfig.Config) (string, error) {
 	val, present := os.LookupEnv(AwsProfile)
 	if present {
 		Logger.Printf("%s is already set to %s (potentially by direnv?), skipping setup", AwsProfile, val)
@@ -99,23 +292,100 @@ func GetProfile() (string, error) {
 	if err != nil {
 		return "", err
 	}
-	for {
-		_, err = os.Stat(filepath.Join(cwd, ".git"))
-		if err == nil {
-			break
+
+	r, err := ResolveRepoProfile(appCfg, cwd)
+	if err != nil {
+		return "", err
+	}
+	if r.Source == ProfileSourceDefault {
+		Logger.Printf("Using the default profile: %s", r.Fallback)
+		return r.Profile, nil
+	}
+
+	Logger.Printf("Using profile %s (%s)", r.Profile, r.AccountID)
+	return r.Profile, nil
+}
+
+// ProfileSummary describes a profile of the AWS config file for listing and searching
+type ProfileSummary struct {
+	Name      string
+	AccountID string
+	RoleName  string
+	// Email is the account's email address, recorded by generate as sso_account_email
+	Email  string
+	Region string
+	// SourceProfile is set for assume-role profiles chained on another profile
+	SourceProfile string
+}
+
+// ListProfiles returns the profiles in an AWS config file sorted by name. The account and
+// role of assume-role profiles are read from their role_arn, and their email from the
+// profile they are sourced from.
+func ListProfiles(configFile string) ([]ProfileSummary, error) {
+	configFile, err := homedir.Expand(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", configFile, err)
+	}
+
+	awsConfig, err := configparser.NewConfigParserFromFile(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", configFile, err)
+	}
+
+	get := func(section, option string) string {
+		value, _ := awsConfig.Get(section, option)
+		return value
+	}
+
+	byName := map[string]ProfileSummary{}
+	for _, section := range awsConfig.Sections() {
+		name, ok := strings.CutPrefix(section, "profile ")
+		if !ok {
+			continue
+		}
+		profile := ProfileSummary{
+			Name:          name,
+			AccountID:     get(section, "sso_account_id"),
+			RoleName:      get(section, "sso_role_name"),
+			Email:         get(section, "sso_account_email"),
+			Region:        get(section, "region"),
+			SourceProfile: get(section, "source_profile"),
 		}
-		cwd = filepath.Dir(cwd)
-		if cwd == "/" {
-			// If no git repository is found, return the default profile
-			return "default", nil
+		if accountID, roleName, ok := parseRoleARN(get(section, "role_arn")); ok {
+			profile.AccountID = accountID
+			profile.RoleName = roleName
 		}
+		byName[name] = profile
 	}
-	repoName := filepath.Base(cwd)
-	profile := getProfileFromRepoName(repoName)
-	err = validateProfile(profile, cwd)
-	if err != nil {
-		// If there's an error validating the profile (e.g., no remotes), return default
-		return "default", nil
+
+	profiles := make([]ProfileSummary, 0, len(byName))
+	for _, profile := range byName {
+		// Follow source profiles for the email, guarding against cycles in hand written files
+		source := profile.SourceProfile
+		for hops := 0; profile.Email == "" && source != "" && hops < len(byName); hops++ {
+			profile.Email = byName[source].Email
+			source = byName[source].SourceProfile
+		}
+		profiles = append(profiles, profile)
+	}
+
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Name < profiles[j].Name
+	})
+
+	return profiles, nil
+}
+
+// parseRoleARN returns the account ID and role name of an IAM role ARN such as
+// arn:aws:iam::123456789012:role/path/Deploy
+func parseRoleARN(arn string) (accountID, roleName string, ok bool) {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) != 6 || parts[0] != "arn" || parts[2] != "iam" {
+		return "", "", false
+	}
+	resource, ok := strings.CutPrefix(parts[5], "role/")
+	if !ok {
+		return "", "", false
 	}
-	return profile, nil
+	return parts[4], resource[strings.LastIndex(resource, "/")+1:], true
 }
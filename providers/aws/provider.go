@@ -2,6 +2,7 @@ package aws
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -26,60 +27,200 @@ type SSOOIDCClient interface {
 type AWSProvider struct {
 	SSOOIDCClient SSOOIDCClient
 	BrowserOpener func(string) error
-	TokenPoller   func(client SSOOIDCClient, register *ssooidc.RegisterClientOutput, deviceAuth *ssooidc.StartDeviceAuthorizationOutput) *string
-	Cfg           aws.Config
+	TokenPoller   func(
+		ctx context.Context,
+		client SSOOIDCClient,
+		register *ssooidc.RegisterClientOutput,
+		deviceAuth *ssooidc.StartDeviceAuthorizationOutput,
+	) (*ssooidc.CreateTokenOutput, error)
+	Cfg     aws.Config
+	Options LoginOptions
+	// Store overrides the token store selected by sso.token_store
+	Store TokenStore
 }
 
 // Creates a new default AWS provider
-func NewDefaultAWSProvider() *AWSProvider {
-	cfg := LoadDefaultConfig()
-	ssooidcClient := ssooidc.NewFromConfig(cfg)
+func NewDefaultAWSProvider(ctx context.Context) (*AWSProvider, error) {
+	cfg, err := LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewAWSProvider(cfg, DefaultLoginOptions()), nil
+}
 
+// Creates a new AWS provider for the given AWS configuration and login options
+func NewAWSProvider(cfg aws.Config, opts LoginOptions) *AWSProvider {
 	return &AWSProvider{
-		SSOOIDCClient: ssooidcClient,
+		SSOOIDCClient: ssooidc.NewFromConfig(cfg),
 		BrowserOpener: browser.OpenURL,
-		TokenPoller: func(client SSOOIDCClient, register *ssooidc.RegisterClientOutput, deviceAuth *ssooidc.StartDeviceAuthorizationOutput) *string {
-			// We need to cast the interface to the concrete type
-			if ssoClient, ok := client.(*ssooidc.Client); ok {
-				return pollForToken(ssoClient, register, deviceAuth)
-			}
-			return nil
+		TokenPoller: func(
+			ctx context.Context,
+			client SSOOIDCClient,
+			register *ssooidc.RegisterClientOutput,
+			deviceAuth *ssooidc.StartDeviceAuthorizationOutput,
+		) (*ssooidc.CreateTokenOutput, error) {
+			return newTokenPoller(client, systemClock{}, opts.writer()).poll(ctx, register, deviceAuth)
 		},
-		Cfg: cfg,
+		Cfg:     cfg,
+		Options: opts,
+	}
+}
+
+// GenerateToken runs the device authorization flow with the provider's configuration
+func (p *AWSProvider) GenerateToken(ctx context.Context, appCfg *appconfig.Config) (Token, error) {
+	entry, err := p.authorize(ctx, appCfg)
+	if err != nil {
+		return Token{}, fmt.Errorf("SSO login to %s failed: %w", appCfg.SSOStartURL(), err)
 	}
+
+	return newToken(entry, TokenSourceInteractive), nil
 }
 
-// Generate token with provider's configuration
-func (p *AWSProvider) GenerateToken(appCfg *appconfig.Config) *string {
+// GetToken returns the cached token for the configured start URL. An expired token is
+// refreshed with the cached refresh token when possible, otherwise the user logs in again.
+func (p *AWSProvider) GetToken(ctx context.Context, appCfg *appconfig.Config) (Token, error) {
+	store, err := p.tokenStore(appCfg)
+	if err != nil {
+		return Token{}, err
+	}
+
+	now := time.Now()
+	cached, err := store.Load(appCfg.SSOStartURL())
+	if err == nil && cached.Valid(now) {
+		return newToken(cached, TokenSourceCache), nil
+	}
+
+	if err == nil && cached.canRefresh(now) {
+		refreshed, refreshErr := p.refresh(ctx, cached)
+		if refreshErr == nil {
+			if err := store.Save(refreshed); err != nil {
+				return Token{}, fmt.Errorf("failed to cache SSO token: %w", err)
+			}
+			return newToken(refreshed, TokenSourceRefresh), nil
+		}
+		if ctx.Err() != nil {
+			return Token{}, fmt.Errorf("%w: %w", ErrLoginCancelled, ctx.Err())
+		}
+		// The refresh token may have been revoked, fall back to an interactive login
+		fmt.Fprintf(p.Options.writer(), "%v, logging in again\n", refreshErr)
+	}
+
+	entry, err := p.Login(ctx, appCfg)
+	if err != nil {
+		return Token{}, err
+	}
+
+	return newToken(entry, TokenSourceInteractive), nil
+}
+
+// Login runs the device authorization flow and caches the resulting token
+func (p *AWSProvider) Login(ctx context.Context, appCfg *appconfig.Config) (*SSOCacheEntry, error) {
+	store, err := p.tokenStore(appCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := p.authorize(ctx, appCfg)
+	if err != nil {
+		return nil, fmt.Errorf("SSO login to %s failed: %w", appCfg.SSOStartURL(), err)
+	}
+
+	if err := store.Save(entry); err != nil {
+		return nil, fmt.Errorf("failed to cache SSO token: %w", err)
+	}
+
+	return entry, nil
+}
+
+// tokenStore returns the provider's store, or the one configured with sso.token_store
+func (p *AWSProvider) tokenStore(appCfg *appconfig.Config) (TokenStore, error) {
+	if p.Store != nil {
+		return p.Store, nil
+	}
+
+	return NewTokenStore(appCfg.SSOTokenStore())
+}
+
+// authorize registers a client, asks the user to approve the device and waits for the token
+func (p *AWSProvider) authorize(ctx context.Context, appCfg *appconfig.Config) (*SSOCacheEntry, error) {
 	// create sso oidc client to trigger login flow
 	ssooidcClient := p.SSOOIDCClient
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	reqCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
 	// register your client which is triggering the login flow
-	register, err := ssooidcClient.RegisterClient(ctx, &ssooidc.RegisterClientInput{
+	register, err := ssooidcClient.RegisterClient(reqCtx, &ssooidc.RegisterClientInput{
 		ClientName: aws.String("aws-sso-config-cli"),
 		ClientType: aws.String("public"),
 		Scopes:     []string{"sso-portal:*"},
 	})
 	if err != nil {
-		return nil
+		return nil, fmt.Errorf("%w: %w", ErrRegistrationFailed, err)
 	}
 
 	// authorize your device using the client registration response
-	deviceAuth, err := ssooidcClient.StartDeviceAuthorization(ctx, &ssooidc.StartDeviceAuthorizationInput{
+	deviceAuth, err := ssooidcClient.StartDeviceAuthorization(reqCtx, &ssooidc.StartDeviceAuthorizationInput{
 		ClientId:     register.ClientId,
 		ClientSecret: register.ClientSecret,
 		StartUrl:     aws.String(appCfg.SSOStartURL()),
 	})
 	if err != nil {
-		return nil
+		return nil, fmt.Errorf("%w: %w", ErrDeviceAuthorizationFailed, err)
+	}
+
+	// trigger OIDC login. open browser (or print the user code) and wait for authorization
+	presentDeviceAuthorization(deviceAuth, p.BrowserOpener, p.Options)
+
+	token, err := p.TokenPoller(ctx, ssooidcClient, register, deviceAuth)
+	if err != nil {
+		return nil, err
+	}
+
+	return newCacheEntry(appCfg, register, token, time.Now()), nil
+}
+
+// CheckStartURL registers a client and starts a device authorization for startURL,
+// which the user is never asked to approve, to check that the SSO OIDC service in the
+// client's region knows the start URL
+func CheckStartURL(ctx context.Context, client SSOOIDCClient, startURL string) error {
+	reqCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	register, err := client.RegisterClient(reqCtx, &ssooidc.RegisterClientInput{
+		ClientName: aws.String("aws-sso-config-cli"),
+		ClientType: aws.String("public"),
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrRegistrationFailed, err)
 	}
 
-	// trigger OIDC login. open browser to login and wait for authorization
-	url := aws.ToString(deviceAuth.VerificationUriComplete)
-	_ = p.BrowserOpener(url)
+	_, err = client.StartDeviceAuthorization(reqCtx, &ssooidc.StartDeviceAuthorizationInput{
+		ClientId:     register.ClientId,
+		ClientSecret: register.ClientSecret,
+		StartUrl:     aws.String(startURL),
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDeviceAuthorizationFailed, err)
+	}
+	return nil
+}
+
+// newCacheEntry combines the client registration and the created token into a cache entry
+func newCacheEntry(appCfg *appconfig.Config, register *ssooidc.RegisterClientOutput, token *ssooidc.CreateTokenOutput, now time.Time) *SSOCacheEntry {
+	entry := &SSOCacheEntry{
+		StartURL:     appCfg.SSOStartURL(),
+		Region:       appCfg.SSORegion(),
+		AccessToken:  aws.ToString(token.AccessToken),
+		ExpiresAt:    now.Add(time.Duration(token.ExpiresIn) * time.Second).UTC(),
+		ClientID:     aws.ToString(register.ClientId),
+		ClientSecret: aws.ToString(register.ClientSecret),
+		RefreshToken: aws.ToString(token.RefreshToken),
+	}
+	if register.ClientSecretExpiresAt > 0 {
+		entry.RegistrationExpiresAt = time.Unix(register.ClientSecretExpiresAt, 0).UTC()
+	}
 
-	return p.TokenPoller(ssooidcClient, register, deviceAuth)
+	return entry
 }
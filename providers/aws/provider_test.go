@@ -3,12 +3,16 @@ package aws
 import (
 	"context"
 	"errors"
+	"io"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ssooidc"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	appconfig "github.com/blairham/aws-sso-config/providers/config"
 )
@@ -82,12 +86,11 @@ func TestAWSProviderGenerateTokenSuccess(t *testing.T) {
 			assert.Equal(t, "https://test-verification-uri.com", url)
 			return nil
 		},
-		TokenPoller: func(client SSOOIDCClient, register *ssooidc.RegisterClientOutput, deviceAuth *ssooidc.StartDeviceAuthorizationOutput) *string {
+		TokenPoller: func(_ context.Context, client SSOOIDCClient, register *ssooidc.RegisterClientOutput, deviceAuth *ssooidc.StartDeviceAuthorizationOutput) (*ssooidc.CreateTokenOutput, error) {
 			// Verify params passed to token poller are correct
 			assert.Equal(t, mockRegister, register)
 			assert.Equal(t, mockDeviceAuth, deviceAuth)
-			token := "test-access-token"
-			return &token
+			return &ssooidc.CreateTokenOutput{AccessToken: aws.String("test-access-token"), ExpiresIn: 3600}, nil
 		},
 	}
 
@@ -100,11 +103,12 @@ func TestAWSProviderGenerateTokenSuccess(t *testing.T) {
 	}
 
 	// Call the method under test
-	token := provider.GenerateToken(appCfg)
+	token, err := provider.GenerateToken(context.Background(), appCfg)
 
 	// Verify results
-	assert.NotNil(t, token)
-	assert.Equal(t, "test-access-token", *token)
+	require.NoError(t, err)
+	assert.Equal(t, "test-access-token", token.AccessToken)
+	assert.Equal(t, TokenSourceInteractive, token.Source)
 	assert.True(t, browserOpened, "Browser should have been opened")
 
 	// Verify mock calls
@@ -126,9 +130,9 @@ func TestAWSProviderGenerateTokenRegisterFailure(t *testing.T) {
 			t.Fail() // Should not be called
 			return nil
 		},
-		TokenPoller: func(client SSOOIDCClient, register *ssooidc.RegisterClientOutput, deviceAuth *ssooidc.StartDeviceAuthorizationOutput) *string {
+		TokenPoller: func(_ context.Context, client SSOOIDCClient, register *ssooidc.RegisterClientOutput, deviceAuth *ssooidc.StartDeviceAuthorizationOutput) (*ssooidc.CreateTokenOutput, error) {
 			t.Fail() // Should not be called
-			return nil
+			return nil, nil
 		},
 	}
 
@@ -141,10 +145,11 @@ func TestAWSProviderGenerateTokenRegisterFailure(t *testing.T) {
 	}
 
 	// Call the method under test
-	token := provider.GenerateToken(appCfg)
+	token, err := provider.GenerateToken(context.Background(), appCfg)
 
 	// Verify results
-	assert.Nil(t, token, "Token should be nil when register client fails")
+	assert.ErrorIs(t, err, ErrRegistrationFailed)
+	assert.Empty(t, token.AccessToken, "Token should be empty when register client fails")
 
 	// Verify mock calls
 	mockClient.AssertExpectations(t)
@@ -173,9 +178,9 @@ func TestAWSProviderGenerateTokenDeviceAuthFailure(t *testing.T) {
 			t.Fail() // Should not be called
 			return nil
 		},
-		TokenPoller: func(client SSOOIDCClient, register *ssooidc.RegisterClientOutput, deviceAuth *ssooidc.StartDeviceAuthorizationOutput) *string {
+		TokenPoller: func(_ context.Context, client SSOOIDCClient, register *ssooidc.RegisterClientOutput, deviceAuth *ssooidc.StartDeviceAuthorizationOutput) (*ssooidc.CreateTokenOutput, error) {
 			t.Fail() // Should not be called
-			return nil
+			return nil, nil
 		},
 	}
 
@@ -188,10 +193,11 @@ func TestAWSProviderGenerateTokenDeviceAuthFailure(t *testing.T) {
 	}
 
 	// Call the method under test
-	token := provider.GenerateToken(appCfg)
+	token, err := provider.GenerateToken(context.Background(), appCfg)
 
 	// Verify results
-	assert.Nil(t, token, "Token should be nil when device auth fails")
+	assert.ErrorIs(t, err, ErrDeviceAuthorizationFailed)
+	assert.Empty(t, token.AccessToken, "Token should be empty when device auth fails")
 
 	// Verify mock calls
 	mockClient.AssertExpectations(t)
@@ -223,10 +229,9 @@ func TestAWSProviderGenerateTokenBrowserOpenFailure(t *testing.T) {
 		BrowserOpener: func(url string) error {
 			return errors.New("browser open error")
 		},
-		TokenPoller: func(client SSOOIDCClient, register *ssooidc.RegisterClientOutput, deviceAuth *ssooidc.StartDeviceAuthorizationOutput) *string {
+		TokenPoller: func(_ context.Context, client SSOOIDCClient, register *ssooidc.RegisterClientOutput, deviceAuth *ssooidc.StartDeviceAuthorizationOutput) (*ssooidc.CreateTokenOutput, error) {
 			// Should still be called even if browser fails
-			token := "test-access-token"
-			return &token
+			return &ssooidc.CreateTokenOutput{AccessToken: aws.String("test-access-token"), ExpiresIn: 3600}, nil
 		},
 	}
 
@@ -239,11 +244,11 @@ func TestAWSProviderGenerateTokenBrowserOpenFailure(t *testing.T) {
 	}
 
 	// Call the method under test
-	token := provider.GenerateToken(appCfg)
+	token, err := provider.GenerateToken(context.Background(), appCfg)
 
 	// Verify results - should continue even if browser open fails
-	assert.NotNil(t, token)
-	assert.Equal(t, "test-access-token", *token)
+	require.NoError(t, err)
+	assert.Equal(t, "test-access-token", token.AccessToken)
 
 	// Verify mock calls
 	mockClient.AssertExpectations(t)
@@ -251,7 +256,8 @@ func TestAWSProviderGenerateTokenBrowserOpenFailure(t *testing.T) {
 
 // TestNewDefaultAWSProvider tests the NewDefaultAWSProvider function
 func TestNewDefaultAWSProvider(t *testing.T) {
-	provider := NewDefaultAWSProvider()
+	provider, err := NewDefaultAWSProvider(context.Background())
+	require.NoError(t, err)
 
 	assert.NotNil(t, provider, "Provider should not be nil")
 	assert.NotNil(t, provider.SSOOIDCClient, "SSOOIDCClient should not be nil")
@@ -259,3 +265,90 @@ func TestNewDefaultAWSProvider(t *testing.T) {
 	assert.NotNil(t, provider.TokenPoller, "TokenPoller should not be nil")
 	assert.NotNil(t, provider.Cfg, "Cfg should not be nil")
 }
+
+func TestAWSProviderLoginCachesToken(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	mockClient := new(MockSSOOIDCClient)
+	mockClient.On("RegisterClient", mock.Anything, mock.Anything, mock.Anything).Return(&ssooidc.RegisterClientOutput{
+		ClientId:              aws.String("test-client-id"),
+		ClientSecret:          aws.String("test-client-secret"),
+		ClientSecretExpiresAt: time.Now().Add(24 * time.Hour).Unix(),
+	}, nil)
+	mockClient.On("StartDeviceAuthorization", mock.Anything, mock.Anything, mock.Anything).Return(&ssooidc.StartDeviceAuthorizationOutput{
+		DeviceCode:              aws.String("test-device-code"),
+		VerificationUriComplete: aws.String("https://test-verification-uri.com"),
+	}, nil)
+
+	provider := &AWSProvider{
+		SSOOIDCClient: mockClient,
+		BrowserOpener: func(string) error { return nil },
+		TokenPoller: func(_ context.Context, client SSOOIDCClient, register *ssooidc.RegisterClientOutput, deviceAuth *ssooidc.StartDeviceAuthorizationOutput) (*ssooidc.CreateTokenOutput, error) {
+			return &ssooidc.CreateTokenOutput{
+				AccessToken:  aws.String("test-access-token"),
+				RefreshToken: aws.String("test-refresh-token"),
+				ExpiresIn:    3600,
+			}, nil
+		},
+		Options: LoginOptions{Out: io.Discard},
+	}
+	appCfg := &appconfig.Config{SSO: appconfig.SSOConfig{StartURL: "https://test-sso-url.com", Region: "us-west-2"}}
+
+	entry, err := provider.Login(context.Background(), appCfg)
+	require.NoError(t, err)
+	assert.Equal(t, "test-access-token", entry.AccessToken)
+	assert.Equal(t, "test-refresh-token", entry.RefreshToken)
+	assert.Equal(t, "test-client-id", entry.ClientID)
+	assert.False(t, entry.RegistrationExpiresAt.IsZero())
+
+	cached, err := LoadCacheEntry("https://test-sso-url.com")
+	require.NoError(t, err)
+	assert.Equal(t, entry, cached)
+
+	// A second call is served from the cache without another login
+	token, err := provider.GetToken(context.Background(), appCfg)
+	require.NoError(t, err)
+	assert.Equal(t, "test-access-token", token.AccessToken)
+	assert.Equal(t, TokenSourceCache, token.Source)
+	mockClient.AssertNumberOfCalls(t, "RegisterClient", 1)
+}
+
+func TestAWSProviderLoginFailure(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	mockClient := new(MockSSOOIDCClient)
+	mockClient.On("RegisterClient", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("register client error"))
+
+	provider := &AWSProvider{SSOOIDCClient: mockClient, Options: LoginOptions{Out: io.Discard}}
+
+	entry, err := provider.Login(context.Background(), &appconfig.Config{SSO: appconfig.SSOConfig{StartURL: "https://test-sso-url.com"}})
+	assert.Error(t, err)
+	assert.Nil(t, entry)
+
+	_, err = LoadCacheEntry("https://test-sso-url.com")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestCheckStartURL(t *testing.T) {
+	register := &ssooidc.RegisterClientOutput{ClientId: aws.String("id"), ClientSecret: aws.String("secret")}
+
+	t.Run("known start URL", func(t *testing.T) {
+		mockClient := new(MockSSOOIDCClient)
+		mockClient.On("RegisterClient", mock.Anything, mock.Anything, mock.Anything).Return(register, nil)
+		mockClient.On("StartDeviceAuthorization", mock.Anything, mock.MatchedBy(func(in *ssooidc.StartDeviceAuthorizationInput) bool {
+			return aws.ToString(in.StartUrl) == "https://org.awsapps.com/start" && aws.ToString(in.ClientId) == "id"
+		}), mock.Anything).Return(&ssooidc.StartDeviceAuthorizationOutput{}, nil)
+
+		require.NoError(t, CheckStartURL(context.Background(), mockClient, "https://org.awsapps.com/start"))
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("unknown start URL", func(t *testing.T) {
+		mockClient := new(MockSSOOIDCClient)
+		mockClient.On("RegisterClient", mock.Anything, mock.Anything, mock.Anything).Return(register, nil)
+		mockClient.On("StartDeviceAuthorization", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("InvalidRequestException"))
+
+		err := CheckStartURL(context.Background(), mockClient, "https://typo.awsapps.com/start")
+		assert.ErrorIs(t, err, ErrDeviceAuthorizationFailed)
+	})
+}
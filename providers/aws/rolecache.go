@@ -0,0 +1,85 @@
+package aws
+
+import (
+	"crypto/sha1" // #nosec G505 - file naming only
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// RoleCredentialCache keeps role credentials per SSO portal, account and role in 0600 files,
+// like the AWS CLI does in ~/.aws/cli/cache
+type RoleCredentialCache struct {
+	Dir string
+	Now func() time.Time
+}
+
+// NewRoleCredentialCache returns a cache in the user's cache directory
+func NewRoleCredentialCache() (*RoleCredentialCache, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cache directory: %w", err)
+	}
+
+	return &RoleCredentialCache{Dir: filepath.Join(dir, "aws-sso-config", "credentials"), Now: time.Now}, nil
+}
+
+// portalDir holds the credentials of one SSO portal, so that they can be cleared on logout
+func (c *RoleCredentialCache) portalDir(startURL string) string {
+	sum := sha1.Sum([]byte(startURL)) // #nosec G401 - file naming only
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:]))
+}
+
+func (c *RoleCredentialCache) file(target *RoleTarget) string {
+	sum := sha1.Sum([]byte(target.AccountID + "/" + target.RoleName)) // #nosec G401 - file naming only
+	return filepath.Join(c.portalDir(target.StartURL), hex.EncodeToString(sum[:])+".json")
+}
+
+// Load returns cached credentials for the target that stay valid for longer than window.
+// It returns an error wrapping os.ErrNotExist when there are none.
+func (c *RoleCredentialCache) Load(target *RoleTarget, window time.Duration) (*RoleCredentials, error) {
+	filename := c.file(target)
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credential cache: %w", err)
+	}
+
+	var creds RoleCredentials
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, fmt.Errorf("failed to parse credential cache %s: %w", filename, err)
+	}
+
+	if !c.Now().Add(window).Before(creds.Expiration) {
+		return nil, fmt.Errorf("cached credentials expire at %s: %w", creds.Expiration.Format(time.RFC3339), os.ErrNotExist)
+	}
+
+	return &creds, nil
+}
+
+// Save writes the credentials for the target, readable only by the user
+func (c *RoleCredentialCache) Save(target *RoleTarget, creds *RoleCredentials) error {
+	filename := c.file(target)
+	if err := os.MkdirAll(filepath.Dir(filename), 0700); err != nil {
+		return fmt.Errorf("failed to create credential cache directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(creds, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode credential cache: %w", err)
+	}
+
+	return os.WriteFile(filename, data, 0600)
+}
+
+// Clear removes all cached credentials obtained through a start URL
+func (c *RoleCredentialCache) Clear(startURL string) error {
+	if err := os.RemoveAll(c.portalDir(startURL)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove credential cache: %w", err)
+	}
+
+	return nil
+}
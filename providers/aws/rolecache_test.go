@@ -0,0 +1,122 @@
+package aws
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sso"
+	"github.com/aws/aws-sdk-go-v2/service/sso/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	appconfig "github.com/blairham/aws-sso-config/providers/config"
+)
+
+func testRoleCredentialCache(t *testing.T, now time.Time) *RoleCredentialCache {
+	t.Helper()
+	return &RoleCredentialCache{Dir: filepath.Join(t.TempDir(), "credentials"), Now: func() time.Time { return now }}
+}
+
+func TestRoleCredentialCache(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cache := testRoleCredentialCache(t, now)
+	target := &RoleTarget{StartURL: "https://dev.awsapps.com/start", AccountID: "123456789012", RoleName: "Developer"}
+	creds := &RoleCredentials{AccessKeyID: "AKIAEXAMPLE", SecretAccessKey: "secret", SessionToken: "session", Expiration: now.Add(time.Hour)}
+
+	t.Run("missing", func(t *testing.T) {
+		_, err := cache.Load(target, 5*time.Minute)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("round trip with owner only permissions", func(t *testing.T) {
+		require.NoError(t, cache.Save(target, creds))
+
+		loaded, err := cache.Load(target, 5*time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, creds, loaded)
+
+		if runtime.GOOS != "windows" {
+			info, err := os.Stat(cache.file(target))
+			require.NoError(t, err)
+			assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+		}
+	})
+
+	t.Run("separate per account and role", func(t *testing.T) {
+		other := &RoleTarget{StartURL: target.StartURL, AccountID: target.AccountID, RoleName: "ReadOnly"}
+		_, err := cache.Load(other, 0)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("refreshed within the window before expiry", func(t *testing.T) {
+		_, err := cache.Load(target, 59*time.Minute)
+		require.NoError(t, err)
+
+		_, err = cache.Load(target, time.Hour)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("clear removes the start URL's credentials", func(t *testing.T) {
+		otherPortal := &RoleTarget{StartURL: "https://prod.awsapps.com/start", AccountID: target.AccountID, RoleName: target.RoleName}
+		require.NoError(t, cache.Save(otherPortal, creds))
+
+		require.NoError(t, cache.Clear(target.StartURL))
+
+		_, err := cache.Load(target, 0)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+		_, err = cache.Load(otherPortal, 0)
+		assert.NoError(t, err)
+		assert.NoError(t, cache.Clear("https://unknown.awsapps.com/start"))
+	})
+}
+
+func TestCredentialFetcherUsesCache(t *testing.T) {
+	now := time.Now()
+	client := new(MockRoleCredentialsClient)
+	client.On("GetRoleCredentials", mock.Anything, mock.Anything, mock.Anything).Return(&sso.GetRoleCredentialsOutput{
+		RoleCredentials: &types.RoleCredentials{
+			AccessKeyId:     aws.String("AKIAEXAMPLE"),
+			SecretAccessKey: aws.String("secret"),
+			SessionToken:    aws.String("session"),
+			Expiration:      now.Add(time.Hour).UnixMilli(),
+		},
+	}, nil).Once()
+
+	tokenCalls := 0
+	fetcher := &CredentialFetcher{
+		Token: func(context.Context, *appconfig.Config) (Token, error) {
+			tokenCalls++
+			return Token{AccessToken: "sso-token"}, nil
+		},
+		Client: func(string) RoleCredentialsClient { return client },
+		Cache:  testRoleCredentialCache(t, now),
+	}
+	target := &RoleTarget{StartURL: "https://dev.awsapps.com/start", AccountID: "123456789012", RoleName: "Developer"}
+	appCfg := &appconfig.Config{SSO: appconfig.SSOConfig{CredentialRefreshMinutes: 5}}
+
+	first, err := fetcher.Fetch(context.Background(), target, appCfg)
+	require.NoError(t, err)
+	second, err := fetcher.Fetch(context.Background(), target, appCfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, first.AccessKeyID, second.AccessKeyID)
+	assert.Equal(t, 1, tokenCalls, "cached credentials should not need an SSO token")
+	client.AssertExpectations(t)
+
+	t.Run("refreshes within the window", func(t *testing.T) {
+		client.On("GetRoleCredentials", mock.Anything, mock.Anything, mock.Anything).Return(&sso.GetRoleCredentialsOutput{
+			RoleCredentials: &types.RoleCredentials{AccessKeyId: aws.String("AKIANEW"), Expiration: now.Add(2 * time.Hour).UnixMilli()},
+		}, nil).Once()
+
+		creds, err := fetcher.Fetch(context.Background(), target, &appconfig.Config{SSO: appconfig.SSOConfig{CredentialRefreshMinutes: 90}})
+		require.NoError(t, err)
+		assert.Equal(t, "AKIANEW", creds.AccessKeyID)
+		client.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,222 @@
+package aws
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+	"github.com/zalando/go-keyring"
+)
+
+// Token store backends, selected with sso.token_store
+const (
+	// TokenStoreFile keeps tokens in plaintext in the AWS CLI cache, ~/.aws/sso/cache
+	TokenStoreFile = "file"
+	// TokenStoreKeyring keeps tokens in the OS keyring (Secret Service over D-Bus on Linux)
+	TokenStoreKeyring = "keyring"
+	// TokenStoreEncryptedFile keeps age encrypted tokens next to the AWS CLI cache
+	TokenStoreEncryptedFile = "encrypted-file"
+
+	// TokenPassphraseEnv holds the passphrase for the encrypted-file token store
+	TokenPassphraseEnv = "AWS_SSO_CONFIG_TOKEN_PASSPHRASE"
+
+	keyringService = "aws-sso-config"
+)
+
+// TokenStoreNames lists the valid values for sso.token_store
+var TokenStoreNames = []string{TokenStoreFile, TokenStoreKeyring, TokenStoreEncryptedFile}
+
+// TokenStore persists SSO cache entries by start URL. Load returns an error
+// wrapping os.ErrNotExist when there is no entry for the start URL.
+type TokenStore interface {
+	Load(startURL string) (*SSOCacheEntry, error)
+	Save(entry *SSOCacheEntry) error
+	Delete(startURL string) error
+}
+
+// NewTokenStore returns the token store with the given name, "" selects the plaintext file store
+func NewTokenStore(name string) (TokenStore, error) {
+	switch name {
+	case "", TokenStoreFile:
+		return fileStore{}, nil
+	case TokenStoreKeyring:
+		return keyringStore{service: keyringService}, nil
+	case TokenStoreEncryptedFile:
+		return &encryptedFileStore{passphrase: func() string { return os.Getenv(TokenPassphraseEnv) }}, nil
+	default:
+		return nil, fmt.Errorf("unknown token store %q, expected one of: %s", name, strings.Join(TokenStoreNames, ", "))
+	}
+}
+
+// fileStore is the plaintext AWS CLI compatible cache
+type fileStore struct{}
+
+func (fileStore) Load(startURL string) (*SSOCacheEntry, error) { return LoadCacheEntry(startURL) }
+func (fileStore) Save(entry *SSOCacheEntry) error              { return SaveCacheEntry(entry) }
+func (fileStore) Delete(startURL string) error                 { return DeleteCacheEntry(startURL) }
+
+// keyringStore keeps each cache entry as a JSON secret in the OS keyring, keyed by start URL
+type keyringStore struct {
+	service string
+}
+
+func (s keyringStore) Load(startURL string) (*SSOCacheEntry, error) {
+	secret, err := keyring.Get(s.service, startURL)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil, fmt.Errorf("no token in keyring for %s: %w", startURL, os.ErrNotExist)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token from keyring: %w", err)
+	}
+
+	var entry SSOCacheEntry
+	if err := json.Unmarshal([]byte(secret), &entry); err != nil {
+		return nil, fmt.Errorf("failed to parse token from keyring: %w", err)
+	}
+
+	return &entry, nil
+}
+
+func (s keyringStore) Save(entry *SSOCacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode token: %w", err)
+	}
+
+	if err := keyring.Set(s.service, entry.StartURL, string(data)); err != nil {
+		return fmt.Errorf("failed to write token to keyring: %w", err)
+	}
+
+	return nil
+}
+
+func (s keyringStore) Delete(startURL string) error {
+	if err := keyring.Delete(s.service, startURL); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return fmt.Errorf("failed to remove token from keyring: %w", err)
+	}
+
+	return nil
+}
+
+// encryptedFileStore keeps each cache entry in a file encrypted with age using a scrypt passphrase
+type encryptedFileStore struct {
+	passphrase func() string
+	// workFactor overrides the scrypt work factor, 0 uses the age default
+	workFactor int
+}
+
+// encryptedCacheFile returns the AWS CLI cache file name with an .age suffix, which the AWS CLI ignores
+func encryptedCacheFile(startURL string) (string, error) {
+	filename, err := CacheFile(startURL)
+	if err != nil {
+		return "", err
+	}
+
+	return filename + ".age", nil
+}
+
+func (s *encryptedFileStore) secret() (string, error) {
+	passphrase := s.passphrase()
+	if passphrase == "" {
+		return "", fmt.Errorf("the %s token store needs a passphrase in %s", TokenStoreEncryptedFile, TokenPassphraseEnv)
+	}
+
+	return passphrase, nil
+}
+
+func (s *encryptedFileStore) Load(startURL string) (*SSOCacheEntry, error) {
+	filename, err := encryptedCacheFile(startURL)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := os.ReadFile(filename) // #nosec G304 - path is derived from the start URL hash
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token cache: %w", err)
+	}
+
+	passphrase, err := s.secret()
+	if err != nil {
+		return nil, err
+	}
+	identity, err := age.NewScryptIdentity(passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create age identity: %w", err)
+	}
+
+	r, err := age.Decrypt(bytes.NewReader(ciphertext), identity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt token cache %s: %w", filename, err)
+	}
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt token cache %s: %w", filename, err)
+	}
+
+	var entry SSOCacheEntry
+	if err := json.Unmarshal(plaintext, &entry); err != nil {
+		return nil, fmt.Errorf("failed to parse token cache %s: %w", filename, err)
+	}
+
+	return &entry, nil
+}
+
+func (s *encryptedFileStore) Save(entry *SSOCacheEntry) error {
+	passphrase, err := s.secret()
+	if err != nil {
+		return err
+	}
+	recipient, err := age.NewScryptRecipient(passphrase)
+	if err != nil {
+		return fmt.Errorf("failed to create age recipient: %w", err)
+	}
+	if s.workFactor > 0 {
+		recipient.SetWorkFactor(s.workFactor)
+	}
+
+	plaintext, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode token: %w", err)
+	}
+
+	var ciphertext bytes.Buffer
+	w, err := age.Encrypt(&ciphertext, recipient)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt token: %w", err)
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return fmt.Errorf("failed to encrypt token: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to encrypt token: %w", err)
+	}
+
+	filename, err := encryptedCacheFile(entry.StartURL)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0700); err != nil {
+		return fmt.Errorf("failed to create token cache directory: %w", err)
+	}
+
+	return os.WriteFile(filename, ciphertext.Bytes(), 0600)
+}
+
+func (s *encryptedFileStore) Delete(startURL string) error {
+	filename, err := encryptedCacheFile(startURL)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(filename); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove token cache: %w", err)
+	}
+
+	return nil
+}
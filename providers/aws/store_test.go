@@ -0,0 +1,168 @@
+package aws
+
+import (
+	"context"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/zalando/go-keyring"
+
+	appconfig "github.com/blairham/aws-sso-config/providers/config"
+)
+
+const storeStartURL = "https://test.awsapps.com/start"
+
+func testStoreEntry() *SSOCacheEntry {
+	return &SSOCacheEntry{
+		StartURL:     storeStartURL,
+		Region:       "us-west-2",
+		AccessToken:  "secret-access-token",
+		ExpiresAt:    time.Now().Add(time.Hour).UTC().Truncate(time.Second),
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		RefreshToken: "secret-refresh-token",
+	}
+}
+
+// newTestEncryptedFileStore uses a low scrypt work factor to keep the tests fast
+func newTestEncryptedFileStore(passphrase string) *encryptedFileStore {
+	return &encryptedFileStore{passphrase: func() string { return passphrase }, workFactor: 10}
+}
+
+func TestNewTokenStore(t *testing.T) {
+	for _, name := range append([]string{""}, TokenStoreNames...) {
+		store, err := NewTokenStore(name)
+		require.NoError(t, err, name)
+		assert.NotNil(t, store, name)
+	}
+
+	_, err := NewTokenStore("vault")
+	assert.ErrorContains(t, err, "unknown token store")
+}
+
+func TestTokenStoresRoundTrip(t *testing.T) {
+	keyring.MockInit()
+
+	stores := map[string]TokenStore{
+		TokenStoreFile:          fileStore{},
+		TokenStoreKeyring:       keyringStore{service: keyringService},
+		TokenStoreEncryptedFile: newTestEncryptedFileStore("correct horse battery staple"),
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+
+			_, err := store.Load(storeStartURL)
+			assert.ErrorIs(t, err, os.ErrNotExist, "a missing entry should wrap os.ErrNotExist")
+
+			entry := testStoreEntry()
+			require.NoError(t, store.Save(entry))
+
+			loaded, err := store.Load(storeStartURL)
+			require.NoError(t, err)
+			assert.Equal(t, entry, loaded)
+
+			require.NoError(t, store.Delete(storeStartURL))
+			_, err = store.Load(storeStartURL)
+			assert.ErrorIs(t, err, os.ErrNotExist)
+
+			// Deleting twice is not an error
+			assert.NoError(t, store.Delete(storeStartURL))
+		})
+	}
+}
+
+func TestEncryptedFileStoreDoesNotWritePlaintext(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	store := newTestEncryptedFileStore("passphrase")
+
+	require.NoError(t, store.Save(testStoreEntry()))
+
+	filename, err := encryptedCacheFile(storeStartURL)
+	require.NoError(t, err)
+	data, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret-access-token")
+	assert.NotContains(t, string(data), "secret-refresh-token")
+
+	info, err := os.Stat(filename)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// Nothing is left in the plaintext AWS CLI cache
+	_, err = LoadCacheEntry(storeStartURL)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestEncryptedFileStorePassphrase(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	require.NoError(t, newTestEncryptedFileStore("right").Save(testStoreEntry()))
+
+	_, err := newTestEncryptedFileStore("wrong").Load(storeStartURL)
+	assert.ErrorContains(t, err, "failed to decrypt")
+
+	_, err = newTestEncryptedFileStore("").Load(storeStartURL)
+	assert.ErrorContains(t, err, TokenPassphraseEnv)
+
+	err = newTestEncryptedFileStore("").Save(testStoreEntry())
+	assert.ErrorContains(t, err, TokenPassphraseEnv)
+}
+
+func TestEncryptedFileStoreReadsPassphraseFromEnvironment(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(TokenPassphraseEnv, "from-env")
+
+	store, err := NewTokenStore(TokenStoreEncryptedFile)
+	require.NoError(t, err)
+	store.(*encryptedFileStore).workFactor = 10
+
+	require.NoError(t, store.Save(testStoreEntry()))
+	loaded, err := newTestEncryptedFileStore("from-env").Load(storeStartURL)
+	require.NoError(t, err)
+	assert.Equal(t, "secret-access-token", loaded.AccessToken)
+}
+
+func TestAWSProviderUsesConfiguredTokenStore(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	keyring.MockInit()
+
+	mockClient := new(MockSSOOIDCClient)
+	mockClient.On("RegisterClient", mock.Anything, mock.Anything, mock.Anything).Return(&ssooidc.RegisterClientOutput{
+		ClientId:     aws.String("test-client-id"),
+		ClientSecret: aws.String("test-client-secret"),
+	}, nil)
+	mockClient.On("StartDeviceAuthorization", mock.Anything, mock.Anything, mock.Anything).Return(testDeviceAuth(), nil)
+
+	provider := &AWSProvider{
+		SSOOIDCClient: mockClient,
+		TokenPoller: func(context.Context, SSOOIDCClient, *ssooidc.RegisterClientOutput, *ssooidc.StartDeviceAuthorizationOutput) (*ssooidc.CreateTokenOutput, error) {
+			return &ssooidc.CreateTokenOutput{AccessToken: aws.String("keyring-token"), ExpiresIn: 3600}, nil
+		},
+		Options: LoginOptions{NoBrowser: true, Out: io.Discard},
+	}
+	appCfg := &appconfig.Config{SSO: appconfig.SSOConfig{StartURL: storeStartURL, TokenStore: TokenStoreKeyring}}
+
+	_, err := provider.Login(context.Background(), appCfg)
+	require.NoError(t, err)
+
+	loaded, err := keyringStore{service: keyringService}.Load(storeStartURL)
+	require.NoError(t, err)
+	assert.Equal(t, "keyring-token", loaded.AccessToken)
+
+	_, err = LoadCacheEntry(storeStartURL)
+	assert.ErrorIs(t, err, os.ErrNotExist, "the plaintext cache should not be written")
+
+	// The next call is served from the keyring
+	token, err := provider.GetToken(context.Background(), appCfg)
+	require.NoError(t, err)
+	assert.Equal(t, TokenSourceCache, token.Source)
+	mockClient.AssertNumberOfCalls(t, "RegisterClient", 1)
+}
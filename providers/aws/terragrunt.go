@@ -0,0 +1,80 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// TerragruntFile is the terragrunt configuration at the root of a repository
+const TerragruntFile = "terragrunt.hcl"
+
+// errNoAccountID means the terragrunt file does not set account_id
+var errNoAccountID = errors.New("no account_id")
+
+// terragruntAccountID reads account_id from a terragrunt file. It can be a top-level attribute,
+// a local or an input, and has to be a literal since the file is not evaluated.
+func terragruntAccountID(file string) (string, error) {
+	src, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+
+	parsed, diags := hclsyntax.ParseConfig(src, file, hcl.InitialPos)
+	if diags.HasErrors() {
+		return "", fmt.Errorf("failed to parse %s: %w", file, diags)
+	}
+	body, ok := parsed.Body.(*hclsyntax.Body)
+	if !ok {
+		return "", fmt.Errorf("failed to parse %s", file)
+	}
+
+	if attr, ok := body.Attributes["account_id"]; ok {
+		return accountIDValue(attr.Expr, file)
+	}
+	for _, block := range body.Blocks {
+		if block.Type != "locals" {
+			continue
+		}
+		if attr, ok := block.Body.Attributes["account_id"]; ok {
+			return accountIDValue(attr.Expr, file)
+		}
+	}
+	if attr, ok := body.Attributes["inputs"]; ok {
+		if object, ok := attr.Expr.(*hclsyntax.ObjectConsExpr); ok {
+			for _, item := range object.Items {
+				if hcl.ExprAsKeyword(item.KeyExpr) == "account_id" {
+					return accountIDValue(item.ValueExpr, file)
+				}
+				if key, diags := item.KeyExpr.Value(nil); !diags.HasErrors() && key.Type() == cty.String && key.AsString() == "account_id" {
+					return accountIDValue(item.ValueExpr, file)
+				}
+			}
+		}
+	}
+
+	return "", errNoAccountID
+}
+
+// accountIDValue evaluates an account_id expression without variables or functions
+func accountIDValue(expr hclsyntax.Expression, file string) (string, error) {
+	value, diags := expr.Value(nil)
+	if diags.HasErrors() || !value.IsKnown() || value.IsNull() {
+		rng := expr.Range()
+		return "", fmt.Errorf("account_id at %s:%d is not a literal value", file, rng.Start.Line)
+	}
+
+	switch value.Type() {
+	case cty.String:
+		return value.AsString(), nil
+	case cty.Number:
+		return value.AsBigFloat().Text('f', -1), nil
+	default:
+		rng := expr.Range()
+		return "", fmt.Errorf("account_id at %s:%d is a %s, not a string", file, rng.Start.Line, value.Type().FriendlyName())
+	}
+}
@@ -0,0 +1,99 @@
+package aws
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTerragruntAccountID(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		errMsg  string
+	}{
+		{
+			name:    "top-level attribute",
+			content: `account_id = "123456789012"`,
+			want:    "123456789012",
+		},
+		{
+			name: "local",
+			content: `
+locals {
+  region     = "eu-west-1"
+  account_id = "123456789012" # production
+}
+`,
+			want: "123456789012",
+		},
+		{
+			name: "input",
+			content: `
+include "root" {
+  path = find_in_parent_folders()
+}
+
+inputs = {
+  region     = "eu-west-1"
+  account_id = "123456789012"
+}
+`,
+			want: "123456789012",
+		},
+		{
+			name:    "quoted input key",
+			content: `inputs = { "account_id" = "123456789012" }`,
+			want:    "123456789012",
+		},
+		{
+			name:    "number",
+			content: `inputs = { account_id = 123456789012 }`,
+			want:    "123456789012",
+		},
+		{
+			name:    "not confused by other attributes",
+			content: "# account_id = \"999999999999\"\nparent_account_id = \"999999999999\"\ninputs = {\n  account_id = \"123456789012\"\n}\n",
+			want:    "123456789012",
+		},
+		{
+			name:    "missing",
+			content: `inputs = { region = "eu-west-1" }`,
+			errMsg:  "no account_id",
+		},
+		{
+			name:    "not a literal",
+			content: "locals {\n  account_id = get_aws_account_id()\n}\n",
+			errMsg:  ":2 is not a literal value",
+		},
+		{
+			name:    "not a string",
+			content: `account_id = ["123456789012"]`,
+			errMsg:  "is a tuple",
+		},
+		{
+			name:    "syntax error",
+			content: `inputs = {`,
+			errMsg:  "failed to parse",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), TerragruntFile)
+			require.NoError(t, os.WriteFile(file, []byte(tt.content), 0600))
+
+			accountID, err := terragruntAccountID(file)
+			if tt.errMsg != "" {
+				assert.ErrorContains(t, err, tt.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, accountID)
+		})
+	}
+}
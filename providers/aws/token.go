@@ -0,0 +1,74 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc"
+)
+
+const refreshTokenGrantType = "refresh_token"
+
+// TokenSource describes where a token came from
+type TokenSource string
+
+const (
+	// TokenSourceCache is a still valid token read from the SSO cache
+	TokenSourceCache TokenSource = "cache"
+	// TokenSourceRefresh is a token obtained with the cached refresh token
+	TokenSourceRefresh TokenSource = "refresh"
+	// TokenSourceInteractive is a token obtained by the user approving a device authorization
+	TokenSourceInteractive TokenSource = "interactive"
+)
+
+// Token is an SSO access token together with its expiry and origin
+type Token struct {
+	AccessToken string
+	ExpiresAt   time.Time
+	Source      TokenSource
+}
+
+// newToken builds a token from a cache entry
+func newToken(entry *SSOCacheEntry, source TokenSource) Token {
+	return Token{
+		AccessToken: entry.AccessToken,
+		ExpiresAt:   entry.ExpiresAt,
+		Source:      source,
+	}
+}
+
+// canRefresh reports whether the entry holds a refresh token and a client registration that is still valid
+func (e *SSOCacheEntry) canRefresh(now time.Time) bool {
+	if e.RefreshToken == "" || e.ClientID == "" || e.ClientSecret == "" {
+		return false
+	}
+	return e.RegistrationExpiresAt.IsZero() || now.Before(e.RegistrationExpiresAt)
+}
+
+// refresh exchanges the cached refresh token for a new access token without user interaction
+func (p *AWSProvider) refresh(ctx context.Context, entry *SSOCacheEntry) (*SSOCacheEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, createTokenTimeout)
+	defer cancel()
+
+	token, err := p.SSOOIDCClient.CreateToken(ctx, &ssooidc.CreateTokenInput{
+		ClientId:     aws.String(entry.ClientID),
+		ClientSecret: aws.String(entry.ClientSecret),
+		GrantType:    aws.String(refreshTokenGrantType),
+		RefreshToken: aws.String(entry.RefreshToken),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRefreshFailed, err)
+	}
+
+	refreshed := *entry
+	refreshed.AccessToken = aws.ToString(token.AccessToken)
+	refreshed.ExpiresAt = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second).UTC()
+	// The refresh token may be rotated, keep the old one when it is not
+	if token.RefreshToken != nil {
+		refreshed.RefreshToken = aws.ToString(token.RefreshToken)
+	}
+
+	return &refreshed, nil
+}
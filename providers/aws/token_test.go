@@ -0,0 +1,111 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	appconfig "github.com/blairham/aws-sso-config/providers/config"
+)
+
+const refreshStartURL = "https://test-sso-url.com"
+
+// saveExpiredEntry caches a token that has expired but can still be refreshed
+func saveExpiredEntry(t *testing.T) *SSOCacheEntry {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	entry := &SSOCacheEntry{
+		StartURL:              refreshStartURL,
+		Region:                "us-west-2",
+		AccessToken:           "expired-token",
+		ExpiresAt:             time.Now().Add(-time.Minute).UTC(),
+		ClientID:              "test-client-id",
+		ClientSecret:          "test-client-secret",
+		RegistrationExpiresAt: time.Now().Add(24 * time.Hour).UTC(),
+		RefreshToken:          "test-refresh-token",
+	}
+	require.NoError(t, SaveCacheEntry(entry))
+	return entry
+}
+
+func TestGetTokenRefreshesExpiredToken(t *testing.T) {
+	saveExpiredEntry(t)
+
+	mockClient := new(MockSSOOIDCClient)
+	mockClient.On("CreateToken", mock.Anything, mock.MatchedBy(func(input *ssooidc.CreateTokenInput) bool {
+		return aws.ToString(input.GrantType) == refreshTokenGrantType &&
+			aws.ToString(input.RefreshToken) == "test-refresh-token" &&
+			aws.ToString(input.ClientId) == "test-client-id"
+	}), mock.Anything).Return(&ssooidc.CreateTokenOutput{
+		AccessToken:  aws.String("refreshed-token"),
+		RefreshToken: aws.String("rotated-refresh-token"),
+		ExpiresIn:    3600,
+	}, nil)
+
+	provider := &AWSProvider{SSOOIDCClient: mockClient, Options: LoginOptions{Out: io.Discard}}
+
+	token, err := provider.GetToken(context.Background(), &appconfig.Config{SSO: appconfig.SSOConfig{StartURL: refreshStartURL}})
+
+	require.NoError(t, err)
+	assert.Equal(t, "refreshed-token", token.AccessToken)
+	assert.Equal(t, TokenSourceRefresh, token.Source)
+	assert.True(t, token.ExpiresAt.After(time.Now()))
+	mockClient.AssertNotCalled(t, "RegisterClient", mock.Anything, mock.Anything, mock.Anything)
+
+	cached, err := LoadCacheEntry(refreshStartURL)
+	require.NoError(t, err)
+	assert.Equal(t, "refreshed-token", cached.AccessToken)
+	assert.Equal(t, "rotated-refresh-token", cached.RefreshToken)
+	assert.Equal(t, "test-client-id", cached.ClientID)
+}
+
+func TestGetTokenFallsBackToLoginWhenRefreshFails(t *testing.T) {
+	saveExpiredEntry(t)
+
+	mockClient := new(MockSSOOIDCClient)
+	mockClient.On("CreateToken", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("invalid_grant"))
+	mockClient.On("RegisterClient", mock.Anything, mock.Anything, mock.Anything).Return(&ssooidc.RegisterClientOutput{
+		ClientId:     aws.String("new-client-id"),
+		ClientSecret: aws.String("new-client-secret"),
+	}, nil)
+	mockClient.On("StartDeviceAuthorization", mock.Anything, mock.Anything, mock.Anything).Return(testDeviceAuth(), nil)
+
+	provider := &AWSProvider{
+		SSOOIDCClient: mockClient,
+		TokenPoller: func(context.Context, SSOOIDCClient, *ssooidc.RegisterClientOutput, *ssooidc.StartDeviceAuthorizationOutput) (*ssooidc.CreateTokenOutput, error) {
+			return &ssooidc.CreateTokenOutput{AccessToken: aws.String("interactive-token"), ExpiresIn: 3600}, nil
+		},
+		Options: LoginOptions{NoBrowser: true, Out: io.Discard},
+	}
+
+	token, err := provider.GetToken(context.Background(), &appconfig.Config{SSO: appconfig.SSOConfig{StartURL: refreshStartURL}})
+
+	require.NoError(t, err)
+	assert.Equal(t, "interactive-token", token.AccessToken)
+	assert.Equal(t, TokenSourceInteractive, token.Source)
+}
+
+func TestGetTokenSkipsRefreshWithExpiredRegistration(t *testing.T) {
+	entry := saveExpiredEntry(t)
+	entry.RegistrationExpiresAt = time.Now().Add(-time.Hour).UTC()
+	require.NoError(t, SaveCacheEntry(entry))
+
+	mockClient := new(MockSSOOIDCClient)
+	mockClient.On("RegisterClient", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("register client error"))
+
+	provider := &AWSProvider{SSOOIDCClient: mockClient, Options: LoginOptions{Out: io.Discard}}
+
+	_, err := provider.GetToken(context.Background(), &appconfig.Config{SSO: appconfig.SSOConfig{StartURL: refreshStartURL}})
+
+	assert.ErrorIs(t, err, ErrRegistrationFailed)
+	mockClient.AssertNotCalled(t, "CreateToken", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,10 @@
+//go:build !windows
+
+package config
+
+import "golang.org/x/sys/unix"
+
+// writable reports whether the current user may create files in dir, without writing
+func writable(dir string) bool {
+	return unix.Access(dir, unix.W_OK) == nil
+}
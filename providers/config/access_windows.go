@@ -0,0 +1,12 @@
+//go:build windows
+
+package config
+
+import "os"
+
+// writable reports whether dir is writable, without writing. Windows has no access
+// check for the current user, only the read-only attribute is seen.
+func writable(dir string) bool {
+	info, err := os.Stat(dir)
+	return err == nil && info.Mode().Perm()&0200 != 0
+}
@@ -7,10 +7,11 @@ import (
 	"github.com/mitchellh/go-homedir"
 )
 
-// AWSConfig holds AWS-specific configuration
+// AWSConfig holds AWS-specific configuration. Each field with an entry in descriptions
+// is a configuration key, see Key.
 type AWSConfig struct {
-	DefaultRegion string `mapstructure:"default_region" toml:"default_region"`
-	ConfigFile    string `mapstructure:"config_file" toml:"config_file"`
+	DefaultRegion string `mapstructure:"default_region" toml:"default_region" validate:"region" deprecated_env:"AWS_CONFIG_DEFAULT_REGION"`
+	ConfigFile    string `mapstructure:"config_file" toml:"config_file" validate:"file_path" trusted:"true" deprecated_env:"AWS_CONFIG_CONFIG_FILE"`
 }
 
 // DefaultAWS returns the default AWS configuration
@@ -49,11 +50,18 @@ func (a *AWSConfig) GetSectionName() string {
 	return "aws"
 }
 
-// GetDefaultContent returns the default TOML content for AWS section
+// GetDefaultContent returns the TOML content for the AWS section with the values of a,
+// defaults for those it leaves unset
 func (a *AWSConfig) GetDefaultContent() string {
-	return `# AWS Configuration
+	values := *a
+	if values.ConfigFile == "" || values.ConfigFile == DefaultAWS().ConfigFile {
+		// Keep the file portable between home directories
+		values.ConfigFile = "~/.aws/config"
+	}
+	values.SetDefaults()
+	return fmt.Sprintf(`# AWS Configuration
 [aws]
-default_region = "us-east-1"
-config_file = "~/.aws/config"
-`
+default_region = %q
+config_file = %q
+`, values.DefaultRegion, values.ConfigFile)
 }
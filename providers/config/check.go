@@ -0,0 +1,246 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+)
+
+// Problem is something wrong with a configuration file
+type Problem struct {
+	// Line is the line the problem is on, 0 when it is not tied to one
+	Line int
+	// Message describes the problem
+	Message string
+}
+
+// Portal is an SSO start URL a configuration file sets, with the region to reach it in
+type Portal struct {
+	// Context is the context setting the start URL, empty for the top level
+	Context  string
+	StartURL string
+	Region   string
+	Line     int
+}
+
+// FileReport is the result of CheckFile
+type FileReport struct {
+	// Problems found, in line order
+	Problems []Problem
+	// Portals lists the start URLs set in the file, for checking them online
+	Portals []Portal
+}
+
+// contextSettings holds the sections a context may set
+type contextSettings struct {
+	SSO SSOConfig `toml:"sso"`
+	AWS AWSConfig `toml:"aws"`
+}
+
+// fileLayout is a configuration file as written, for strict decoding
+type fileLayout struct {
+	Config
+	Version        int                        `toml:"version"`
+	CurrentContext string                     `toml:"current_context"`
+	Contexts       map[string]contextSettings `toml:"contexts"`
+}
+
+// CheckFile checks a single configuration file without merging other layers: it must
+// be valid TOML in the current layout, without unknown sections or keys, and every
+// value must pass its key's validator. An error is returned only if the file cannot
+// be read.
+func CheckFile(path string) (*FileReport, error) {
+	data, err := os.ReadFile(path) // #nosec G304 - the user names the file to check
+	if err != nil {
+		return nil, err
+	}
+
+	report := &FileReport{}
+	var layout fileLayout
+	err = toml.NewDecoder(bytes.NewReader(data)).DisallowUnknownFields().Decode(&layout)
+	var decodeErr *toml.DecodeError
+	var strictErr *toml.StrictMissingError
+	switch {
+	case errors.As(err, &strictErr):
+		for i := range strictErr.Errors {
+			report.add(unknownProblem(&strictErr.Errors[i]))
+		}
+	case errors.As(err, &decodeErr):
+		// Nothing else can be checked in a file that does not decode
+		line, _ := decodeErr.Position()
+		report.add(Problem{Line: line, Message: strings.TrimPrefix(decodeErr.Error(), "toml: ")})
+		return report, nil
+	case err != nil:
+		return nil, err
+	}
+
+	lines := keyLines(data)
+	if layout.Version > CurrentVersion {
+		message := fmt.Sprintf("version %d is newer than this release supports (%d)", layout.Version, CurrentVersion)
+		report.add(Problem{Line: lines[versionKey], Message: message})
+	}
+	if _, ok := layout.Contexts[layout.CurrentContext]; layout.CurrentContext != "" && !ok {
+		message := fmt.Sprintf("current_context %q is not defined in this file", layout.CurrentContext)
+		report.add(Problem{Line: lines[currentContextKey], Message: message})
+	}
+
+	report.checkKeys(&layout.Config, "", lines)
+	report.addPortal(&layout.Config, &layout.Config, "", lines)
+	for _, name := range slices.Sorted(maps.Keys(layout.Contexts)) {
+		prefix := contextsKey + "." + strings.ToLower(name) + "."
+		if !contextNamePattern.MatchString(name) {
+			message := fmt.Sprintf("context name %q must be lower case letters, digits, - and _", name)
+			report.add(Problem{Line: firstLine(lines, prefix), Message: message})
+		}
+		context := &Config{SSO: layout.Contexts[name].SSO, AWS: layout.Contexts[name].AWS}
+		report.checkKeys(context, prefix, lines)
+		report.addPortal(context, &layout.Config, name, lines)
+	}
+
+	// Chains and repository mappings are checked as a whole, as they are when loaded
+	layout.SetDefaults()
+	if err := layout.Generate.Validate(layout.SSO.Role); err != nil {
+		report.add(Problem{Line: lines["generate.chains"], Message: err.Error()})
+	}
+	if err := layout.Repos.Validate(); err != nil {
+		report.add(Problem{Line: lines["repos"], Message: err.Error()})
+	}
+
+	slices.SortStableFunc(report.Problems, func(a, b Problem) int { return a.Line - b.Line })
+	return report, nil
+}
+
+// checkKeys runs the validator of every registered key set in the file, named with
+// prefix, against its value in config
+func (r *FileReport) checkKeys(config *Config, prefix string, lines map[string]int) {
+	for _, k := range schema {
+		line, ok := lines[prefix+k.Name]
+		if !ok {
+			continue
+		}
+		value := k.Get(config)
+		if isDefault(k, value) {
+			continue
+		}
+		if err := k.Validate(value); err != nil {
+			r.add(Problem{Line: line, Message: err.Error()})
+		}
+	}
+}
+
+// addPortal records the start URL config sets, if any, with the region set next to
+// it, at the top level or the default
+func (r *FileReport) addPortal(config *Config, top *Config, context string, lines map[string]int) {
+	prefix := ""
+	if context != "" {
+		prefix = contextsKey + "." + strings.ToLower(context) + "."
+	}
+	line, ok := lines[prefix+"sso.start_url"]
+	if !ok {
+		return
+	}
+	region := config.SSO.Region
+	if region == "" {
+		region = top.SSO.Region
+	}
+	if region == "" {
+		region = DefaultSSO().Region
+	}
+	r.Portals = append(r.Portals, Portal{Context: context, StartURL: config.SSO.StartURL, Region: region, Line: line})
+}
+
+// firstLine returns the first line a key starting with prefix is on, 0 if none
+func firstLine(lines map[string]int, prefix string) int {
+	first := 0
+	for name, line := range lines {
+		if strings.HasPrefix(name, prefix) && (first == 0 || line < first) {
+			first = line
+		}
+	}
+	return first
+}
+
+func (r *FileReport) add(p Problem) {
+	r.Problems = append(r.Problems, p)
+}
+
+// unknownProblem describes a key strict decoding found no field for, pointing keys of
+// the first layout to their new names. Keys are compared lower cased, as viper reads them.
+func unknownProblem(err *toml.DecodeError) Problem {
+	line, _ := err.Position()
+	key := strings.Join(err.Key(), ".")
+	if name, ok := legacyKeys[strings.ToLower(key)]; ok {
+		if name == "" {
+			return Problem{Line: line, Message: fmt.Sprintf("%s is not supported, remove it", key)}
+		}
+		return Problem{Line: line, Message: fmt.Sprintf("%s is from the version 0 layout, use %s", key, name)}
+	}
+	if strings.Contains(err.Error(), "missing table") {
+		return Problem{Line: line, Message: "unknown configuration section: " + key}
+	}
+	return Problem{Line: line, Message: "unknown configuration key: " + key}
+}
+
+// keyLines maps the dotted names of the tables and keys in data to the line they are
+// first set on, lower cased like viper. Keys in arrays of tables are not included.
+func keyLines(data []byte) map[string]int {
+	lines := map[string]int{}
+	var p unstable.Parser
+	p.Reset(data)
+
+	table, inArray := "", false
+	for p.NextExpression() {
+		e := p.Expression()
+		switch e.Kind {
+		case unstable.Table, unstable.ArrayTable:
+			name, line := joinKey(&p, e.Key())
+			table, inArray = name, e.Kind == unstable.ArrayTable
+			if _, ok := lines[name]; !ok {
+				lines[name] = line
+			}
+		case unstable.KeyValue:
+			// The keys of each entry of an array of tables would repeat
+			if !inArray {
+				addKeyValue(&p, lines, table, e)
+			}
+		}
+	}
+	return lines
+}
+
+// addKeyValue records a key in table, and the keys of an inline table value
+func addKeyValue(p *unstable.Parser, lines map[string]int, table string, kv *unstable.Node) {
+	name, line := joinKey(p, kv.Key())
+	if table != "" {
+		name = table + "." + name
+	}
+	if _, ok := lines[name]; !ok {
+		lines[name] = line
+	}
+	if value := kv.Value(); value.Kind == unstable.InlineTable {
+		for it := value.Children(); it.Next(); {
+			addKeyValue(p, lines, name, it.Node())
+		}
+	}
+}
+
+// joinKey joins the parts of a key, returning the line its first part is on
+func joinKey(p *unstable.Parser, it unstable.Iterator) (string, int) {
+	var parts []string
+	line := 0
+	for it.Next() {
+		node := it.Node()
+		if line == 0 {
+			line = p.Shape(node.Raw).Start.Line
+		}
+		parts = append(parts, strings.ToLower(string(node.Data)))
+	}
+	return strings.Join(parts, "."), line
+}
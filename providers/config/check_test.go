@@ -0,0 +1,103 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func checkString(t *testing.T, content string) *FileReport {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	report, err := CheckFile(path)
+	require.NoError(t, err)
+	return report
+}
+
+func TestCheckFileValid(t *testing.T) {
+	report := checkString(t, `version = 1
+current_context = "work"
+
+[sso]
+start_url = "https://team.awsapps.com/start"
+region = "us-east-1"
+role = "Developer"
+
+[contexts.work.sso]
+start_url = "https://work.awsapps.com/start"
+region = "eu-west-1"
+
+[contexts.home]
+sso = { start_url = "https://home.awsapps.com/start" }
+`)
+	assert.Empty(t, report.Problems)
+	assert.Equal(t, []Portal{
+		{StartURL: "https://team.awsapps.com/start", Region: "us-east-1", Line: 5},
+		{Context: "home", StartURL: "https://home.awsapps.com/start", Region: "us-east-1", Line: 14},
+		{Context: "work", StartURL: "https://work.awsapps.com/start", Region: "eu-west-1", Line: 10},
+	}, report.Portals)
+}
+
+func TestCheckFileProblems(t *testing.T) {
+	report := checkString(t, `version = 2
+current_context = "missing"
+sso_start_url = "https://flat.awsapps.com/start"
+
+[sso]
+region = "narnia"
+colour = "blue"
+
+[proxy]
+url = "http://proxy"
+
+[contexts."My Work".aws]
+default_region = "eu-west-1"
+
+[[generate.chains]]
+name = "chained"
+source_role = "Missing"
+role_arn_tmpl = "arn:aws:iam::{{.AccountID}}:role/Chained"
+`)
+	var lines []int
+	for _, p := range report.Problems {
+		lines = append(lines, p.Line)
+	}
+	assert.Equal(t, []int{1, 2, 3, 6, 7, 9, 12, 15, 18}, lines, report.Problems)
+	assert.Contains(t, report.Problems[0].Message, "version 2 is newer than this release supports (1)")
+	assert.Contains(t, report.Problems[1].Message, `current_context "missing" is not defined in this file`)
+	assert.Equal(t, "sso_start_url is from the version 0 layout, use sso.start_url", report.Problems[2].Message)
+	assert.Contains(t, report.Problems[3].Message, `"narnia" is not an AWS region`)
+	assert.Equal(t, "unknown configuration key: sso.colour", report.Problems[4].Message)
+	assert.Equal(t, "unknown configuration section: proxy", report.Problems[5].Message)
+	assert.Contains(t, report.Problems[6].Message, `context name "My Work" must be lower case`)
+	assert.Contains(t, report.Problems[7].Message, "generate chain 1")
+	assert.Equal(t, "unknown configuration key: generate.chains.role_arn_tmpl", report.Problems[8].Message)
+	assert.Empty(t, report.Portals)
+}
+
+func TestCheckFileKeyCase(t *testing.T) {
+	report := checkString(t, `SSO_Start_URL = "https://flat.awsapps.com/start"
+
+[SSO]
+Start_URL = "https://team.awsapps.com/start"
+Region = "narnia"
+`)
+	require.Len(t, report.Problems, 2, report.Problems)
+	assert.Equal(t, Problem{Line: 1, Message: "SSO_Start_URL is from the version 0 layout, use sso.start_url"}, report.Problems[0])
+	assert.Equal(t, 5, report.Problems[1].Line)
+	assert.Contains(t, report.Problems[1].Message, `"narnia" is not an AWS region`)
+	assert.Equal(t, []Portal{{StartURL: "https://team.awsapps.com/start", Region: "narnia", Line: 4}}, report.Portals)
+}
+
+func TestCheckFileSyntaxError(t *testing.T) {
+	report := checkString(t, "[sso]\nregion = \"us-east-1\"\nrole = Developer\n")
+	require.Len(t, report.Problems, 1)
+	assert.Equal(t, 3, report.Problems[0].Line)
+
+	_, err := CheckFile(filepath.Join(t.TempDir(), "missing.toml"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
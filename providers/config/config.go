@@ -5,6 +5,15 @@ type Config struct {
 	// Provider configurations
 	SSO SSOConfig `mapstructure:"sso" toml:"sso"`
 	AWS AWSConfig `mapstructure:"aws" toml:"aws"`
+	// Command configurations
+	Generate GenerateConfig `mapstructure:"generate" toml:"generate"`
+	// Repository to profile mappings
+	Repos ReposConfig `mapstructure:"repos" toml:"repos"`
+
+	// Context is the name of the context merged over the top-level settings, empty for none
+	Context string `mapstructure:"-" toml:"-"`
+	// Origins maps each registered key to the layer its value came from
+	Origins map[string]Origin `mapstructure:"-" toml:"-"`
 }
 
 // Backward compatibility getters
@@ -20,6 +29,14 @@ func (c *Config) SSORole() string {
 	return c.SSO.Role
 }
 
+func (c *Config) SSOTokenStore() string {
+	return c.SSO.TokenStore
+}
+
+func (c *Config) SSOCredentialRefreshMinutes() int {
+	return c.SSO.CredentialRefreshMinutes
+}
+
 // AWS configuration getters
 func (c *Config) DefaultRegion() string {
 	return c.AWS.DefaultRegion
@@ -37,6 +54,12 @@ func (c *Config) Validate() error {
 	if err := c.AWS.Validate(); err != nil {
 		return err
 	}
+	if err := c.Generate.Validate(c.SSO.Role); err != nil {
+		return err
+	}
+	if err := c.Repos.Validate(); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -45,6 +68,8 @@ func Default() *Config {
 	return &Config{
 		SSO: DefaultSSO(),
 		AWS: DefaultAWS(),
+
+		Generate: DefaultGenerate(),
 	}
 }
 
@@ -52,4 +77,5 @@ func Default() *Config {
 func (c *Config) SetDefaults() {
 	c.SSO.SetDefaults()
 	c.AWS.SetDefaults()
+	c.Generate.SetDefaults()
 }
@@ -23,9 +23,9 @@ func TestNewConfigManager(t *testing.T) {
 }
 
 func TestConfigManagerLoad(t *testing.T) {
-	t.Run("load non-existent config creates default file and returns config", func(t *testing.T) {
+	t.Run("load non-existent config returns defaults without creating a file", func(t *testing.T) {
 		tempDir := t.TempDir()
-		configFile := filepath.Join(tempDir, "test-config")
+		configFile := filepath.Join(tempDir, "missing", "test-config")
 		cm := NewConfigManager(configFile)
 
 		// First verify the file doesn't exist
@@ -48,8 +48,29 @@ func TestConfigManagerLoad(t *testing.T) {
 		assert.Equal(t, "us-east-1", config.AWS.DefaultRegion)
 		assert.Contains(t, config.AWS.ConfigFile, ".aws/config")
 
-		// Check that config file was created
-		assert.FileExists(t, configFile)
+		// Check that nothing was written
+		assert.NoDirExists(t, filepath.Dir(configFile))
+	})
+
+	t.Run("create writes the given settings and defaults", func(t *testing.T) {
+		configFile := filepath.Join(t.TempDir(), "test-config")
+		cm := NewConfigManager(configFile)
+
+		config := &Config{SSO: SSOConfig{StartURL: "https://team.awsapps.com/start", Region: "eu-west-1"}, AWS: DefaultAWS()}
+		require.NoError(t, cm.Create(config))
+
+		data, err := os.ReadFile(configFile)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "version = 1")
+		assert.Contains(t, string(data), `start_url = "https://team.awsapps.com/start"`)
+		assert.Contains(t, string(data), `role = "AdministratorAccess"`)
+		assert.Contains(t, string(data), `config_file = "~/.aws/config"`, "the default is written portably")
+		assert.Contains(t, string(data), "# [[repos]]")
+
+		loaded, err := cm.Load()
+		require.NoError(t, err)
+		assert.Equal(t, "eu-west-1", loaded.SSO.Region)
+		assert.Equal(t, "us-east-1", loaded.AWS.DefaultRegion)
 	})
 
 	t.Run("load existing config", func(t *testing.T) {
@@ -265,6 +286,49 @@ region = "us-west-2"
 		assert.Equal(t, "us-west-2", config.SSO.Region)
 	})
 
+	t.Run("load generate chains", func(t *testing.T) {
+		configFile := filepath.Join(t.TempDir(), "test-config")
+		content := `[sso]
+start_url = "https://test.awsapps.com/start"
+region = "us-west-2"
+role = "Admin"
+
+[[generate.chains]]
+name = "deploy"
+source_role = "Admin"
+role_arn_template = "arn:aws:iam::{{.AccountId}}:role/Deploy"
+`
+		require.NoError(t, os.WriteFile(configFile, []byte(content), 0600))
+
+		config, err := Load(configFile)
+		require.NoError(t, err)
+		require.Len(t, config.Generate.Chains, 1)
+		assert.Equal(t, "deploy", config.Generate.Chains[0].Name)
+		assert.Equal(t, "arn:aws:iam::{{.AccountId}}:role/Deploy", config.Generate.Chains[0].RoleARNTemplate)
+		assert.Equal(t, DefaultRoleSessionNameTemplate, config.Generate.Chains[0].RoleSessionNameTemplate)
+	})
+
+	t.Run("load repos", func(t *testing.T) {
+		configFile := filepath.Join(t.TempDir(), "test-config")
+		content := `[[repos]]
+path = "~/src/infra/*"
+profile = "infra"
+
+[[repos]]
+remote = "github.com/acme/*"
+profile = "acme"
+`
+		require.NoError(t, os.WriteFile(configFile, []byte(content), 0600))
+
+		config, err := Load(configFile)
+		require.NoError(t, err)
+		assert.Equal(t, ReposConfig{
+			{Path: "~/src/infra/*", Profile: "infra"},
+			{Remote: "github.com/acme/*", Profile: "acme"},
+		}, config.Repos)
+		assert.NoError(t, config.Repos.Validate())
+	})
+
 	t.Run("load with empty config path uses default location", func(t *testing.T) {
 		// Clean up any existing config file
 		homeConfigFile := filepath.Join(os.Getenv("HOME"), ".awsssoconfig")
@@ -0,0 +1,266 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// ContextEnv selects a context, overriding current_context. The --context flag sets it.
+	ContextEnv = "AWS_SSO_CONFIG_CONTEXT"
+
+	// currentContextKey is the top-level key holding the context selected by context use
+	currentContextKey = "current_context"
+	// contextsKey holds one table of sso and aws settings per context
+	contextsKey = "contexts"
+)
+
+// ErrUnknownContext is returned when the selected context is not defined
+var ErrUnknownContext = errors.New("unknown context")
+
+// contextNamePattern keeps context names usable as TOML table names. Viper lowercases
+// keys, so upper case names could not be told apart.
+var contextNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ContextSelection reports the active context and what selected it
+type ContextSelection struct {
+	// Name is the active context, empty for the top-level settings
+	Name string
+	// FromEnvironment is true when --context or AWS_SSO_CONFIG_CONTEXT selected it,
+	// rather than current_context in the configuration file
+	FromEnvironment bool
+}
+
+// Contexts returns the names of the contexts defined in any configuration file, sorted
+func (cm *ConfigManager) Contexts() ([]string, error) {
+	v, err := cm.mergedFiles()
+	if err != nil {
+		return nil, err
+	}
+	return contextNames(v), nil
+}
+
+// CurrentContext returns the active context, which need not be defined
+func (cm *ConfigManager) CurrentContext() (ContextSelection, error) {
+	v, err := cm.mergedFiles()
+	if err != nil {
+		return ContextSelection{}, err
+	}
+	return selectContext(v), nil
+}
+
+// UseContext records name as the current context in the user file. An empty name
+// goes back to the top-level settings.
+func (cm *ConfigManager) UseContext(name string) error {
+	merged, err := cm.mergedFiles()
+	if err != nil {
+		return err
+	}
+	if name != "" && !merged.IsSet(contextsKey+"."+name) {
+		return fmt.Errorf("%w %q, create it with: aws-sso-config context create %s", ErrUnknownContext, name, name)
+	}
+
+	v, err := cm.readFile()
+	if err != nil {
+		return err
+	}
+
+	settings := v.AllSettings()
+	if name == "" {
+		delete(settings, currentContextKey)
+	} else {
+		settings[currentContextKey] = name
+	}
+	return cm.writeSettings(settings)
+}
+
+// CreateContext defines a context holding the given key values, which are validated
+// like values given to config set. A context needs at least one value, as an empty
+// table is not written.
+func (cm *ConfigManager) CreateContext(name string, values map[string]string) error {
+	if !contextNamePattern.MatchString(name) {
+		return fmt.Errorf("context name %q must be lower case letters, digits, - and _", name)
+	}
+	if len(values) == 0 {
+		return fmt.Errorf("context %q needs at least one setting, e.g. sso.start_url=https://example.awsapps.com/start", name)
+	}
+
+	merged, err := cm.mergedFiles()
+	if err != nil {
+		return err
+	}
+	if merged.IsSet(contextsKey + "." + name) {
+		return fmt.Errorf("context %q already exists", name)
+	}
+	v, err := cm.readFile()
+	if err != nil {
+		return err
+	}
+
+	context := Default()
+	sections := map[string]any{}
+	for key, value := range values {
+		k, ok := LookupKey(key)
+		if !ok {
+			return fmt.Errorf("unknown configuration key: %s", key)
+		}
+		if err := k.Set(context, value); err != nil {
+			return err
+		}
+		section, _ := sections[k.Section].(map[string]any)
+		if section == nil {
+			section = map[string]any{}
+			sections[k.Section] = section
+		}
+		section[k.fieldName()] = k.value(context)
+	}
+
+	settings := v.AllSettings()
+	contexts, _ := settings[contextsKey].(map[string]any)
+	if contexts == nil {
+		contexts = map[string]any{}
+		settings[contextsKey] = contexts
+	}
+	contexts[name] = sections
+	return cm.writeSettings(settings)
+}
+
+// DeleteContext removes a context from the user file, and clears current_context
+// if it selected it
+func (cm *ConfigManager) DeleteContext(name string) error {
+	v, err := cm.readFile()
+	if err != nil {
+		return err
+	}
+	if !v.IsSet(contextsKey + "." + name) {
+		return fmt.Errorf("%w %q in %s", ErrUnknownContext, name, cm.configFile)
+	}
+
+	settings := v.AllSettings()
+	contexts, _ := settings[contextsKey].(map[string]any)
+	delete(contexts, name)
+	if len(contexts) == 0 {
+		delete(settings, contextsKey)
+	}
+	if settings[currentContextKey] == name {
+		delete(settings, currentContextKey)
+	}
+	return cm.writeSettings(settings)
+}
+
+// removeKey removes a key from the user file, from a context's settings there when
+// context is not empty
+func (cm *ConfigManager) removeKey(context string, name string) error {
+	k, ok := LookupKey(name)
+	if !ok {
+		return fmt.Errorf("unknown configuration key: %s", name)
+	}
+
+	v, err := cm.readFile()
+	if err != nil {
+		return err
+	}
+	settings := v.AllSettings()
+
+	sections := settings
+	if context != "" {
+		if !v.IsSet(contextsKey + "." + context) {
+			return fmt.Errorf("%w %q in %s", ErrUnknownContext, context, cm.configFile)
+		}
+		contexts, _ := settings[contextsKey].(map[string]any)
+		sections, _ = contexts[context].(map[string]any)
+	}
+	if section, ok := sections[k.Section].(map[string]any); ok {
+		delete(section, k.fieldName())
+		if len(section) == 0 {
+			delete(sections, k.Section)
+		}
+	}
+	if context != "" && len(sections) == 0 {
+		return fmt.Errorf("%s is the last setting in context %q, delete the context instead", name, context)
+	}
+	return cm.writeSettings(settings)
+}
+
+// applyContext merges the selected context's settings over the top-level ones in
+// config and records its name
+func applyContext(v *viper.Viper, config *Config) error {
+	selection := selectContext(v)
+	if selection.Name == "" {
+		return nil
+	}
+
+	prefix := contextsKey + "." + selection.Name
+	if !v.IsSet(prefix) {
+		return fmt.Errorf("%w %q, define it as [%s]", ErrUnknownContext, selection.Name, prefix)
+	}
+	for _, k := range schema {
+		if !v.IsSet(prefix + "." + k.Name) {
+			continue
+		}
+		if err := k.load(config, v.GetString(prefix+"."+k.Name)); err != nil {
+			return fmt.Errorf("context %s: %w", selection.Name, err)
+		}
+	}
+	config.Context = selection.Name
+	return nil
+}
+
+// selectContext picks the context named by the environment, then by current_context
+func selectContext(v *viper.Viper) ContextSelection {
+	if name := os.Getenv(ContextEnv); name != "" {
+		return ContextSelection{Name: name, FromEnvironment: true}
+	}
+	return ContextSelection{Name: v.GetString(currentContextKey)}
+}
+
+// contextNames returns the sorted names of the contexts in v
+func contextNames(v *viper.Viper) []string {
+	names := make([]string, 0)
+	for name := range v.GetStringMap(contextsKey) {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// readFile reads the user file, migrated to the current layout, without defaults or
+// the environment. A missing file reads as empty.
+func (cm *ConfigManager) readFile() (*viper.Viper, error) {
+	v := viper.New()
+	v.SetConfigFile(cm.configFile)
+	v.SetConfigType("toml")
+	if err := v.ReadInConfig(); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return v, nil
+		}
+		return nil, fmt.Errorf("error reading config file: %w", err)
+	}
+	return cm.upgradeLayer(Origin{Layer: LayerUser, Source: cm.configFile}, v)
+}
+
+// writeSettings replaces the configuration file with settings, marked with the
+// current layout version. A file in an older layout is backed up first.
+func (cm *ConfigManager) writeSettings(settings map[string]any) error {
+	if err := cm.backupOldLayout(); err != nil {
+		return err
+	}
+	settings[versionKey] = CurrentVersion
+	v := viper.New()
+	v.SetConfigType("toml")
+	if err := v.MergeConfigMap(settings); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cm.configFile), 0750); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	return v.WriteConfigAs(cm.configFile)
+}
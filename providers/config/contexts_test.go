@@ -0,0 +1,148 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const contextsTestConfig = `current_context = "work"
+
+[sso]
+start_url = "https://top.awsapps.com/start"
+region = "us-east-1"
+role = "TopRole"
+
+[aws]
+default_region = "us-east-1"
+
+[contexts.work.sso]
+start_url = "https://work.awsapps.com/start"
+credential_refresh_minutes = 10
+
+[contexts.client-a.sso]
+start_url = "https://client-a.awsapps.com/start"
+region = "eu-west-1"
+
+[contexts.client-a.aws]
+default_region = "eu-west-1"
+`
+
+func writeContextsConfig(t *testing.T) *ConfigManager {
+	t.Helper()
+	t.Setenv(ContextEnv, "")
+	configFile := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(configFile, []byte(contextsTestConfig), 0600))
+	return NewConfigManager(configFile)
+}
+
+func TestLoadMergesContext(t *testing.T) {
+	t.Run("current context from the file", func(t *testing.T) {
+		cm := writeContextsConfig(t)
+		config, err := cm.Load()
+		require.NoError(t, err)
+
+		assert.Equal(t, "work", config.Context)
+		assert.Equal(t, "https://work.awsapps.com/start", config.SSO.StartURL)
+		assert.Equal(t, 10, config.SSO.CredentialRefreshMinutes)
+		assert.Equal(t, "us-east-1", config.SSO.Region, "unset keys fall back to the top level")
+		assert.Equal(t, "TopRole", config.SSO.Role)
+	})
+
+	t.Run("environment overrides the file", func(t *testing.T) {
+		cm := writeContextsConfig(t)
+		t.Setenv(ContextEnv, "client-a")
+		config, err := cm.Load()
+		require.NoError(t, err)
+
+		assert.Equal(t, "client-a", config.Context)
+		assert.Equal(t, "eu-west-1", config.SSO.Region)
+		assert.Equal(t, "eu-west-1", config.AWS.DefaultRegion)
+		assert.Equal(t, defaultCredentialRefreshMinutes, config.SSO.CredentialRefreshMinutes)
+	})
+
+	t.Run("invalid context value", func(t *testing.T) {
+		configFile := filepath.Join(t.TempDir(), "config.toml")
+		require.NoError(t, os.WriteFile(configFile, []byte("current_context = \"work\"\n[contexts.work.sso]\nregion = \"narnia\"\n"), 0600))
+		t.Setenv(ContextEnv, "")
+
+		_, err := NewConfigManager(configFile).Load()
+		assert.EqualError(t, err, `context work: invalid value for sso.region: "narnia" is not an AWS region`)
+	})
+
+	t.Run("unknown context", func(t *testing.T) {
+		cm := writeContextsConfig(t)
+		t.Setenv(ContextEnv, "missing")
+		_, err := cm.Load()
+		assert.ErrorIs(t, err, ErrUnknownContext)
+		assert.ErrorIs(t, cm.SaveKey("sso.role", "Other"), ErrUnknownContext)
+	})
+}
+
+func TestContextManagement(t *testing.T) {
+	cm := writeContextsConfig(t)
+
+	names, err := cm.Contexts()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"client-a", "work"}, names)
+
+	selection, err := cm.CurrentContext()
+	require.NoError(t, err)
+	assert.Equal(t, ContextSelection{Name: "work"}, selection)
+
+	require.NoError(t, cm.CreateContext("sandbox", map[string]string{"sso.region": "us-west-2", "sso.role": "Dev"}))
+	assert.ErrorContains(t, cm.CreateContext("sandbox", map[string]string{"sso.role": "Dev"}), "already exists")
+	assert.ErrorContains(t, cm.CreateContext("Bad.Name", map[string]string{"sso.role": "Dev"}), "must be lower case")
+	assert.ErrorContains(t, cm.CreateContext("other", map[string]string{"sso.region": "narnia"}), "is not an AWS region")
+	assert.ErrorContains(t, cm.CreateContext("other", map[string]string{"sso.nope": "x"}), "unknown configuration key")
+
+	require.NoError(t, cm.UseContext("sandbox"))
+	assert.ErrorIs(t, cm.UseContext("missing"), ErrUnknownContext)
+
+	config, err := cm.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "sandbox", config.Context)
+	assert.Equal(t, "us-west-2", config.SSO.Region)
+	assert.Equal(t, "https://top.awsapps.com/start", config.SSO.StartURL)
+
+	// config set and unset apply to the active context only
+	require.NoError(t, cm.SaveKey("sso.role", "Ops"))
+	require.NoError(t, cm.ResetKey("sso.region"))
+	config, err = cm.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "Ops", config.SSO.Role)
+	assert.Equal(t, "us-east-1", config.SSO.Region)
+	require.NoError(t, cm.UseContext(""))
+	config, err = cm.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "TopRole", config.SSO.Role)
+	assert.Empty(t, config.Context)
+
+	require.NoError(t, cm.UseContext("work"))
+	require.NoError(t, cm.DeleteContext("work"))
+	assert.ErrorIs(t, cm.DeleteContext("work"), ErrUnknownContext)
+	selection, err = cm.CurrentContext()
+	require.NoError(t, err)
+	assert.Empty(t, selection.Name, "deleting the current context clears current_context")
+
+	names, err = cm.Contexts()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"client-a", "sandbox"}, names)
+}
+
+func TestCreateEmptyContext(t *testing.T) {
+	t.Setenv(ContextEnv, "")
+	cm := NewConfigManager(filepath.Join(t.TempDir(), "config.toml"))
+
+	assert.ErrorContains(t, cm.CreateContext("empty", nil), "needs at least one setting")
+	require.NoError(t, cm.CreateContext("first", map[string]string{"sso.role": "Dev"}))
+	names, err := cm.Contexts()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first"}, names)
+
+	t.Setenv(ContextEnv, "first")
+	assert.ErrorContains(t, cm.ResetKey("sso.role"), "last setting in context")
+}
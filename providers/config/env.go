@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+)
+
+// Logger receives warnings about the configuration, such as deprecated variables
+var Logger = log.New(os.Stderr, "", 0)
+
+// warned records the warnings already logged, so commands that load the
+// configuration more than once warn only once
+var warned sync.Map
+
+// warnOnce logs a warning unless it was already logged
+func warnOnce(format string, args ...any) {
+	message := fmt.Sprintf(format, args...)
+	if _, ok := warned.LoadOrStore(message, true); !ok {
+		Logger.Print(message)
+	}
+}
+
+// applyEnv overrides registered keys from their environment variables, the env layer.
+// Empty variables are ignored, others are validated like config set.
+func applyEnv(config *Config) error {
+	for _, k := range schema {
+		name, value, ok := envValue(k)
+		if !ok {
+			continue
+		}
+		if err := k.load(config, value); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if config.Origins != nil {
+			config.Origins[k.Name] = Origin{Layer: LayerEnv, Source: name}
+		}
+	}
+	return nil
+}
+
+// envValue returns the variable overriding k and its value, preferring Env over
+// DeprecatedEnv and warning when the deprecated one is set
+func envValue(k Key) (name string, value string, ok bool) {
+	deprecated := ""
+	if k.DeprecatedEnv != "" {
+		deprecated = os.Getenv(k.DeprecatedEnv)
+	}
+	if deprecated != "" {
+		warnOnce("Warning: %s is deprecated, use %s instead", k.DeprecatedEnv, k.Env)
+	}
+
+	if value := os.Getenv(k.Env); value != "" {
+		return k.Env, value, true
+	}
+	if deprecated != "" {
+		return k.DeprecatedEnv, deprecated, true
+	}
+	return "", "", false
+}
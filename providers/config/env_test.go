@@ -0,0 +1,146 @@
+package config
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clearEnv unsets every configuration variable for the test and captures warnings
+func clearEnv(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	t.Setenv(ContextEnv, "")
+	for _, k := range Keys() {
+		t.Setenv(k.Env, "")
+		if k.DeprecatedEnv != "" {
+			t.Setenv(k.DeprecatedEnv, "")
+		}
+	}
+
+	var out bytes.Buffer
+	logger := Logger
+	Logger = log.New(&out, "", 0)
+	warned.Clear()
+	t.Cleanup(func() { Logger = logger })
+	return &out
+}
+
+func TestEnvOverridesEveryKey(t *testing.T) {
+	values := map[string]string{
+		"sso.start_url":                  "https://env.awsapps.com/start",
+		"sso.region":                     "ap-southeast-2",
+		"sso.role":                       "EnvRole",
+		"sso.token_store":                "keyring",
+		"sso.credential_refresh_minutes": "15",
+		"aws.default_region":             "eu-central-1",
+		"aws.config_file":                "/tmp/env-aws-config",
+	}
+
+	for _, k := range Keys() {
+		t.Run(k.Env, func(t *testing.T) {
+			clearEnv(t)
+			cm := NewConfigManager(filepath.Join(t.TempDir(), "config.toml"))
+			t.Setenv(k.Env, values[k.Name])
+
+			config, err := cm.Load()
+			require.NoError(t, err)
+			assert.Equal(t, values[k.Name], k.Get(config))
+			assert.Equal(t, Origin{Layer: LayerEnv, Source: k.Env}, config.Origins[k.Name])
+		})
+	}
+}
+
+func TestDeprecatedEnv(t *testing.T) {
+	t.Run("legacy name is read with a warning", func(t *testing.T) {
+		out := clearEnv(t)
+		cm := NewConfigManager(filepath.Join(t.TempDir(), "config.toml"))
+		t.Setenv("AWS_CONFIG_SSO_REGION", "eu-west-2")
+
+		config, err := cm.Load()
+		require.NoError(t, err)
+		_, err = cm.Load()
+		require.NoError(t, err)
+
+		assert.Equal(t, "eu-west-2", config.SSO.Region)
+		assert.Equal(t, Origin{Layer: LayerEnv, Source: "AWS_CONFIG_SSO_REGION"}, config.Origins["sso.region"])
+		assert.Equal(t, "Warning: AWS_CONFIG_SSO_REGION is deprecated, use AWS_SSO_CONFIG_SSO_REGION instead\n", out.String(),
+			"warned once per process")
+	})
+
+	t.Run("new name wins", func(t *testing.T) {
+		out := clearEnv(t)
+		cm := NewConfigManager(filepath.Join(t.TempDir(), "config.toml"))
+		t.Setenv("AWS_CONFIG_DEFAULT_REGION", "eu-west-2")
+		t.Setenv("AWS_SSO_CONFIG_AWS_DEFAULT_REGION", "us-west-1")
+
+		config, err := cm.Load()
+		require.NoError(t, err)
+		assert.Equal(t, "us-west-1", config.AWS.DefaultRegion)
+		assert.Contains(t, out.String(), "AWS_CONFIG_DEFAULT_REGION is deprecated")
+	})
+
+	t.Run("every legacy name", func(t *testing.T) {
+		legacy := map[string]string{}
+		for _, k := range Keys() {
+			if k.DeprecatedEnv != "" {
+				legacy[k.Name] = k.DeprecatedEnv
+			}
+		}
+		assert.Equal(t, map[string]string{
+			"sso.start_url":      "AWS_CONFIG_SSO_START_URL",
+			"sso.region":         "AWS_CONFIG_SSO_REGION",
+			"sso.role":           "AWS_CONFIG_SSO_ROLE",
+			"aws.default_region": "AWS_CONFIG_DEFAULT_REGION",
+			"aws.config_file":    "AWS_CONFIG_CONFIG_FILE",
+		}, legacy)
+	})
+}
+
+func TestEnvPrecedence(t *testing.T) {
+	clearEnv(t)
+	cm := writeContextsConfig(t)
+
+	t.Setenv("AWS_SSO_CONFIG_SSO_START_URL", "https://env.awsapps.com/start")
+	t.Setenv("AWS_SSO_CONFIG_SSO_ROLE", "")
+	config, err := cm.Load()
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://env.awsapps.com/start", config.SSO.StartURL, "env beats the context")
+	assert.Equal(t, "TopRole", config.SSO.Role, "empty variables are ignored")
+	assert.Equal(t, LayerUser, config.Origins["sso.role"].Layer)
+
+	t.Setenv("AWS_SSO_CONFIG_SSO_CREDENTIAL_REFRESH_MINUTES", "soon")
+	_, err = cm.Load()
+	assert.ErrorContains(t, err, "AWS_SSO_CONFIG_SSO_CREDENTIAL_REFRESH_MINUTES: ")
+}
+
+func TestEnvIsValidated(t *testing.T) {
+	clearEnv(t)
+	cm := NewConfigManager(filepath.Join(t.TempDir(), "config.toml"))
+
+	t.Setenv("AWS_SSO_CONFIG_SSO_REGION", "narnia")
+	_, err := cm.Load()
+	assert.EqualError(t, err, `AWS_SSO_CONFIG_SSO_REGION: invalid value for sso.region: "narnia" is not an AWS region`)
+
+	t.Setenv("AWS_SSO_CONFIG_SSO_REGION", "")
+	t.Setenv("AWS_SSO_CONFIG_AWS_CONFIG_FILE", "~/.aws/config")
+	_, err = cm.Load()
+	assert.NoError(t, err, "the default is accepted without validation")
+}
+
+func TestEnvDoesNotLeakIntoFile(t *testing.T) {
+	clearEnv(t)
+	configFile := filepath.Join(t.TempDir(), "config.toml")
+	cm := NewConfigManager(configFile)
+	t.Setenv("AWS_SSO_CONFIG_SSO_ROLE", "EnvRole")
+
+	require.NoError(t, cm.SaveKey("sso.region", "eu-west-1"))
+	data, err := os.ReadFile(configFile)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "EnvRole")
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// DefaultRoleSessionNameTemplate names chained role sessions after the local user
+const DefaultRoleSessionNameTemplate = "{{.User}}"
+
+// chainNamePattern keeps chain names usable as profile name suffixes
+var chainNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// GenerateConfig holds settings for the generate command
+type GenerateConfig struct {
+	Chains []ChainConfig `mapstructure:"chains" toml:"chains"`
+}
+
+// ChainConfig declares an assume-role profile chained on top of each generated profile.
+// Templates are text/template strings rendered with the account's AccountId, AccountName,
+// SourceProfile, Profile and the local User.
+type ChainConfig struct {
+	// Name is appended to the account's profile name, [profile <account>-<name>]
+	Name string `mapstructure:"name" toml:"name"`
+	// SourceRole is the SSO role of the generated profiles to chain from, or the name of another chain
+	SourceRole string `mapstructure:"source_role" toml:"source_role"`
+	// RoleARNTemplate renders the role_arn to assume
+	RoleARNTemplate string `mapstructure:"role_arn_template" toml:"role_arn_template"`
+	// RoleSessionNameTemplate renders the role_session_name, the local user by default
+	RoleSessionNameTemplate string `mapstructure:"role_session_name_template" toml:"role_session_name_template"`
+}
+
+// DefaultGenerate returns the default generate configuration, without chains
+func DefaultGenerate() GenerateConfig {
+	return GenerateConfig{}
+}
+
+// Validate checks each chain and that chains form no cycle. ssoRole is the SSO role
+// generate writes profiles for, the only role a chain can start from.
+func (g *GenerateConfig) Validate(ssoRole string) error {
+	_, err := g.OrderedChains(ssoRole)
+	return err
+}
+
+// SetDefaults sets default values for any missing generate configuration
+func (g *GenerateConfig) SetDefaults() {
+	for i := range g.Chains {
+		if g.Chains[i].RoleSessionNameTemplate == "" {
+			g.Chains[i].RoleSessionNameTemplate = DefaultRoleSessionNameTemplate
+		}
+	}
+}
+
+// GetSectionName returns the TOML section name for generate configuration
+func (g *GenerateConfig) GetSectionName() string {
+	return "generate"
+}
+
+// GetDefaultContent returns the default TOML content for the generate section
+func (g *GenerateConfig) GetDefaultContent() string {
+	return `# Generate Configuration
+# Chain an assume-role profile on top of every generated profile:
+# [[generate.chains]]
+# name = "deploy"
+# source_role = "AdministratorAccess"
+# role_arn_template = "arn:aws:iam::{{.AccountId}}:role/Deploy"
+# role_session_name_template = "{{.User}}"
+`
+}
+
+// OrderedChains validates the chains and returns them so that every chain comes after the
+// chain it is sourced from
+func (g *GenerateConfig) OrderedChains(ssoRole string) ([]ChainConfig, error) {
+	byName := make(map[string]ChainConfig, len(g.Chains))
+	for i, chain := range g.Chains {
+		if err := chain.validate(); err != nil {
+			return nil, fmt.Errorf("generate chain %d: %w", i+1, err)
+		}
+		if _, ok := byName[chain.Name]; ok {
+			return nil, fmt.Errorf("generate chain %q is declared more than once", chain.Name)
+		}
+		byName[chain.Name] = chain
+	}
+
+	for _, chain := range g.Chains {
+		if _, ok := byName[chain.SourceRole]; !ok && chain.SourceRole != ssoRole {
+			return nil, fmt.Errorf("generate chain %q: source role %q is neither the SSO role %q nor another chain", chain.Name, chain.SourceRole, ssoRole)
+		}
+	}
+
+	ordered := make([]ChainConfig, 0, len(g.Chains))
+	state := make(map[string]int, len(g.Chains)) // 1 while visiting, 2 once ordered
+	var visit func(chain ChainConfig, path []string) error
+	visit = func(chain ChainConfig, path []string) error {
+		switch state[chain.Name] {
+		case 1:
+			return fmt.Errorf("generate chains form a cycle: %s", strings.Join(append(path, chain.Name), " -> "))
+		case 2:
+			return nil
+		}
+		state[chain.Name] = 1
+		if source, ok := byName[chain.SourceRole]; ok {
+			if err := visit(source, append(path, chain.Name)); err != nil {
+				return err
+			}
+		}
+		state[chain.Name] = 2
+		ordered = append(ordered, chain)
+		return nil
+	}
+
+	for _, chain := range g.Chains {
+		if err := visit(chain, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return ordered, nil
+}
+
+func (c *ChainConfig) validate() error {
+	if !chainNamePattern.MatchString(c.Name) {
+		return fmt.Errorf("name %q must be letters, digits, '.', '_' or '-'", c.Name)
+	}
+	if c.SourceRole == "" {
+		return fmt.Errorf("%s: source_role is required", c.Name)
+	}
+	if c.RoleARNTemplate == "" {
+		return fmt.Errorf("%s: role_arn_template is required", c.Name)
+	}
+	if _, err := template.New("role_arn").Parse(c.RoleARNTemplate); err != nil {
+		return fmt.Errorf("%s: invalid role_arn_template: %w", c.Name, err)
+	}
+	if _, err := template.New("role_session_name").Parse(c.RoleSessionNameTemplate); err != nil {
+		return fmt.Errorf("%s: invalid role_session_name_template: %w", c.Name, err)
+	}
+	return nil
+}
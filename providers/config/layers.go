@@ -0,0 +1,214 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Configuration is read from these layers, each overriding the ones before it:
+//
+//  1. defaults built into the binary
+//  2. the system file, /etc/aws-sso-config/config.toml, for organization-wide defaults
+//  3. the user file, ~/.awsssoconfig or the file given with --config
+//  4. the project file, .aws-sso-config.toml in the working directory or the nearest parent
+//  5. environment variables
+//  6. command flags, such as --region, which apply to that command only
+//
+// Within the file layers, the selected context's settings override the top-level
+// ones. Only the user file is written to.
+const (
+	LayerDefault = "default"
+	LayerSystem  = "system"
+	LayerUser    = "user"
+	LayerProject = "project"
+	LayerEnv     = "env"
+)
+
+// SystemConfigFile is the system layer, shipped by administrators
+const SystemConfigFile = "/etc/aws-sso-config/config.toml"
+
+// ProjectConfigFileName is the project layer, looked up from the working directory
+const ProjectConfigFileName = ".aws-sso-config.toml"
+
+// Origin records where a configuration value came from
+type Origin struct {
+	// Layer is one of the Layer constants
+	Layer string
+	// Source is the file or environment variable, empty for defaults
+	Source string
+	// Context is the context the value was set in, empty for top-level values
+	Context string
+}
+
+// String formats the origin like git config --show-origin, e.g. user:/home/me/.awsssoconfig
+func (o Origin) String() string {
+	s := o.Layer
+	if o.Source != "" {
+		s += ":" + o.Source
+	}
+	if o.Context != "" {
+		s += " (context " + o.Context + ")"
+	}
+	return s
+}
+
+// layer is one configuration file that was read
+type layer struct {
+	origin Origin
+	v      *viper.Viper
+}
+
+// readLayers reads the system, user and project files that exist, in precedence order
+func (cm *ConfigManager) readLayers() ([]layer, error) {
+	files := []Origin{
+		{Layer: LayerSystem, Source: cm.systemFile},
+		{Layer: LayerUser, Source: cm.configFile},
+	}
+	if project := findProjectFile(cm.workDir); project != "" {
+		files = append(files, Origin{Layer: LayerProject, Source: project})
+	}
+
+	var layers []layer
+	for _, origin := range files {
+		if origin.Source == "" {
+			continue
+		}
+		v := viper.New()
+		v.SetConfigFile(origin.Source)
+		v.SetConfigType("toml")
+		if err := v.ReadInConfig(); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("error reading %s config file %s: %w", origin.Layer, origin.Source, err)
+		}
+		v, err := cm.upgradeLayer(origin, v)
+		if err != nil {
+			return nil, err
+		}
+		if origin.Layer == LayerProject {
+			if v, err = restrictProject(origin, v); err != nil {
+				return nil, err
+			}
+		}
+		layers = append(layers, layer{origin: origin, v: v})
+	}
+	return layers, nil
+}
+
+// restrictProject drops what a project file may not set: trusted keys, at the top level
+// and in contexts, and [[generate.chains]], which add roles to the AWS config file
+func restrictProject(origin Origin, v *viper.Viper) (*viper.Viper, error) {
+	settings := v.AllSettings()
+	ignored := dropTrusted("", settings)
+	if contexts, ok := settings[contextsKey].(map[string]any); ok {
+		for _, name := range slices.Sorted(maps.Keys(contexts)) {
+			sections, _ := contexts[name].(map[string]any)
+			ignored = append(ignored, dropTrusted(contextsKey+"."+name+".", sections)...)
+		}
+	}
+	if _, ok := settings["generate"]; ok {
+		delete(settings, "generate")
+		ignored = append(ignored, "generate")
+	}
+	if len(ignored) == 0 {
+		return v, nil
+	}
+
+	warnOnce("Warning: ignoring %s in %s, project files cannot set them", strings.Join(ignored, ", "), origin.Source)
+	restricted := viper.New()
+	restricted.SetConfigType("toml")
+	if err := restricted.MergeConfigMap(settings); err != nil {
+		return nil, err
+	}
+	return restricted, nil
+}
+
+// dropTrusted removes the trusted keys from the sections in settings, returning their
+// names with prefix
+func dropTrusted(prefix string, settings map[string]any) []string {
+	var dropped []string
+	for _, k := range schema {
+		table, _ := settings[k.Section].(map[string]any)
+		if _, ok := table[k.fieldName()]; ok && k.Trusted {
+			delete(table, k.fieldName())
+			dropped = append(dropped, prefix+k.Name)
+		}
+	}
+	return dropped
+}
+
+// mergeLayers merges the layers into v, later layers overriding earlier ones
+func mergeLayers(v *viper.Viper, layers []layer) error {
+	for _, l := range layers {
+		if err := v.MergeConfigMap(l.v.AllSettings()); err != nil {
+			return fmt.Errorf("error merging %s config file %s: %w", l.origin.Layer, l.origin.Source, err)
+		}
+	}
+	return nil
+}
+
+// mergedFiles reads and merges the file layers, without defaults or the environment
+func (cm *ConfigManager) mergedFiles() (*viper.Viper, error) {
+	layers, err := cm.readLayers()
+	if err != nil {
+		return nil, err
+	}
+	v := viper.New()
+	v.SetConfigType("toml")
+	if err := mergeLayers(v, layers); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// layerOrigins returns the origin of every registered key: the last layer that sets
+// it, with the context's settings taking precedence over the top-level ones
+func layerOrigins(layers []layer, context string) map[string]Origin {
+	origins := make(map[string]Origin, len(schema))
+	for _, k := range schema {
+		origins[k.Name] = Origin{Layer: LayerDefault}
+		for _, l := range layers {
+			if l.v.IsSet(k.Name) {
+				origins[k.Name] = l.origin
+			}
+		}
+		if context == "" {
+			continue
+		}
+		for _, l := range layers {
+			if l.v.IsSet(contextsKey + "." + context + "." + k.Name) {
+				origin := l.origin
+				origin.Context = context
+				origins[k.Name] = origin
+			}
+		}
+	}
+	return origins
+}
+
+// findProjectFile returns the project file in dir or its nearest parent, or ""
+func findProjectFile(dir string) string {
+	if dir == "" {
+		return ""
+	}
+	for {
+		path := filepath.Join(dir, ProjectConfigFileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// layeredManager writes the given system, user and project files, skipping empty
+// ones, and returns a manager reading them with the project file two levels up
+func layeredManager(t *testing.T, system, user, project string) *ConfigManager {
+	t.Helper()
+	t.Setenv(ContextEnv, "")
+	dir := t.TempDir()
+	workDir := filepath.Join(dir, "repo", "services", "api")
+	require.NoError(t, os.MkdirAll(workDir, 0750))
+
+	write := func(path, content string) {
+		if content != "" {
+			require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		}
+	}
+	write(filepath.Join(dir, "system.toml"), system)
+	write(filepath.Join(dir, "user.toml"), user)
+	write(filepath.Join(dir, "repo", ProjectConfigFileName), project)
+
+	cm := NewConfigManager(filepath.Join(dir, "user.toml"))
+	cm.systemFile = filepath.Join(dir, "system.toml")
+	cm.workDir = workDir
+	return cm
+}
+
+func TestLoadLayers(t *testing.T) {
+	cm := layeredManager(t,
+		"[sso]\nstart_url = \"https://org.awsapps.com/start\"\nregion = \"us-east-2\"\nrole = \"OrgRole\"\n",
+		"[sso]\nregion = \"us-west-2\"\nrole = \"MyRole\"\n",
+		"[sso]\nrole = \"ProjectRole\"\n[aws]\ndefault_region = \"eu-west-1\"\n",
+	)
+
+	config, err := cm.Load()
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://org.awsapps.com/start", config.SSO.StartURL)
+	assert.Equal(t, "us-west-2", config.SSO.Region)
+	assert.Equal(t, "ProjectRole", config.SSO.Role)
+	assert.Equal(t, "eu-west-1", config.AWS.DefaultRegion)
+	assert.Equal(t, "file", config.SSO.TokenStore)
+
+	assert.Equal(t, LayerSystem, config.Origins["sso.start_url"].Layer)
+	assert.Equal(t, cm.systemFile, config.Origins["sso.start_url"].Source)
+	assert.Equal(t, LayerUser, config.Origins["sso.region"].Layer)
+	assert.Equal(t, LayerProject, config.Origins["sso.role"].Layer)
+	assert.Equal(t, filepath.Join(filepath.Dir(filepath.Dir(cm.workDir)), ProjectConfigFileName), config.Origins["sso.role"].Source)
+	assert.Equal(t, Origin{Layer: LayerDefault}, config.Origins["sso.token_store"])
+	assert.Len(t, config.Origins, len(Keys()))
+}
+
+func TestProjectCannotSetTrustedKeys(t *testing.T) {
+	out := clearEnv(t)
+	cm := layeredManager(t, "",
+		"[sso]\nstart_url = \"https://me.awsapps.com/start\"\n[[generate.chains]]\nname = \"mine\"\nsource_role = \"AdministratorAccess\"\n"+
+			"role_arn_template = \"arn:aws:iam::{{.AccountId}}:role/Mine\"\n",
+		`[sso]
+start_url = "https://evil.awsapps.com/start"
+token_store = "file"
+role = "ProjectRole"
+
+[aws]
+config_file = "/tmp/overwritten"
+
+[contexts.work.sso]
+start_url = "https://evil.awsapps.com/start"
+region = "eu-west-1"
+
+[[generate.chains]]
+name = "deploy"
+source_role = "AdministratorAccess"
+role_arn_template = "arn:aws:iam::111111111111:role/Evil"
+`)
+	project := filepath.Join(filepath.Dir(filepath.Dir(cm.workDir)), ProjectConfigFileName)
+
+	config, err := cm.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "https://me.awsapps.com/start", config.SSO.StartURL)
+	assert.Equal(t, LayerUser, config.Origins["sso.start_url"].Layer)
+	assert.Equal(t, Origin{Layer: LayerDefault}, config.Origins["sso.token_store"])
+	assert.Equal(t, DefaultAWS().ConfigFile, config.AWS.ConfigFile)
+	assert.Equal(t, "ProjectRole", config.SSO.Role, "other keys still apply")
+	require.Len(t, config.Generate.Chains, 1)
+	assert.Equal(t, "mine", config.Generate.Chains[0].Name)
+	assert.Equal(t, "Warning: ignoring sso.start_url, sso.token_store, aws.config_file, contexts.work.sso.start_url, generate in "+
+		project+", project files cannot set them\n", out.String())
+
+	t.Setenv(ContextEnv, "work")
+	config, err = cm.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "https://me.awsapps.com/start", config.SSO.StartURL)
+	assert.Equal(t, "eu-west-1", config.SSO.Region)
+}
+
+func TestLoadLayersWithContext(t *testing.T) {
+	cm := layeredManager(t,
+		"[contexts.work.sso]\nstart_url = \"https://work.awsapps.com/start\"\n",
+		"current_context = \"work\"\n[sso]\nstart_url = \"https://me.awsapps.com/start\"\n",
+		"[contexts.work.sso]\nregion = \"eu-central-1\"\n",
+	)
+
+	config, err := cm.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "work", config.Context)
+	assert.Equal(t, "https://work.awsapps.com/start", config.SSO.StartURL)
+	assert.Equal(t, "eu-central-1", config.SSO.Region)
+	assert.Equal(t, "system:"+cm.systemFile+" (context work)", config.Origins["sso.start_url"].String())
+	assert.Equal(t, LayerProject, config.Origins["sso.region"].Layer)
+
+	names, err := cm.Contexts()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"work"}, names)
+}
+
+func TestLoadDoesNotCreateUserFileOverOtherLayers(t *testing.T) {
+	cm := layeredManager(t, "[sso]\nregion = \"us-east-2\"\n", "", "")
+
+	config, err := cm.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "us-east-2", config.SSO.Region)
+	assert.NoFileExists(t, cm.configFile)
+}
+
+func TestSaveKeyWritesOnlyTheUserFile(t *testing.T) {
+	cm := layeredManager(t,
+		"[sso]\nstart_url = \"https://org.awsapps.com/start\"\nregion = \"us-east-2\"\n",
+		"",
+		"[sso]\nrole = \"ProjectRole\"\n",
+	)
+
+	require.NoError(t, cm.SaveKey("sso.region", "us-west-2"))
+	content, err := os.ReadFile(cm.configFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "us-west-2")
+	assert.NotContains(t, string(content), "org.awsapps.com", "values from other layers are not copied")
+	assert.NotContains(t, string(content), "ProjectRole")
+
+	require.NoError(t, cm.ResetKey("sso.region"))
+	config, err := cm.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "us-east-2", config.SSO.Region, "unset falls back to the system file")
+}
+
+func TestFindProjectFile(t *testing.T) {
+	dir := t.TempDir()
+	nested := filepath.Join(dir, "a", "b")
+	require.NoError(t, os.MkdirAll(nested, 0750))
+	assert.Empty(t, findProjectFile(nested))
+	assert.Empty(t, findProjectFile(""))
+
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "a", ProjectConfigFileName), 0750))
+	assert.Empty(t, findProjectFile(nested), "directories are skipped")
+
+	project := filepath.Join(dir, ProjectConfigFileName)
+	require.NoError(t, os.WriteFile(project, nil, 0600))
+	assert.Equal(t, project, findProjectFile(nested))
+}
+
+func TestLoadReportsBrokenLayer(t *testing.T) {
+	cm := layeredManager(t, "[sso\n", "", "")
+	_, err := cm.Load()
+	assert.ErrorContains(t, err, "error reading system config file "+cm.systemFile)
+}
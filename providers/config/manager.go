@@ -1,143 +1,212 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 
 	"github.com/mitchellh/go-homedir"
 	"github.com/spf13/viper"
 )
 
-// ConfigManager handles loading from a single configuration file with multiple sections
+// ConfigManager loads the layered configuration and writes to the user file, see
+// the Layer constants for the order layers are merged in
 type ConfigManager struct {
 	configFile string
+	systemFile string
+	workDir    string
 }
 
-// NewConfigManager creates a new configuration manager
+// NewConfigManager creates a new configuration manager. configFile is the user
+// layer, ~/.awsssoconfig when empty.
 func NewConfigManager(configFile string) *ConfigManager {
 	if configFile == "" {
 		home, _ := homedir.Dir()
 		configFile = filepath.Join(home, ".awsssoconfig")
 	}
-	return &ConfigManager{configFile: configFile}
+	workDir, _ := os.Getwd()
+	return &ConfigManager{configFile: configFile, systemFile: SystemConfigFile, workDir: workDir}
 }
 
-// Load loads configuration from a single file with multiple sections
+// Path returns the user file, the only file the manager writes to
+func (cm *ConfigManager) Path() string {
+	return cm.configFile
+}
+
+// Load loads and merges the configuration layers. Missing files are skipped, and
+// without any the defaults apply; nothing is written, see Create and the init command.
 func (cm *ConfigManager) Load() (*Config, error) {
-	v := viper.New()
+	layers, err := cm.readLayers()
+	if err != nil {
+		return nil, err
+	}
 
-	// Set up configuration file
-	v.SetConfigFile(cm.configFile)
+	v := viper.New()
 	v.SetConfigType("toml")
-
-	// Environment variable configuration
-	v.SetEnvPrefix("AWS_SSO_CONFIG")
-	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-	v.AutomaticEnv()
-
-	// Read config file (create if not found)
-	if err := v.ReadInConfig(); err != nil {
-		// Check for "file not found" error more broadly
-		if strings.Contains(err.Error(), "no such file or directory") ||
-			strings.Contains(err.Error(), "cannot find the file") {
-			// Create default config file
-			if createErr := cm.createDefaultConfig(); createErr == nil {
-				// Try reading again after creation
-				if readErr := v.ReadInConfig(); readErr != nil {
-					return nil, fmt.Errorf("error reading newly created config file: %w", readErr)
-				}
-			} else {
-				return nil, fmt.Errorf("error creating config file: %w", createErr)
-			}
-		} else {
-			return nil, fmt.Errorf("error reading config file: %w", err)
-		}
+	if err := mergeLayers(v, layers); err != nil {
+		return nil, err
 	}
 
 	config := &Config{}
+	if err := decodeSections(v, config); err != nil {
+		return nil, err
+	}
+
+	// Merge the selected context over the top-level settings
+	if err := applyContext(v, config); err != nil {
+		return nil, err
+	}
+	config.Origins = layerOrigins(layers, config.Context)
+
+	// Environment variables override every file, see applyEnv
+	if err := applyEnv(config); err != nil {
+		return nil, err
+	}
+
+	// Set defaults for any missing values
+	config.SetDefaults()
 
+	return config, nil
+}
+
+// decodeSections unmarshals the top-level sections of v into config
+func decodeSections(v *viper.Viper, config *Config) error {
 	// Load SSO section
 	if ssoData := v.Sub("sso"); ssoData != nil {
 		if err := ssoData.Unmarshal(&config.SSO); err != nil {
-			return nil, fmt.Errorf("error unmarshaling SSO config: %w", err)
+			return fmt.Errorf("error unmarshaling SSO config: %w", err)
 		}
 	}
 
 	// Load AWS section
 	if awsData := v.Sub("aws"); awsData != nil {
 		if err := awsData.Unmarshal(&config.AWS); err != nil {
-			return nil, fmt.Errorf("error unmarshaling AWS config: %w", err)
+			return fmt.Errorf("error unmarshaling AWS config: %w", err)
 		}
 	}
 
-	// Set defaults for any missing values
-	config.SetDefaults()
+	// Load generate section
+	if generateData := v.Sub("generate"); generateData != nil {
+		if err := generateData.Unmarshal(&config.Generate); err != nil {
+			return fmt.Errorf("error unmarshaling generate config: %w", err)
+		}
+	}
 
-	return config, nil
+	// Load repository mappings, an array of tables rather than a section
+	if err := v.UnmarshalKey("repos", &config.Repos); err != nil {
+		return fmt.Errorf("error unmarshaling repos config: %w", err)
+	}
+	return nil
 }
 
-// createDefaultConfig creates a default configuration file with all sections
-func (cm *ConfigManager) createDefaultConfig() error {
+// Create writes the user file with the sso and aws settings of config, defaults for
+// those it leaves unset, and commented examples of the other sections. An existing
+// file is replaced.
+func (cm *ConfigManager) Create(config *Config) error {
 	// Ensure the directory exists
 	configDir := filepath.Dir(cm.configFile)
 	if err := os.MkdirAll(configDir, 0750); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	// Combine default content from all sections
-	sso := DefaultSSO()
-	aws := DefaultAWS()
+	// Combine the content of all sections
+	generate := DefaultGenerate()
+	var repos ReposConfig
 
-	content := sso.GetDefaultContent() + "\n" + aws.GetDefaultContent()
+	content := fmt.Sprintf("# Layout version of this file, upgraded automatically\n%s = %d\n\n", versionKey, CurrentVersion) +
+		config.SSO.GetDefaultContent() + "\n" + config.AWS.GetDefaultContent() + "\n" + generate.GetDefaultContent() + "\n" + repos.GetDefaultContent()
 
 	return os.WriteFile(cm.configFile, []byte(content), 0600)
 }
 
 // SaveProviderConfig saves a provider configuration section to the single config file
 func (cm *ConfigManager) SaveProviderConfig(provider string, data interface{}) error {
-	v := viper.New()
-	v.SetConfigFile(cm.configFile)
-	v.SetConfigType("toml")
-
-	// Try to read existing config first
-	_ = v.ReadInConfig() // Ignore error if file doesn't exist
+	// Read the existing file, in the current layout, if there is one
+	v, err := cm.readFile()
+	if err != nil {
+		return err
+	}
 
-	// Set the provider section data
-	switch provider {
-	case "sso":
-		if ssoData, ok := data.(SSOConfig); ok {
-			if ssoData.StartURL != "" {
-				v.Set("sso.start_url", ssoData.StartURL)
-			}
-			if ssoData.Region != "" {
-				v.Set("sso.region", ssoData.Region)
-			}
-			if ssoData.Role != "" {
-				v.Set("sso.role", ssoData.Role)
+	// Set the provider section data, leaving unset fields as they are in the file
+	keys, ok := sectionKeys(provider)
+	if !ok {
+		return fmt.Errorf("unknown provider: %s", provider)
+	}
+	section := reflect.ValueOf(data)
+	if section.IsValid() && section.Type() == reflect.TypeOf(Config{}).Field(keys[0].index[0]).Type {
+		for _, k := range keys {
+			if field := section.Field(k.index[1]); !field.IsZero() {
+				v.Set(k.Name, field.Interface())
 			}
 		}
-	case "aws":
-		if awsData, ok := data.(AWSConfig); ok {
-			if awsData.DefaultRegion != "" {
-				v.Set("aws.default_region", awsData.DefaultRegion)
-			}
-			if awsData.ConfigFile != "" {
-				v.Set("aws.config_file", awsData.ConfigFile)
-			}
+	}
+	return cm.writeSettings(v.AllSettings())
+}
+
+// SaveKey sets a single registered key in the user file, or in the active context
+// there. Other layers are left alone.
+func (cm *ConfigManager) SaveKey(name string, value string) error {
+	k, ok := LookupKey(name)
+	if !ok {
+		return fmt.Errorf("unknown configuration key: %s", name)
+	}
+
+	config, err := cm.Load()
+	if errors.Is(err, ErrUnknownContext) {
+		return err
+	}
+	if err != nil {
+		config = Default()
+	}
+	if err := k.Set(config, value); err != nil {
+		return err
+	}
+
+	path := k.Name
+	if config.Context != "" {
+		path = contextsKey + "." + config.Context + "." + k.Name
+	}
+	return cm.saveValue(path, k.value(config))
+}
+
+// SaveKeys sets the named registered keys at the top level of the user file to their
+// values in config, keeping everything else in the file
+func (cm *ConfigManager) SaveKeys(config *Config, names ...string) error {
+	v, err := cm.readFile()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		k, ok := LookupKey(name)
+		if !ok {
+			return fmt.Errorf("unknown configuration key: %s", name)
 		}
-	default:
-		return fmt.Errorf("unknown provider: %s", provider)
+		v.Set(k.Name, k.value(config))
 	}
+	return cm.writeSettings(v.AllSettings())
+}
 
-	// Ensure the directory exists
-	configDir := filepath.Dir(cm.configFile)
-	if err := os.MkdirAll(configDir, 0750); err != nil {
-		return fmt.Errorf("failed to create config directory: %w", err)
+// ResetKey removes a single registered key from the user file, or from the active
+// context there, so the value from a lower layer or the default applies again
+func (cm *ConfigManager) ResetKey(name string) error {
+	selection, err := cm.CurrentContext()
+	if err != nil {
+		return err
 	}
+	return cm.removeKey(selection.Name, name)
+}
 
-	return v.WriteConfig()
+// saveValue sets a dotted path in the user file
+func (cm *ConfigManager) saveValue(path string, value any) error {
+	v, err := cm.readFile()
+	if err != nil {
+		return err
+	}
+	v.Set(path, value)
+	return cm.writeSettings(v.AllSettings())
 }
 
 // LoadConfigForKey loads only the configuration needed for a specific key
@@ -150,18 +219,22 @@ func LoadConfigForKey(configFile string, key string) (*Config, error) {
 		cm = NewConfigManager("")
 	}
 
+	if !strings.HasPrefix(key, "sso.") && !strings.HasPrefix(key, "aws.") {
+		return nil, fmt.Errorf("unknown key prefix for key: %s", key)
+	}
 	config := &Config{}
 
 	// Load the full config since it's in a single file anyway
 	fullConfig, err := cm.Load()
+	if errors.Is(err, ErrUnknownContext) {
+		return nil, err
+	}
 	if err != nil {
 		// If loading fails, create defaults for the specific provider
 		if strings.HasPrefix(key, "sso.") {
 			config.SSO = DefaultSSO()
-		} else if strings.HasPrefix(key, "aws.") {
-			config.AWS = DefaultAWS()
 		} else {
-			return nil, fmt.Errorf("unknown key prefix for key: %s", key)
+			config.AWS = DefaultAWS()
 		}
 		return config, nil
 	}
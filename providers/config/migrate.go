@@ -0,0 +1,233 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// versionKey is the top-level key holding the layout version of a configuration file.
+// Files without it are version 0.
+const versionKey = "version"
+
+// migration upgrades settings from the version before it to its own version, returning
+// a note for each change it made
+type migration func(settings map[string]any) []string
+
+// migrations[i] upgrades version i to i+1. Add a migration here when a key is renamed
+// or moved; files are upgraded in order when they are read.
+var migrations = []migration{
+	migrateFlatLayout,
+}
+
+// CurrentVersion is the layout version this release writes
+var CurrentVersion = len(migrations)
+
+// legacyKeys maps the flat keys of the first layout to their registered keys. An empty
+// key marks a setting that was never implemented and is dropped.
+var legacyKeys = map[string]string{
+	"sso_start_url":  "sso.start_url",
+	"sso_region":     "sso.region",
+	"sso_role":       "sso.role",
+	"default_region": "aws.default_region",
+	"config_file":    "aws.config_file",
+	"backup_configs": "",
+	"dry_run":        "",
+}
+
+// migrateFlatLayout moves the flat keys of the first layout into the [sso] and [aws]
+// sections. A key already set in its section wins over the flat one.
+func migrateFlatLayout(settings map[string]any) []string {
+	var notes []string
+	for _, old := range slices.Sorted(maps.Keys(legacyKeys)) {
+		value, ok := settings[old]
+		if !ok {
+			continue
+		}
+		delete(settings, old)
+
+		name := legacyKeys[old]
+		if name == "" {
+			notes = append(notes, fmt.Sprintf("removed %s, which is not supported", old))
+			continue
+		}
+		section, field, _ := strings.Cut(name, ".")
+		table, _ := settings[section].(map[string]any)
+		if table == nil {
+			table = map[string]any{}
+			settings[section] = table
+		}
+		if _, ok := table[field]; ok {
+			notes = append(notes, fmt.Sprintf("removed %s, %s is already set", old, name))
+			continue
+		}
+		table[field] = value
+		notes = append(notes, fmt.Sprintf("moved %s to %s", old, name))
+	}
+	return notes
+}
+
+// migrate upgrades settings to CurrentVersion in place, returning the version they
+// had and a note for each change
+func migrate(settings map[string]any) (int, []string, error) {
+	version := 0
+	switch v := settings[versionKey].(type) {
+	case nil:
+	case int64:
+		version = int(v)
+	case int:
+		version = v
+	default:
+		return 0, nil, fmt.Errorf("version must be a whole number, got %v", v)
+	}
+	if version < 0 || version > CurrentVersion {
+		return 0, nil, fmt.Errorf("version %d is not supported by this release, which reads up to version %d; upgrade aws-sso-config",
+			version, CurrentVersion)
+	}
+
+	var notes []string
+	for _, m := range migrations[version:] {
+		notes = append(notes, m(settings)...)
+	}
+	settings[versionKey] = CurrentVersion
+	return version, notes, nil
+}
+
+// upgradeLayer migrates a file that was read to the current layout in memory and warns
+// about unknown keys. Reading never writes: the user file is saved in the current
+// layout by the next change to it, see backupOldLayout, and other files are not ours
+// to write.
+func (cm *ConfigManager) upgradeLayer(origin Origin, v *viper.Viper) (*viper.Viper, error) {
+	settings := v.AllSettings()
+	version, notes, err := migrate(settings)
+	if err != nil {
+		return nil, fmt.Errorf("%s config file %s: %w", origin.Layer, origin.Source, err)
+	}
+	for _, key := range unknownKeys(settings) {
+		warnOnce("Warning: unknown configuration key %s in %s", key, origin.Source)
+	}
+	if len(notes) == 0 {
+		return v, nil
+	}
+
+	migrated := viper.New()
+	migrated.SetConfigType("toml")
+	if err := migrated.MergeConfigMap(settings); err != nil {
+		return nil, err
+	}
+
+	if origin.Layer != LayerUser {
+		warnOnce("Note: %s uses the version %d layout, update it: %s", origin.Source, version, strings.Join(notes, "; "))
+		return migrated, nil
+	}
+	warnOnce("Note: %s uses the version %d layout, it is upgraded in memory and saved as version %d by your next change: %s",
+		origin.Source, version, CurrentVersion, strings.Join(notes, "; "))
+	return migrated, nil
+}
+
+// backupOldLayout copies the user file to <file>.v<version>.bak before it is first
+// written in the current layout, if migrating it changes anything
+func (cm *ConfigManager) backupOldLayout() error {
+	original, err := os.ReadFile(cm.configFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	v := viper.New()
+	v.SetConfigType("toml")
+	if err := v.ReadConfig(bytes.NewReader(original)); err != nil {
+		return fmt.Errorf("error reading config file: %w", err)
+	}
+	version, notes, err := migrate(v.AllSettings())
+	if err != nil || len(notes) == 0 {
+		return err
+	}
+
+	backup := fmt.Sprintf("%s.v%d.bak", cm.configFile, version)
+	if err := os.WriteFile(backup, original, 0600); err != nil {
+		return fmt.Errorf("error saving %s before migrating it: %w", cm.configFile, err)
+	}
+	Logger.Printf("Note: migrated %s to version %d, the original is saved as %s", cm.configFile, CurrentVersion, backup)
+	return nil
+}
+
+// contextType holds the sections a context may set
+var contextType = reflect.TypeOf(contextSettings{})
+
+// unknownKeys returns the dotted names of the keys in settings that are not part of
+// Config, a context, version or current_context, sorted
+func unknownKeys(settings map[string]any) []string {
+	var unknown []string
+	for _, key := range slices.Sorted(maps.Keys(settings)) {
+		switch key {
+		case versionKey, currentContextKey:
+		case contextsKey:
+			contexts, _ := settings[key].(map[string]any)
+			for _, name := range slices.Sorted(maps.Keys(contexts)) {
+				unknown = append(unknown, unknownFields(contextsKey+"."+name, contexts[name], contextType)...)
+			}
+		default:
+			unknown = append(unknown, unknownFields("", map[string]any{key: settings[key]}, reflect.TypeOf(Config{}))...)
+		}
+	}
+	return unknown
+}
+
+// unknownFields returns the keys of value, named from prefix, that have no field
+// with a matching toml tag in typ. Tables and arrays of tables are checked recursively.
+func unknownFields(prefix string, value any, typ reflect.Type) []string {
+	switch typ.Kind() {
+	case reflect.Struct:
+		table, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		var unknown []string
+		for _, key := range slices.Sorted(maps.Keys(table)) {
+			name := key
+			if prefix != "" {
+				name = prefix + "." + key
+			}
+			field, ok := fieldByTag(typ, key)
+			if !ok {
+				unknown = append(unknown, name)
+				continue
+			}
+			unknown = append(unknown, unknownFields(name, table[key], field.Type)...)
+		}
+		return unknown
+	case reflect.Slice:
+		items := reflect.ValueOf(value)
+		if items.Kind() != reflect.Slice {
+			return nil
+		}
+		var unknown []string
+		for i := 0; i < items.Len(); i++ {
+			unknown = append(unknown, unknownFields(fmt.Sprintf("%s[%d]", prefix, i), items.Index(i).Interface(), typ.Elem())...)
+		}
+		return unknown
+	default:
+		return nil
+	}
+}
+
+// fieldByTag finds the field of typ with the toml tag name, ignoring fields tagged "-"
+func fieldByTag(typ reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if tag := tagName(field); tag != "-" && strings.EqualFold(tag, name) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const flatConfig = `# AWS Config Tool Configuration
+sso_start_url = "https://flat.awsapps.com/start"
+sso_region = "eu-west-1"
+default_region = "eu-west-2"
+backup_configs = true
+dry_run = false
+
+[sso]
+region = "us-west-2"
+`
+
+const flatNotes = "removed backup_configs, which is not supported; moved default_region to aws.default_region; " +
+	"removed dry_run, which is not supported; removed sso_region, sso.region is already set; moved sso_start_url to sso.start_url"
+
+func TestMigrateUserFile(t *testing.T) {
+	out := clearEnv(t)
+	cm := layeredManager(t, "", flatConfig, "")
+
+	config, err := cm.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "https://flat.awsapps.com/start", config.SSO.StartURL)
+	assert.Equal(t, "us-west-2", config.SSO.Region, "the sectioned key wins")
+	assert.Equal(t, "eu-west-2", config.AWS.DefaultRegion)
+	assert.Equal(t, "Note: "+cm.configFile+" uses the version 0 layout, it is upgraded in memory and saved as version 1 by your next change: "+
+		flatNotes+"\n", out.String())
+
+	// Reading leaves the disk alone
+	data, err := os.ReadFile(cm.configFile)
+	require.NoError(t, err)
+	assert.Equal(t, flatConfig, string(data))
+	assert.NoFileExists(t, cm.configFile+".v0.bak")
+
+	// The next change saves the upgrade, after backing up the original
+	out.Reset()
+	require.NoError(t, cm.SaveKey("sso.role", "Dev"))
+	assert.Equal(t, "Note: migrated "+cm.configFile+" to version 1, the original is saved as "+cm.configFile+".v0.bak\n", out.String())
+	backup, err := os.ReadFile(cm.configFile + ".v0.bak")
+	require.NoError(t, err)
+	assert.Equal(t, flatConfig, string(backup))
+	migrated, err := os.ReadFile(cm.configFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(migrated), "version = 1")
+	assert.Contains(t, string(migrated), "https://flat.awsapps.com/start")
+	assert.NotContains(t, string(migrated), "sso_start_url")
+	assert.NotContains(t, string(migrated), "dry_run")
+
+	out.Reset()
+	config, err = cm.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "Dev", config.SSO.Role)
+	assert.Empty(t, out.String(), "a migrated file is left alone")
+}
+
+func TestMigrateOtherLayers(t *testing.T) {
+	out := clearEnv(t)
+	cm := layeredManager(t, "", "", "sso_role = \"ProjectRole\"\n")
+	project := filepath.Join(filepath.Dir(filepath.Dir(cm.workDir)), ProjectConfigFileName)
+
+	config, err := cm.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "ProjectRole", config.SSO.Role)
+	assert.Equal(t, LayerProject, config.Origins["sso.role"].Layer)
+	assert.Equal(t, "Note: "+project+" uses the version 0 layout, update it: moved sso_role to sso.role\n", out.String())
+
+	data, err := os.ReadFile(project)
+	require.NoError(t, err)
+	assert.Equal(t, "sso_role = \"ProjectRole\"\n", string(data), "only the user file is rewritten")
+	assert.NoFileExists(t, project+".v0.bak")
+}
+
+func TestMigrateVersion(t *testing.T) {
+	clearEnv(t)
+
+	_, err := layeredManager(t, "", "version = 2\n", "").Load()
+	assert.ErrorContains(t, err, "version 2 is not supported by this release, which reads up to version 1; upgrade aws-sso-config")
+	_, err = layeredManager(t, "", "version = \"one\"\n", "").Load()
+	assert.ErrorContains(t, err, "version must be a whole number")
+
+	cm := layeredManager(t, "", "", "")
+	require.NoError(t, cm.SaveKey("sso.role", "Dev"))
+	data, err := os.ReadFile(cm.configFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "version = 1", "written files are marked with the current version")
+}
+
+func TestUnknownKeysWarn(t *testing.T) {
+	out := clearEnv(t)
+	cm := layeredManager(t, "", `version = 1
+current_context = "work"
+
+[sso]
+startt_url = "https://typo.awsapps.com/start"
+
+[naming]
+style = "short"
+
+[contexts.work.sso]
+role = "Dev"
+rgion = "eu-west-1"
+
+[[repos]]
+pathh = "~/src/*"
+profile = "src"
+
+[[generate.chains]]
+name = "deploy"
+`, "")
+
+	unknown := unknownKeys(mustSettings(t, cm))
+	assert.Equal(t, []string{"contexts.work.sso.rgion", "naming", "repos[0].pathh", "sso.startt_url"}, unknown)
+
+	_, _ = cm.Load()
+	for _, key := range unknown {
+		assert.Contains(t, out.String(), "Warning: unknown configuration key "+key+" in "+cm.configFile+"\n")
+	}
+}
+
+// mustSettings reads the user file as it is
+func mustSettings(t *testing.T, cm *ConfigManager) map[string]any {
+	t.Helper()
+	v, err := cm.readFile()
+	require.NoError(t, err)
+	return v.AllSettings()
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+// ReposConfig maps git repositories to AWS profiles, first match wins
+type ReposConfig []RepoConfig
+
+// RepoConfig maps the repositories matching Path or Remote to Profile
+type RepoConfig struct {
+	// Path is a glob matched against the repository's root directory, ~ is expanded
+	Path string `mapstructure:"path" toml:"path"`
+	// Remote is a glob matched against the origin URL, compared without scheme, user and .git
+	// suffix so that "github.com/acme/*" matches both SSH and HTTPS clones
+	Remote string `mapstructure:"remote" toml:"remote"`
+	// Profile is the AWS profile to use in matching repositories
+	Profile string `mapstructure:"profile" toml:"profile"`
+}
+
+// Validate checks every mapping
+func (r ReposConfig) Validate() error {
+	for i := range r {
+		if err := r[i].Validate(); err != nil {
+			return fmt.Errorf("repos entry %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Match returns the first mapping matching a repository, or nil
+func (r ReposConfig) Match(root, remote string) *RepoConfig {
+	for i := range r {
+		if r[i].Matches(root, remote) {
+			return &r[i]
+		}
+	}
+	return nil
+}
+
+// GetSectionName returns the TOML section name for repository mappings
+func (r ReposConfig) GetSectionName() string {
+	return "repos"
+}
+
+// GetDefaultContent returns the default TOML content for repository mappings
+func (r ReposConfig) GetDefaultContent() string {
+	return `# Repository Profiles
+# Map git repositories to profiles by path or origin URL, first match wins:
+# [[repos]]
+# path = "~/src/infrastructure/*"
+# profile = "infrastructure"
+#
+# [[repos]]
+# remote = "github.com/acme/payments-*"
+# profile = "payments"
+`
+}
+
+// Validate checks that the mapping has a profile and exactly one valid pattern
+func (r *RepoConfig) Validate() error {
+	if r.Profile == "" {
+		return fmt.Errorf("profile is required")
+	}
+	if (r.Path == "") == (r.Remote == "") {
+		return fmt.Errorf("%s: set either path or remote", r.Profile)
+	}
+	if _, err := filepath.Match(r.Path, ""); err != nil {
+		return fmt.Errorf("%s: invalid path pattern %q: %w", r.Profile, r.Path, err)
+	}
+	if _, err := path.Match(NormalizeRemote(r.Remote), ""); err != nil {
+		return fmt.Errorf("%s: invalid remote pattern %q: %w", r.Profile, r.Remote, err)
+	}
+	return nil
+}
+
+// Matches reports whether the repository rooted at root with the given origin URL matches
+func (r *RepoConfig) Matches(root, remote string) bool {
+	if r.Path != "" {
+		pattern, err := homedir.Expand(r.Path)
+		if err != nil {
+			return false
+		}
+		matched, _ := filepath.Match(filepath.Clean(pattern), filepath.Clean(root))
+		return matched
+	}
+	if remote == "" {
+		return false
+	}
+	matched, _ := path.Match(NormalizeRemote(r.Remote), NormalizeRemote(remote))
+	return matched
+}
+
+// String describes the mapping as it is written in the config file
+func (r *RepoConfig) String() string {
+	if r.Path != "" {
+		return fmt.Sprintf("[[repos]] path = %q", r.Path)
+	}
+	return fmt.Sprintf("[[repos]] remote = %q", r.Remote)
+}
+
+// NormalizeRemote reduces a git remote URL to host/path, so that
+// git@github.com:acme/app.git, ssh://git@github.com/acme/app and
+// https://github.com/acme/app.git all become github.com/acme/app
+func NormalizeRemote(remote string) string {
+	remote = strings.TrimSpace(remote)
+	if _, rest, ok := strings.Cut(remote, "://"); ok {
+		remote = rest
+	} else if host, rest, ok := strings.Cut(remote, ":"); ok && !strings.Contains(host, "/") {
+		// scp-like syntax, [user@]host:path
+		remote = host + "/" + rest
+	}
+
+	// Drop the user and port from the host
+	host, rest, _ := strings.Cut(remote, "/")
+	host = host[strings.LastIndex(host, "@")+1:]
+	if i := strings.LastIndex(host, ":"); i >= 0 {
+		host = host[:i]
+	}
+
+	remote = strings.TrimSuffix(strings.TrimSuffix(host+"/"+rest, "/"), ".git")
+	return strings.ToLower(remote)
+}
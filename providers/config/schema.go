@@ -0,0 +1,228 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// EnvPrefix prefixes the environment variables that override configuration keys
+const EnvPrefix = "AWS_SSO_CONFIG"
+
+// Key describes a single settable configuration key. Keys are registered from the
+// section structs held by Config: a field described in descriptions becomes the key
+// <section>.<toml name>, optionally checked by the validator named in its validate tag and overridden by the environment variable in its env tag. A
+// deprecated_env tag names an older variable that still works, with a warning, and
+// trusted:"true" keeps the key out of project files.
+type Key struct {
+	// Name is the dotted key, e.g. sso.start_url
+	Name string
+	// Section is the TOML section the key lives in, e.g. sso
+	Section string
+	// Type is the Go kind of the field, string or int
+	Type reflect.Kind
+	// Description is shown in help and key listings
+	Description string
+	// Validator names the validator that checks values before they are set
+	Validator string
+	// Env is the environment variable that overrides the key
+	Env string
+	// DeprecatedEnv is an older variable that overrides the key when Env is not set
+	DeprecatedEnv string
+	// Trusted keys decide where logins go, where tokens are kept or which file is
+	// written, so a project file, which comes with any checked-out repository,
+	// cannot set them
+	Trusted bool
+
+	index []int
+}
+
+// descriptions registers the configuration keys, and describes them in help and key
+// listings. They are kept out of the struct tags to keep those readable.
+var descriptions = map[string]string{
+	"sso.start_url":                  "Your AWS SSO start URL",
+	"sso.region":                     "AWS region for SSO (e.g., us-east-1)",
+	"sso.role":                       "SSO role name (e.g., AdministratorAccess)",
+	"sso.token_store":                "Where SSO tokens are cached (file, keyring, encrypted-file)",
+	"sso.credential_refresh_minutes": "Minutes before expiry that cached role credentials are refreshed",
+	"aws.default_region":             "Default AWS region for profiles",
+	"aws.config_file":                "Path to AWS config file",
+}
+
+// schema holds every registered key in declaration order
+var schema = buildSchema(reflect.TypeOf(Config{}), descriptions)
+
+// Keys returns every registered configuration key in declaration order
+func Keys() []Key {
+	keys := make([]Key, len(schema))
+	copy(keys, schema)
+	return keys
+}
+
+// KeyNames returns the names of every registered configuration key
+func KeyNames() []string {
+	names := make([]string, 0, len(schema))
+	for _, k := range schema {
+		names = append(names, k.Name)
+	}
+	return names
+}
+
+// LookupKey returns the registered key with the given name
+func LookupKey(name string) (Key, bool) {
+	for _, k := range schema {
+		if k.Name == name {
+			return k, true
+		}
+	}
+	return Key{}, false
+}
+
+// Get returns the key's value in config, formatted as it would be set
+func (k Key) Get(config *Config) string {
+	return formatValue(reflect.ValueOf(config).Elem().FieldByIndex(k.index))
+}
+
+// Set validates value and stores it in config
+func (k Key) Set(config *Config, value string) error {
+	if err := k.Validate(value); err != nil {
+		return err
+	}
+	return k.assign(config, value)
+}
+
+// load stores a value from a context or the environment in config, validated like Set
+// unless it is the key's default, which is accepted without validation like config
+// unset does
+func (k Key) load(config *Config, value string) error {
+	if isDefault(k, value) {
+		return k.assign(config, value)
+	}
+	return k.Set(config, value)
+}
+
+// assign converts value to the key's type and stores it in config, without validating it
+func (k Key) assign(config *Config, value string) error {
+	field := reflect.ValueOf(config).Elem().FieldByIndex(k.index)
+	switch k.Type {
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid value for %s: %q is not a whole number", k.Name, value)
+		}
+		field.SetInt(int64(n))
+	default:
+		field.SetString(value)
+	}
+	return nil
+}
+
+// Validate runs the key's validator, if it has one, against value
+func (k Key) Validate(value string) error {
+	if k.Validator == "" {
+		return nil
+	}
+	if err := validators[k.Validator](value); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", k.Name, err)
+	}
+	return nil
+}
+
+// Default returns the key's default value, as set by Default
+func (k Key) Default() string {
+	return k.Get(Default())
+}
+
+// value returns the key's typed value in config
+func (k Key) value(config *Config) any {
+	return reflect.ValueOf(config).Elem().FieldByIndex(k.index).Interface()
+}
+
+// fieldName returns the key's name within its section
+func (k Key) fieldName() string {
+	return k.Name[len(k.Section)+1:]
+}
+
+// Reset stores the key's default value in config. Defaults are not validated, the
+// default config file for one may not exist yet.
+func (k Key) Reset(config *Config) {
+	field := reflect.ValueOf(config).Elem().FieldByIndex(k.index)
+	field.Set(reflect.ValueOf(Default()).Elem().FieldByIndex(k.index))
+}
+
+// sectionKeys returns the keys registered for a section, or false if it has none
+func sectionKeys(section string) ([]Key, bool) {
+	var keys []Key
+	for _, k := range schema {
+		if k.Section == section {
+			keys = append(keys, k)
+		}
+	}
+	return keys, len(keys) > 0
+}
+
+// buildSchema registers every field of the struct sections of config that has an
+// entry in descriptions. It panics on a tag it cannot honour or a description without
+// a field, as that is a programming error.
+func buildSchema(config reflect.Type, descriptions map[string]string) []Key {
+	var keys []Key
+	for i := 0; i < config.NumField(); i++ {
+		section := config.Field(i)
+		if section.Type.Kind() != reflect.Struct {
+			continue
+		}
+		sectionName := tagName(section)
+		for j := 0; j < section.Type.NumField(); j++ {
+			field := section.Type.Field(j)
+			name := sectionName + "." + tagName(field)
+			desc, ok := descriptions[name]
+			if !ok {
+				continue
+			}
+			k := Key{
+				Name:        name,
+				Section:     sectionName,
+				Type:        field.Type.Kind(),
+				Description: desc,
+				Validator:   field.Tag.Get("validate"),
+				Env:         field.Tag.Get("env"),
+				index:       []int{i, j},
+
+				DeprecatedEnv: field.Tag.Get("deprecated_env"),
+				Trusted:       field.Tag.Get("trusted") == "true",
+			}
+			if k.Env == "" {
+				k.Env = EnvPrefix + "_" + strings.ToUpper(strings.ReplaceAll(k.Name, ".", "_"))
+			}
+			if k.Type != reflect.String && k.Type != reflect.Int {
+				panic(fmt.Sprintf("config key %s has unsupported type %s", k.Name, field.Type))
+			}
+			if _, ok := validators[k.Validator]; k.Validator != "" && !ok {
+				panic(fmt.Sprintf("config key %s names unknown validator %q", k.Name, k.Validator))
+			}
+			keys = append(keys, k)
+		}
+	}
+	for name := range descriptions {
+		if !slices.ContainsFunc(keys, func(k Key) bool { return k.Name == name }) {
+			panic(fmt.Sprintf("config key %s has a description but no field", name))
+		}
+	}
+	return keys
+}
+
+// tagName returns the TOML name of a struct field
+func tagName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("toml"), ",")
+	return name
+}
+
+// formatValue formats a string or int field
+func formatValue(v reflect.Value) string {
+	if v.Kind() == reflect.Int {
+		return strconv.FormatInt(v.Int(), 10)
+	}
+	return v.String()
+}
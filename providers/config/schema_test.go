@@ -0,0 +1,131 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEveryFieldIsRegistered(t *testing.T) {
+	sections := map[string]reflect.Type{
+		"sso": reflect.TypeOf(SSOConfig{}),
+		"aws": reflect.TypeOf(AWSConfig{}),
+	}
+
+	for section, typ := range sections {
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			name := section + "." + tagName(field)
+			k, ok := LookupKey(name)
+			if assert.True(t, ok, "%s.%s needs an entry in descriptions to register %s", typ.Name(), field.Name, name) {
+				assert.NotEmpty(t, k.Description, name)
+				assert.Equal(t, section, k.Section)
+			}
+		}
+	}
+	assert.Len(t, Keys(), 7)
+}
+
+func TestKeys(t *testing.T) {
+	t.Run("in declaration order", func(t *testing.T) {
+		assert.Equal(t, []string{
+			"sso.start_url",
+			"sso.region",
+			"sso.role",
+			"sso.token_store",
+			"sso.credential_refresh_minutes",
+			"aws.default_region",
+			"aws.config_file",
+		}, KeyNames())
+	})
+
+	t.Run("get and set", func(t *testing.T) {
+		config := &Config{}
+		values := map[string]string{
+			"sso.start_url":                  "https://example.awsapps.com/start",
+			"sso.region":                     "eu-west-1",
+			"sso.role":                       "ReadOnly",
+			"sso.token_store":                "keyring",
+			"sso.credential_refresh_minutes": "5",
+			"aws.default_region":             "us-west-2",
+			"aws.config_file":                filepath.Join(t.TempDir(), "config"),
+		}
+		for _, k := range Keys() {
+			require.NoError(t, k.Set(config, values[k.Name]), k.Name)
+			assert.Equal(t, values[k.Name], k.Get(config), k.Name)
+		}
+		assert.Equal(t, 5, config.SSO.CredentialRefreshMinutes)
+		assert.Equal(t, "us-west-2", config.AWS.DefaultRegion)
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		k, _ := LookupKey("sso.credential_refresh_minutes")
+		assert.Equal(t, "5", k.Default())
+		k, _ = LookupKey("aws.config_file")
+		assert.Equal(t, DefaultAWS().ConfigFile, k.Default())
+	})
+
+	t.Run("types and environment variables", func(t *testing.T) {
+		k, _ := LookupKey("sso.credential_refresh_minutes")
+		assert.Equal(t, reflect.Int, k.Type)
+		assert.Equal(t, "AWS_SSO_CONFIG_SSO_CREDENTIAL_REFRESH_MINUTES", k.Env)
+		k, _ = LookupKey("aws.default_region")
+		assert.Equal(t, reflect.String, k.Type)
+		assert.Equal(t, "AWS_SSO_CONFIG_AWS_DEFAULT_REGION", k.Env)
+	})
+
+	t.Run("validators reject bad values", func(t *testing.T) {
+		config := &Config{}
+		k, _ := LookupKey("sso.token_store")
+		assert.ErrorContains(t, k.Set(config, "disk"), `invalid value for sso.token_store: "disk" is not a token store`)
+		k, _ = LookupKey("sso.credential_refresh_minutes")
+		assert.ErrorContains(t, k.Set(config, "-1"), "is not a whole number of minutes")
+		assert.Zero(t, config.SSO.CredentialRefreshMinutes)
+	})
+
+	t.Run("reset to default without validating", func(t *testing.T) {
+		config := &Config{}
+		k, _ := LookupKey("aws.config_file")
+		k.Reset(config)
+		assert.Equal(t, DefaultAWS().ConfigFile, config.AWS.ConfigFile)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		_, ok := LookupKey("sso.unknown")
+		assert.False(t, ok)
+	})
+}
+
+func TestBuildSchemaRejectsBadTags(t *testing.T) {
+	type section struct {
+		Value string `toml:"value" validate:"nonsense"`
+	}
+	type config struct {
+		Section section `toml:"section"`
+	}
+	assert.PanicsWithValue(t, `config key section.value names unknown validator "nonsense"`, func() {
+		buildSchema(reflect.TypeOf(config{}), map[string]string{"section.value": "A value"})
+	})
+	assert.PanicsWithValue(t, "config key section.other has a description but no field", func() {
+		buildSchema(reflect.TypeOf(config{}), map[string]string{"section.other": "Another value"})
+	})
+}
+
+func TestSaveKey(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.toml")
+	cm := NewConfigManager(configFile)
+
+	require.NoError(t, cm.SaveKey("sso.credential_refresh_minutes", "12"))
+	require.NoError(t, cm.SaveKey("aws.default_region", "eu-west-1"))
+	assert.ErrorContains(t, cm.SaveKey("sso.token_store", "disk"), "is not a token store")
+	assert.ErrorContains(t, cm.SaveKey("sso.unknown", "x"), "unknown configuration key")
+
+	config, err := cm.Load()
+	require.NoError(t, err)
+	assert.Equal(t, 12, config.SSO.CredentialRefreshMinutes)
+	assert.Equal(t, "eu-west-1", config.AWS.DefaultRegion)
+	assert.Equal(t, "file", config.SSO.TokenStore)
+}
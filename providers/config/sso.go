@@ -2,19 +2,30 @@ package config
 
 import "fmt"
 
-// SSOConfig holds SSO-specific configuration
+// SSOConfig holds SSO-specific configuration. Each field with an entry in descriptions
+// is a configuration key, see Key.
 type SSOConfig struct {
-	StartURL string `mapstructure:"start_url" toml:"start_url"`
-	Region   string `mapstructure:"region" toml:"region"`
-	Role     string `mapstructure:"role" toml:"role"`
+	StartURL string `mapstructure:"start_url" toml:"start_url" validate:"start_url" trusted:"true" deprecated_env:"AWS_CONFIG_SSO_START_URL"`
+	Region   string `mapstructure:"region" toml:"region" validate:"region" deprecated_env:"AWS_CONFIG_SSO_REGION"`
+	Role     string `mapstructure:"role" toml:"role" validate:"role_name" deprecated_env:"AWS_CONFIG_SSO_ROLE"`
+	// TokenStore selects where SSO tokens are cached: file, keyring or encrypted-file
+	TokenStore string `mapstructure:"token_store" toml:"token_store" validate:"token_store" trusted:"true"`
+	// CredentialRefreshMinutes is how long before expiry cached role credentials are replaced
+	CredentialRefreshMinutes int `mapstructure:"credential_refresh_minutes" toml:"credential_refresh_minutes" validate:"minutes"`
 }
 
+// defaultCredentialRefreshMinutes leaves room for a command to finish with the credentials it started with
+const defaultCredentialRefreshMinutes = 5
+
 // DefaultSSO returns the default SSO configuration
 func DefaultSSO() SSOConfig {
 	return SSOConfig{
-		StartURL: "https://your-sso-portal.awsapps.com/start",
-		Region:   "us-east-1",
-		Role:     "AdministratorAccess",
+		StartURL:   "https://your-sso-portal.awsapps.com/start",
+		Region:     "us-east-1",
+		Role:       "AdministratorAccess",
+		TokenStore: "file",
+
+		CredentialRefreshMinutes: defaultCredentialRefreshMinutes,
 	}
 }
 
@@ -26,6 +37,15 @@ func (s *SSOConfig) Validate() error {
 	if s.Region == "" {
 		return fmt.Errorf("SSO region is required")
 	}
+	if s.TokenStore != "" {
+		k, _ := LookupKey("sso.token_store")
+		if err := k.Validate(s.TokenStore); err != nil {
+			return err
+		}
+	}
+	if s.CredentialRefreshMinutes < 0 {
+		return fmt.Errorf("SSO credential refresh minutes cannot be negative, got %d", s.CredentialRefreshMinutes)
+	}
 	return nil
 }
 
@@ -40,6 +60,12 @@ func (s *SSOConfig) SetDefaults() {
 	if s.Role == "" {
 		s.Role = "AdministratorAccess"
 	}
+	if s.TokenStore == "" {
+		s.TokenStore = "file"
+	}
+	if s.CredentialRefreshMinutes == 0 {
+		s.CredentialRefreshMinutes = defaultCredentialRefreshMinutes
+	}
 }
 
 // GetSectionName returns the TOML section name for SSO configuration
@@ -47,12 +73,19 @@ func (s *SSOConfig) GetSectionName() string {
 	return "sso"
 }
 
-// GetDefaultContent returns the default TOML content for SSO section
+// GetDefaultContent returns the TOML content for the SSO section with the values of s,
+// defaults for those it leaves unset
 func (s *SSOConfig) GetDefaultContent() string {
-	return `# AWS SSO Configuration
+	values := *s
+	values.SetDefaults()
+	return fmt.Sprintf(`# AWS SSO Configuration
 [sso]
-start_url = "https://your-sso-portal.awsapps.com/start"
-region = "us-east-1"
-role = "AdministratorAccess"
-`
+start_url = %q
+region = %q
+role = %q
+# Where SSO tokens are cached: file (AWS CLI cache), keyring or encrypted-file
+token_store = %q
+# Minutes before expiry that cached role credentials are refreshed
+credential_refresh_minutes = %d
+`, values.StartURL, values.Region, values.Role, values.TokenStore, values.CredentialRefreshMinutes)
 }
@@ -0,0 +1,176 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/viper"
+)
+
+// ImportMode says how Import combines a configuration with the user file
+type ImportMode int
+
+const (
+	// ImportMerge sets the imported values over the user file, keeping its other settings
+	ImportMerge ImportMode = iota
+	// ImportReplace replaces the user file with the imported configuration
+	ImportReplace
+)
+
+// exportedKeys are the top-level entries Export writes besides version. current_context
+// is left out, as it is each user's own choice.
+var exportedKeys = []string{"sso", "aws", "generate", "repos", contextsKey}
+
+// Export writes the settings of the configuration files as TOML, for config import on
+// another machine. Defaults and environment variables are not included.
+func (cm *ConfigManager) Export(w io.Writer) error {
+	merged, err := cm.mergedFiles()
+	if err != nil {
+		return err
+	}
+
+	settings := merged.AllSettings()
+	exported := map[string]any{versionKey: CurrentVersion}
+	for _, key := range exportedKeys {
+		if value, ok := settings[key]; ok {
+			exported[key] = value
+		}
+	}
+
+	v := viper.New()
+	v.SetConfigType("toml")
+	if err := v.MergeConfigMap(exported); err != nil {
+		return err
+	}
+	return v.WriteConfigTo(w)
+}
+
+// Import reads a TOML configuration, validates every value like config set does,
+// and writes it to the user file. Nothing is written if any value is invalid.
+func (cm *ConfigManager) Import(r io.Reader, mode ImportMode) error {
+	in := viper.New()
+	in.SetConfigType("toml")
+	if err := in.ReadConfig(r); err != nil {
+		return fmt.Errorf("error parsing imported configuration: %w", err)
+	}
+	imported := in.AllSettings()
+	if len(imported) == 0 {
+		return errors.New("the imported configuration has no settings")
+	}
+	if _, _, err := migrate(imported); err != nil {
+		return fmt.Errorf("invalid imported configuration: %w", err)
+	}
+	if err := validateSettings(imported); err != nil {
+		return err
+	}
+
+	settings := imported
+	if mode == ImportMerge {
+		current, err := cm.readFile()
+		if err != nil {
+			return err
+		}
+		if err := current.MergeConfigMap(imported); err != nil {
+			return err
+		}
+		settings = current.AllSettings()
+	}
+
+	// Chains and repository mappings are checked on the result, as a chain's
+	// source role may be the SSO role set in the user file
+	result := viper.New()
+	if err := result.MergeConfigMap(settings); err != nil {
+		return err
+	}
+	config := &Config{}
+	if err := decodeSections(result, config); err != nil {
+		return err
+	}
+	config.SetDefaults()
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("invalid imported configuration: %w", err)
+	}
+
+	return cm.writeSettings(settings)
+}
+
+// validateSettings checks every registered key in settings, at the top level and in
+// contexts, with its validator, and rejects unknown sections and keys
+func validateSettings(settings map[string]any) error {
+	for _, key := range slices.Sorted(maps.Keys(settings)) {
+		switch key {
+		case "sso", "aws":
+			if err := validateSection(key, settings[key]); err != nil {
+				return err
+			}
+		case contextsKey:
+			contexts, ok := settings[key].(map[string]any)
+			if !ok {
+				return fmt.Errorf("%s must be a table of contexts", contextsKey)
+			}
+			for _, name := range slices.Sorted(maps.Keys(contexts)) {
+				if err := validateContext(name, contexts[name]); err != nil {
+					return fmt.Errorf("context %s: %w", name, err)
+				}
+			}
+		case "generate", "repos", currentContextKey, versionKey:
+			// Checked once the result is decoded
+		default:
+			return fmt.Errorf("unknown configuration section: %s", key)
+		}
+	}
+	return nil
+}
+
+// validateContext checks the sso and aws settings of a context
+func validateContext(name string, value any) error {
+	if !contextNamePattern.MatchString(name) {
+		return fmt.Errorf("name must be lower case letters, digits, - and _")
+	}
+	sections, ok := value.(map[string]any)
+	if !ok {
+		return fmt.Errorf("must be a table of sso and aws settings")
+	}
+	for _, section := range slices.Sorted(maps.Keys(sections)) {
+		if section != "sso" && section != "aws" {
+			return fmt.Errorf("unknown configuration section: %s", section)
+		}
+		if err := validateSection(section, sections[section]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateSection checks each value of an sso or aws table with its key's validator
+func validateSection(section string, value any) error {
+	values, ok := value.(map[string]any)
+	if !ok {
+		return fmt.Errorf("%s must be a table", section)
+	}
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		k, ok := LookupKey(section + "." + name)
+		if !ok {
+			return fmt.Errorf("unknown configuration key: %s.%s", section, name)
+		}
+		value := fmt.Sprint(values[name])
+		if isDefault(k, value) {
+			continue
+		}
+		if err := k.Validate(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isDefault reports whether value is k's default, which is accepted without validation
+// like config unset does, e.g. ~/.aws/config on a machine without ~/.aws yet
+func isDefault(k Key, value string) bool {
+	expanded, err := homedir.Expand(value)
+	return value == k.Default() || (err == nil && expanded == k.Default())
+}
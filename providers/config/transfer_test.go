@@ -0,0 +1,110 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const teamConfig = `[sso]
+start_url = "https://team.awsapps.com/start"
+region = "eu-west-1"
+
+[[generate.chains]]
+name = "deploy"
+source_role = "Developer"
+role_arn_template = "arn:aws:iam::{{.AccountId}}:role/Deploy"
+
+[[repos]]
+remote = "github.com/acme/*"
+profile = "acme"
+
+[contexts.client-a.sso]
+start_url = "https://client-a.awsapps.com/start"
+`
+
+func TestExportImport(t *testing.T) {
+	clearEnv(t)
+	dir := t.TempDir()
+	source := NewConfigManager(filepath.Join(dir, "source.toml"))
+	require.NoError(t, os.WriteFile(source.Path(), []byte("[sso]\nrole = \"Developer\"\n"), 0600))
+	require.NoError(t, source.Import(strings.NewReader(teamConfig+"[aws]\ndefault_region = \"eu-west-1\"\n"), ImportMerge),
+		"chains may source from a role set by the user file")
+	require.NoError(t, source.UseContext("client-a"))
+
+	var exported bytes.Buffer
+	require.NoError(t, source.Export(&exported))
+	assert.NotContains(t, exported.String(), "current_context")
+	assert.NotContains(t, exported.String(), "token_store", "defaults are not exported")
+
+	target := NewConfigManager(filepath.Join(dir, "target.toml"))
+	require.NoError(t, os.WriteFile(target.Path(), []byte("[sso]\ntoken_store = \"keyring\"\n"), 0600))
+	require.NoError(t, target.Import(&exported, ImportMerge))
+
+	config, err := target.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "https://team.awsapps.com/start", config.SSO.StartURL)
+	assert.Equal(t, "Developer", config.SSO.Role)
+	assert.Equal(t, "keyring", config.SSO.TokenStore, "merging keeps other settings")
+	assert.Equal(t, "eu-west-1", config.AWS.DefaultRegion)
+	assert.Len(t, config.Generate.Chains, 1)
+	assert.Equal(t, ReposConfig{{Remote: "github.com/acme/*", Profile: "acme"}}, config.Repos)
+	names, err := target.Contexts()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"client-a"}, names)
+
+	require.NoError(t, target.Import(strings.NewReader("[sso]\nrole = \"Ops\"\n[aws]\nconfig_file = \"~/.aws/config\"\n"), ImportReplace),
+		"defaults are accepted without validation")
+	config, err = target.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "Ops", config.SSO.Role)
+	assert.Equal(t, "file", config.SSO.TokenStore, "replacing drops other settings")
+	assert.Empty(t, config.Repos)
+}
+
+func TestImportValidates(t *testing.T) {
+	clearEnv(t)
+	configFile := filepath.Join(t.TempDir(), "config.toml")
+	cm := NewConfigManager(configFile)
+
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{"empty", "", "has no settings"},
+		{"syntax", "[sso\n", "error parsing imported configuration"},
+		{"newer version", "version = 2\n[sso]\nrole = \"Dev\"\n", "version 2 is not supported"},
+		{"unknown section", "[naming]\nstyle = \"x\"\n", "unknown configuration section: naming"},
+		{"unknown key", "[sso]\nstart = \"x\"\n", "unknown configuration key: sso.start"},
+		{"validator", "[sso]\nregion = \"us-esat-1\"\n", "did you mean us-east-1?"},
+		{"context value", "[contexts.work.aws]\ndefault_region = \"narnia\"\n", "context work: invalid value for aws.default_region"},
+		{"context name", "[contexts.\"my work\".sso]\nrole = \"Dev\"\n", "context my work: name must be lower case"},
+		{"chain", teamConfig, `source role "Developer" is neither the SSO role "AdministratorAccess"`},
+		{"repos", "[[repos]]\nprofile = \"acme\"\n", "repos entry 1: acme: set either path or remote"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, cm.Import(strings.NewReader(tt.config), ImportMerge), tt.wantErr)
+			assert.NoFileExists(t, configFile, "nothing is written")
+		})
+	}
+}
+
+func TestExportMissingFiles(t *testing.T) {
+	clearEnv(t)
+	cm := NewConfigManager(filepath.Join(t.TempDir(), "missing.toml"))
+	cm.systemFile = ""
+	cm.workDir = ""
+
+	var exported bytes.Buffer
+	require.NoError(t, cm.Export(&exported))
+	assert.Equal(t, "version = 1", strings.TrimSpace(exported.String()))
+	_, err := os.Stat(cm.Path())
+	assert.True(t, os.IsNotExist(err), "export does not create the user file")
+}
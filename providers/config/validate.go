@@ -0,0 +1,167 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+// validators check a raw value before it is converted and stored. Errors name the
+// problem and, where one can be guessed, end with "did you mean ...?".
+var validators = map[string]func(value string) error{
+	"start_url":   validateStartURL,
+	"region":      validateRegion,
+	"role_name":   validateRoleName,
+	"file_path":   validateFilePath,
+	"minutes":     validateMinutes,
+	"token_store": validateTokenStore,
+}
+
+var (
+	// portalAliasPattern matches the alias part of an awsapps.com start URL
+	portalAliasPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+	// roleNamePattern is the IAM role name character set and length
+	roleNamePattern = regexp.MustCompile(`^[\w+=,.@-]{1,64}$`)
+	// roleNameInvalid matches the characters IAM does not allow in role names
+	roleNameInvalid = regexp.MustCompile(`[^\w+=,.@-]`)
+)
+
+// maxRegionSuggestionDistance is the most edits a mistyped region can be from the suggestion
+const maxRegionSuggestionDistance = 3
+
+// validateStartURL accepts an https URL on a custom domain, and for an awsapps.com
+// portal only the /start path
+func validateStartURL(value string) error {
+	if !strings.Contains(value, "://") {
+		if portalAliasPattern.MatchString(value) {
+			return fmt.Errorf("%q is not a URL, did you mean https://%s.awsapps.com/start?", value, value)
+		}
+		return fmt.Errorf("%q is not a URL, did you mean https://%s?", value, value)
+	}
+
+	u, err := url.Parse(value)
+	if err != nil {
+		return fmt.Errorf("%q is not a URL: %w", value, err)
+	}
+	if !strings.Contains(u.Hostname(), ".") {
+		return fmt.Errorf("%q has no domain name", value)
+	}
+	if u.Scheme != "https" {
+		u.Scheme = "https"
+		return fmt.Errorf("%q must use https, did you mean %s?", value, u)
+	}
+	if strings.HasSuffix(u.Hostname(), ".awsapps.com") && strings.TrimSuffix(u.Path, "/") != "/start" {
+		return fmt.Errorf("%q is not an AWS access portal URL, did you mean https://%s/start?", value, u.Host)
+	}
+	return nil
+}
+
+// validateRegion accepts the regions in the SDK partition metadata
+func validateRegion(value string) error {
+	for _, region := range regions {
+		if value == region {
+			return nil
+		}
+	}
+
+	suggestion, best := "", maxRegionSuggestionDistance+1
+	for _, region := range regions {
+		if d := editDistance(strings.ToLower(value), region); d < best {
+			suggestion, best = region, d
+		}
+	}
+	if suggestion == "" {
+		return fmt.Errorf("%q is not an AWS region", value)
+	}
+	return fmt.Errorf("%q is not an AWS region, did you mean %s?", value, suggestion)
+}
+
+// validateRoleName accepts IAM role names
+func validateRoleName(value string) error {
+	if roleNamePattern.MatchString(value) {
+		return nil
+	}
+	if value == "" {
+		return fmt.Errorf("role name cannot be empty")
+	}
+	if len(value) > 64 {
+		return fmt.Errorf("%q is longer than the 64 characters IAM allows in a role name", value)
+	}
+	return fmt.Errorf("%q is not a valid role name, did you mean %s?", value, roleNameInvalid.ReplaceAllString(value, ""))
+}
+
+// validateFilePath accepts a file, existing or not, in an existing writable directory
+func validateFilePath(value string) error {
+	path, err := homedir.Expand(value)
+	if err != nil {
+		return fmt.Errorf("%q cannot be expanded: %w", value, err)
+	}
+
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return fmt.Errorf("%q is a directory, did you mean %s?", value, filepath.Join(value, "config"))
+	}
+
+	dir := filepath.Dir(path)
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("directory %s does not exist, create it first", dir)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+
+	if !writable(dir) {
+		return fmt.Errorf("directory %s is not writable", dir)
+	}
+	return nil
+}
+
+// validateMinutes accepts a whole, positive number of minutes
+func validateMinutes(value string) error {
+	minutes, err := strconv.Atoi(value)
+	if err != nil || minutes < 0 {
+		return fmt.Errorf("%q is not a whole number of minutes", value)
+	}
+	// 0 reads as unset and would be replaced by the default
+	if minutes == 0 {
+		return fmt.Errorf("%q is too small, use at least 1 minute", value)
+	}
+	return nil
+}
+
+// validateTokenStore accepts the token stores the SSO provider knows about
+func validateTokenStore(value string) error {
+	switch value {
+	case "file", "keyring", "encrypted-file":
+		return nil
+	default:
+		return fmt.Errorf("%q is not a token store, use file, keyring or encrypted-file", value)
+	}
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
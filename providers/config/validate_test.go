@@ -0,0 +1,75 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidators(t *testing.T) {
+	dir := t.TempDir()
+	readOnly := filepath.Join(dir, "read-only")
+	assert.NoError(t, os.Mkdir(readOnly, 0500))
+
+	tests := []struct {
+		validator string
+		value     string
+		wantErr   string
+	}{
+		{"start_url", "https://mycompany.awsapps.com/start", ""},
+		{"start_url", "https://mycompany.awsapps.com/start/", ""},
+		{"start_url", "https://sso.example.com/portal", ""},
+		{"start_url", "foo", `"foo" is not a URL, did you mean https://foo.awsapps.com/start?`},
+		{"start_url", "foo.awsapps.com/start", "did you mean https://foo.awsapps.com/start?"},
+		{"start_url", "http://foo.awsapps.com/start", "must use https, did you mean https://foo.awsapps.com/start?"},
+		{"start_url", "https://foo.awsapps.com", "not an AWS access portal URL, did you mean https://foo.awsapps.com/start?"},
+		{"start_url", "https://localhost/start", "has no domain name"},
+
+		{"region", "us-east-1", ""},
+		{"region", "us-gov-west-1", ""},
+		{"region", "cn-north-1", ""},
+		{"region", "us-esat-1", `"us-esat-1" is not an AWS region, did you mean us-east-1?`},
+		{"region", "EU-WEST-1", "did you mean eu-west-1?"},
+		{"region", "narnia", `"narnia" is not an AWS region`},
+		{"region", "aws-global", "is not an AWS region"},
+
+		{"role_name", "AdministratorAccess", ""},
+		{"role_name", "team+ops=a,b.c@d-e_f", ""},
+		{"role_name", "Administrator Access", "did you mean AdministratorAccess?"},
+		{"role_name", "", "cannot be empty"},
+		{"role_name", string(make([]byte, 65)), "longer than the 64 characters"},
+
+		{"file_path", filepath.Join(dir, "config"), ""},
+		{"file_path", dir, "is a directory, did you mean " + filepath.Join(dir, "config") + "?"},
+		{"file_path", filepath.Join(dir, "missing", "config"), "does not exist"},
+
+		{"minutes", "1", ""},
+		{"minutes", "0", "is too small, use at least 1 minute"},
+		{"minutes", "1.5", "is not a whole number of minutes"},
+		{"token_store", "keyring", ""},
+		{"token_store", "disk", "is not a token store"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.validator+"/"+tt.value, func(t *testing.T) {
+			err := validators[tt.validator](tt.value)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+
+	if os.Getuid() != 0 {
+		assert.ErrorContains(t, validateFilePath(filepath.Join(readOnly, "config")), "is not writable")
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("us-east-1", "us-east-1"))
+	assert.Equal(t, 2, editDistance("us-esat-1", "us-east-1"))
+	assert.Equal(t, 3, editDistance("", "abc"))
+}